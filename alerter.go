@@ -8,24 +8,61 @@ import (
 	"net/http"
 	"net/smtp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Alerter handles sending alerts through various channels
 type Alerter struct {
-	config *Alerting
+	config   atomic.Pointer[Alerting]
+	lastSent map[string]time.Time   // last failure alert per endpoint ID
+	held     map[string]string      // endpoint ID -> unhealthy parent its failure alert was held for
+	firing   map[string]firingAlert // endpoint ID -> alert pushed to Alertmanager for its outage
+	mu       sync.Mutex
+
+	// Endpoint ID -> ts of the Slack message that opened its incident's thread
+	slackThreads map[string]string
+	// Endpoint ID -> closed when its latest queued Slack bot post is done
+	slackLast map[string]chan struct{}
+
+	// Alerts collected during quiet hours, sent as a digest when they end
+	digest      []heldAlert
+	digestTimer *time.Timer
+
+	// dependencyDown returns the name of an unhealthy endpoint that endpoint depends on, if any
+	dependencyDown func(endpoint Endpoint) string
 }
 
 // NewAlerter creates a new alerter
 func NewAlerter(config *Alerting) *Alerter {
-	return &Alerter{
-		config: config,
+	a := &Alerter{
+		lastSent: make(map[string]time.Time),
+		held:     make(map[string]string),
+		firing:   make(map[string]firingAlert),
+
+		slackThreads: make(map[string]string),
+		slackLast:    make(map[string]chan struct{}),
 	}
+	a.config.Store(config)
+	return a
+}
+
+// UpdateConfig swaps in a new alerting configuration; alerts already in flight
+// finish with the configuration they started with
+func (a *Alerter) UpdateConfig(config *Alerting) {
+	a.config.Store(config)
+	log.Printf("Alerting configuration updated")
+}
+
+// cfg returns the current alerting configuration
+func (a *Alerter) cfg() *Alerting {
+	return a.config.Load()
 }
 
 // SendFailureAlert sends an alert when an endpoint becomes unhealthy
 func (a *Alerter) SendFailureAlert(endpoint Endpoint, state *EndpointState) {
-	if !a.config.Enabled {
+	if !a.cfg().Enabled || a.holdForDependency(endpoint, state) {
 		return
 	}
 
@@ -48,16 +85,410 @@ func (a *Alerter) SendFailureAlert(endpoint Endpoint, state *EndpointState) {
 
 	subject := fmt.Sprintf("[CRONZEE] Alert: %s is DOWN", endpoint.Name)
 
+	a.markSent(state.ID)
 	a.sendAlert(subject, message, "failure", endpoint, state)
 	// 🔔 NEW: Teams alert
-	if a.config.TeamsEnabled && a.config.TeamsWebhook != "" {
-		a.sendTeamsAlert(endpoint,state)
+	if a.cfg().TeamsEnabled && a.cfg().TeamsWebhook != "" {
+		a.sendTeamsAlert("failure", endpoint, state)
+	}
+}
+
+// SendFirstFailureAlert sends an early warning on the first failed check of a
+// healthy endpoint, before the failure threshold marks it unhealthy
+func (a *Alerter) SendFirstFailureAlert(endpoint Endpoint, state *EndpointState) {
+	if !a.cfg().Enabled || (a.dependencyDown != nil && a.dependencyDown(endpoint) != "") {
+		return
+	}
+
+	unhealthyAfter := fmt.Sprintf("%d consecutive failures", endpoint.FailureThreshold)
+	if endpoint.FailureDuration > 0 {
+		unhealthyAfter = fmt.Sprintf("failing for %v", endpoint.FailureDuration)
+	}
+
+	message := fmt.Sprintf(
+		"🔴 WARNING: Endpoint '%s' failed a health check\n\n"+
+			"URL: %s\n"+
+			"Status: %s (unhealthy after %s)\n"+
+			"Error: %s\n"+
+			"Last Check: %s\n"+
+			"Response Time: %v",
+		endpoint.Name,
+		endpoint.URL,
+		state.Status,
+		unhealthyAfter,
+		state.LastError,
+		state.LastCheck.Format(time.RFC3339),
+		state.ResponseTime,
+	)
+
+	subject := fmt.Sprintf("[CRONZEE] Warning: %s check failed", endpoint.Name)
+
+	a.sendAlert(subject, message, "first_failure", endpoint, state)
+	if a.cfg().TeamsEnabled && a.cfg().TeamsWebhook != "" {
+		a.sendTeamsAlert("first_failure", endpoint, state)
+	}
+}
+
+// SendRepeatAlert re-sends a failure alert if the endpoint has stayed unhealthy
+// for longer than the configured repeat interval since the last alert
+func (a *Alerter) SendRepeatAlert(endpoint Endpoint, state *EndpointState) {
+	if !a.cfg().Enabled {
+		return
+	}
+
+	// A failure alert held back for a dependency goes out once the parent is
+	// healthy again but this endpoint is still down
+	a.mu.Lock()
+	_, held := a.held[state.ID]
+	a.mu.Unlock()
+	if held {
+		if a.dependencyDown == nil || a.dependencyDown(endpoint) == "" {
+			a.releaseHeld(state.ID)
+			a.SendFailureAlert(endpoint, state)
+		}
+		return
+	}
+
+	if a.cfg().RepeatInterval <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	last, ok := a.lastSent[state.ID]
+	if ok && time.Since(last) < a.cfg().RepeatInterval {
+		a.mu.Unlock()
+		return
+	}
+	a.lastSent[state.ID] = time.Now()
+	a.mu.Unlock()
+
+	message := fmt.Sprintf(
+		"🔴 REMINDER: Endpoint '%s' is still UNHEALTHY\n\n"+
+			"URL: %s\n"+
+			"Status: %s\n"+
+			"Down For: %v\n"+
+			"Consecutive Failures: %d\n"+
+			"Last Error: %s\n"+
+			"Last Check: %s",
+		endpoint.Name,
+		endpoint.URL,
+		state.Status,
+		time.Since(state.LastStatusChange).Round(time.Second),
+		state.ConsecutiveFailures,
+		state.LastError,
+		state.LastCheck.Format(time.RFC3339),
+	)
+
+	subject := fmt.Sprintf("[CRONZEE] Reminder: %s is still DOWN", endpoint.Name)
+
+	a.sendAlert(subject, message, "repeat", endpoint, state)
+	if a.cfg().TeamsEnabled && a.cfg().TeamsWebhook != "" {
+		a.sendTeamsAlert("repeat", endpoint, state)
 	}
 }
 
+// SendFlappingAlert sends a single notice when an endpoint starts flapping;
+// further failure and recovery alerts are held back until it stabilizes
+func (a *Alerter) SendFlappingAlert(endpoint Endpoint, state *EndpointState, changes int) {
+	if !a.cfg().Enabled {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"🟠 FLAPPING: Endpoint '%s' is changing status repeatedly\n\n"+
+			"URL: %s\n"+
+			"Status: %s\n"+
+			"Status Changes: %d in %v\n"+
+			"Last Error: %s\n"+
+			"Last Check: %s\n\n"+
+			"Alerts for this endpoint are paused until it stabilizes.",
+		endpoint.Name,
+		endpoint.URL,
+		state.Status,
+		changes,
+		a.cfg().FlapWindow,
+		state.LastError,
+		state.LastCheck.Format(time.RFC3339),
+	)
+
+	subject := fmt.Sprintf("[CRONZEE] Flapping: %s", endpoint.Name)
+
+	a.sendAlert(subject, message, "flapping", endpoint, state)
+}
+
+// SendDegradedAlert sends a notice when an endpoint's response time regresses
+// past the configured multiple of its baseline, or when it returns to normal
+func (a *Alerter) SendDegradedAlert(endpoint Endpoint, state *EndpointState, degraded bool, baseline, recent time.Duration) {
+	if !a.cfg().Enabled {
+		return
+	}
+
+	headline := fmt.Sprintf("🟡 DEGRADED: Endpoint '%s' is responding slowly", endpoint.Name)
+	subject := fmt.Sprintf("[CRONZEE] Degraded: %s is slow", endpoint.Name)
+	alertType := "degraded"
+	if !degraded {
+		headline = fmt.Sprintf("✅ Endpoint '%s' response time is back to normal", endpoint.Name)
+		subject = fmt.Sprintf("[CRONZEE] Recovered: %s response time", endpoint.Name)
+		alertType = "degraded_recovery"
+	}
+
+	message := fmt.Sprintf(
+		"%s\n\n"+
+			"URL: %s\n"+
+			"Recent Median Response Time: %v\n"+
+			"Baseline Response Time: %v\n"+
+			"Last Check: %s",
+		headline,
+		endpoint.URL,
+		recent,
+		baseline,
+		state.LastCheck.Format(time.RFC3339),
+	)
+
+	a.sendAlert(subject, message, alertType, endpoint, state)
+}
+
+// snoozed reports whether alerts for the endpoint are snoozed right now. The
+// snooze lapses by itself once SnoozeUntil has passed.
+func snoozed(state *EndpointState) bool {
+	return time.Now().Before(state.SnoozeUntil)
+}
+
+// quiet reports whether alerts for endpoint are currently held for quiet hours
+func (a *Alerter) quiet(endpoint Endpoint) bool {
+	return !endpoint.AlwaysAlert && a.cfg().QuietHours.Active(time.Now())
+}
+
+// holdForQuietHours drops an alert during quiet hours or, in digest mode, keeps
+// it for the digest sent when quiet hours end
+func (a *Alerter) holdForQuietHours(subject string, emails []string) {
+	q := a.cfg().QuietHours
+	if !q.Digest {
+		log.Printf("Quiet hours: dropped alert %q", subject)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.digest = append(a.digest, heldAlert{line: time.Now().Format("15:04") + " " + subject, emails: emails})
+	if a.digestTimer == nil {
+		a.digestTimer = time.AfterFunc(time.Until(q.NextEnd(time.Now())), a.sendDigest)
+	}
+	log.Printf("Quiet hours: queued alert %q for the digest", subject)
+}
+
+// heldAlert is an alert queued for the quiet hours digest, with the email
+// recipients of its endpoint (nil for email_config.to)
+type heldAlert struct {
+	line   string
+	emails []string
+}
+
+// sendDigest sends the alerts collected during quiet hours. The webhook and
+// chat channels get them all in one message; email sends one digest per set
+// of recipients, holding only the alerts that would have gone to them.
+func (a *Alerter) sendDigest() {
+	a.mu.Lock()
+	held := a.digest
+	a.digest = nil
+	a.digestTimer = nil
+	a.mu.Unlock()
+
+	if len(held) == 0 || !a.cfg().Enabled {
+		return
+	}
+
+	alerts := make([]string, len(held))
+	var recipients []string // group keys, in order of their first alert
+	groups := make(map[string][]heldAlert)
+	for i, h := range held {
+		alerts[i] = h.line
+		key := strings.Join(h.emails, ",")
+		if _, ok := groups[key]; !ok {
+			recipients = append(recipients, key)
+		}
+		groups[key] = append(groups[key], h)
+	}
+
+	a.postNotice(digestSubject(len(alerts)), digestMessage(alerts), "digest", map[string]interface{}{"alerts": alerts})
+	if a.cfg().EmailEnabled {
+		for _, key := range recipients {
+			group := groups[key]
+			lines := make([]string, len(group))
+			for i, h := range group {
+				lines[i] = h.line
+			}
+			go a.sendEmailAlert(digestSubject(len(lines)), digestMessage(lines), group[0].emails)
+		}
+	}
+}
+
+// digestSubject returns the subject of a quiet hours digest of n alerts
+func digestSubject(n int) string {
+	return fmt.Sprintf("[CRONZEE] %d alert(s) during quiet hours", n)
+}
+
+// digestMessage returns the text of a quiet hours digest
+func digestMessage(alerts []string) string {
+	return "🌙 Alerts held during quiet hours:\n\n" + strings.Join(alerts, "\n")
+}
+
+// sendNotice sends a message that is not about a single endpoint, such as a
+// report, through the webhook, Slack, Teams and email channels. extra is
+// merged into the webhook payload.
+func (a *Alerter) sendNotice(subject, message, alertType string, extra map[string]interface{}) {
+	a.postNotice(subject, message, alertType, extra)
+	if a.cfg().EmailEnabled {
+		go a.sendEmailAlert(subject, message, nil)
+	}
+}
+
+// postNotice sends a notice through the webhook, Slack and Teams channels
+func (a *Alerter) postNotice(subject, message, alertType string, extra map[string]interface{}) {
+	if urls := a.cfg().WebhookTargets(); len(urls) > 0 {
+		payload := map[string]interface{}{
+			"subject":    subject,
+			"message":    message,
+			"alert_type": alertType,
+			"timestamp":  time.Now().Format(time.RFC3339),
+		}
+		for key, value := range extra {
+			payload[key] = value
+		}
+		for key, value := range a.cfg().CustomFields {
+			payload[key] = value
+		}
+		for _, url := range urls {
+			go a.postJSON("Webhook", url, payload)
+		}
+	}
+
+	if a.cfg().SlackEnabled {
+		payload := map[string]interface{}{
+			"text": subject,
+			"attachments": []map[string]interface{}{
+				{
+					"color":  "#6366f1",
+					"text":   message,
+					"footer": "Cronzee Health Monitor",
+					"ts":     time.Now().Unix(),
+				},
+			},
+		}
+		for _, url := range a.cfg().SlackTargets() {
+			go a.postJSON("Slack", url, payload)
+		}
+		if a.cfg().slackBotEnabled() {
+			go func() {
+				if _, err := a.postSlackMessage(payload); err != nil {
+					log.Printf("Failed to send Slack message: %v", err)
+				}
+			}()
+		}
+	}
+
+	if a.cfg().TeamsEnabled && a.cfg().TeamsWebhook != "" {
+		payload := map[string]interface{}{
+			"title":       subject,
+			"theme_color": "6366F1",
+			"alert_type":  alertType,
+			"message":     message,
+			"timestamp":   teamsTime(time.Now()),
+		}
+		go a.postJSON("Teams", a.cfg().TeamsWebhook, payload)
+	}
+}
+
+// SendSummaryReport sends a scheduled uptime report through the configured
+// webhook, Slack, Teams and email channels
+func (a *Alerter) SendSummaryReport(schedule string, from, to time.Time, summaries []EndpointSummary) {
+	if !a.cfg().Enabled {
+		return
+	}
+
+	subject := fmt.Sprintf("[CRONZEE] %s uptime report", strings.ToUpper(schedule[:1])+schedule[1:])
+	message := formatSummary(schedule, from, to, summaries)
+
+	a.sendNotice(subject, message, "summary", map[string]interface{}{
+		"period": map[string]interface{}{
+			"schedule": schedule,
+			"from":     from.Format(time.RFC3339),
+			"to":       to.Format(time.RFC3339),
+		},
+		"endpoints": summaries,
+	})
+	log.Printf("Sent %s summary report for %d endpoints", schedule, len(summaries))
+}
+
+// postJSON posts a JSON payload to a notification channel, logging the outcome
+func (a *Alerter) postJSON(channel, url string, payload interface{}) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s payload: %v", channel, err)
+		return
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		log.Printf("Failed to send %s message: %v", channel, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("%s message failed with status code: %d", channel, resp.StatusCode)
+	}
+}
+
+// holdForDependency reports whether the failure alert for an endpoint should be
+// held back because one of the endpoints it depends on is already unhealthy
+func (a *Alerter) holdForDependency(endpoint Endpoint, state *EndpointState) bool {
+	if a.dependencyDown == nil || len(endpoint.DependsOn) == 0 {
+		return false
+	}
+	parent := a.dependencyDown(endpoint)
+	if parent == "" {
+		return false
+	}
+
+	a.mu.Lock()
+	_, already := a.held[state.ID]
+	a.held[state.ID] = parent
+	a.mu.Unlock()
+	if !already {
+		log.Printf("[%s] Alert suppressed: depends on '%s', which is unhealthy (likely root cause)", endpoint.Name, parent)
+	}
+	return true
+}
+
+// releaseHeld forgets a held failure alert and reports whether there was one
+func (a *Alerter) releaseHeld(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.held[id]
+	delete(a.held, id)
+	return ok
+}
+
+// markSent records that a failure alert was just sent for an endpoint
+func (a *Alerter) markSent(id string) {
+	a.mu.Lock()
+	a.lastSent[id] = time.Now()
+	a.mu.Unlock()
+}
+
+// clearSent forgets the last failure alert for an endpoint once it recovers
+func (a *Alerter) clearSent(id string) {
+	a.mu.Lock()
+	delete(a.lastSent, id)
+	a.mu.Unlock()
+}
+
 // SendRecoveryAlert sends an alert when an endpoint recovers
 func (a *Alerter) SendRecoveryAlert(endpoint Endpoint, state *EndpointState) {
-	if !a.config.Enabled {
+	a.clearSent(state.ID)
+	// No failure alert went out for this outage, so there is nothing to resolve
+	if a.releaseHeld(state.ID) || !a.cfg().Enabled {
 		return
 	}
 
@@ -84,24 +515,49 @@ func (a *Alerter) SendRecoveryAlert(endpoint Endpoint, state *EndpointState) {
 
 // sendAlert sends alerts through configured channels
 func (a *Alerter) sendAlert(subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
-	// Send webhook alert
-	if a.config.WebhookURL != "" {
-		go a.sendWebhookAlert(subject, message, alertType, endpoint, state)
+	if snoozed(state) {
+		log.Printf("Snoozed until %s: dropped alert %q", state.SnoozeUntil.Format(time.RFC3339), subject)
+		return
+	}
+	if a.quiet(endpoint) {
+		a.holdForQuietHours(subject, endpoint.AlertEmails)
+		return
 	}
 
-	// Send Slack alert
-	if a.config.SlackEnabled && a.config.SlackWebhook != "" {
-		go a.sendSlackAlert(subject, message, alertType, endpoint, state)
+	// Send webhook alerts
+	for _, url := range a.cfg().WebhookTargets() {
+		go a.sendWebhookAlert(url, subject, message, alertType, endpoint, state)
 	}
 
-	// Send email alert
-	if a.config.EmailEnabled {
-		go a.sendEmailAlert(subject, message)
+	// Send Slack alerts
+	if a.cfg().SlackEnabled {
+		for _, url := range a.cfg().SlackTargets() {
+			go a.sendSlackAlert(url, subject, message, alertType, endpoint, state)
+		}
+		if a.cfg().slackBotEnabled() {
+			a.queueSlackBotAlert(subject, message, alertType, endpoint, state)
+		}
+	}
+
+	// Send email alert, to the endpoint's own recipients if it has any
+	if a.cfg().EmailEnabled {
+		go a.sendEmailAlert(subject, message, endpoint.AlertEmails)
 	}
 }
 
-// sendWebhookAlert sends a generic webhook alert
-func (a *Alerter) sendWebhookAlert(subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
+// sendWebhookAlert sends a generic webhook alert to url
+func (a *Alerter) sendWebhookAlert(url, subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
+	stateFields := map[string]interface{}{
+		"status":               string(state.Status),
+		"consecutive_failures": state.ConsecutiveFailures,
+		"last_error":           state.LastError,
+		"response_time_ms":     state.ResponseTime.Milliseconds(),
+		"last_check":           state.LastCheck.Format(time.RFC3339),
+	}
+	// Lets incident tools acknowledge or resolve it via /api/incidents
+	if state.Status == StatusUnhealthy {
+		stateFields["incident_id"] = incidentID(state)
+	}
 	payload := map[string]interface{}{
 		"subject":    subject,
 		"message":    message,
@@ -111,18 +567,12 @@ func (a *Alerter) sendWebhookAlert(subject, message, alertType string, endpoint
 			"url":    endpoint.URL,
 			"method": endpoint.Method,
 		},
-		"state": map[string]interface{}{
-			"status":               string(state.Status),
-			"consecutive_failures": state.ConsecutiveFailures,
-			"last_error":           state.LastError,
-			"response_time_ms":     state.ResponseTime.Milliseconds(),
-			"last_check":           state.LastCheck.Format(time.RFC3339),
-		},
+		"state":     stateFields,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 
 	// Add custom fields
-	for key, value := range a.config.CustomFields {
+	for key, value := range a.cfg().CustomFields {
 		payload[key] = value
 	}
 
@@ -132,7 +582,7 @@ func (a *Alerter) sendWebhookAlert(subject, message, alertType string, endpoint
 		return
 	}
 
-	resp, err := http.Post(a.config.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		log.Printf("Failed to send webhook alert: %v", err)
 		return
@@ -146,17 +596,75 @@ func (a *Alerter) sendWebhookAlert(subject, message, alertType string, endpoint
 	}
 }
 
-// sendSlackAlert sends an alert to Slack
-func (a *Alerter) sendSlackAlert(subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
+// sendSlackAlert sends an alert to the Slack webhook at url
+func (a *Alerter) sendSlackAlert(url, subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
+	payload := a.slackPayload(subject, message, alertType, endpoint, state)
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal Slack payload: %v", err)
+		return
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		log.Printf("Failed to send Slack alert: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		log.Printf("Slack alert sent successfully for endpoint: %s", endpoint.Name)
+	} else {
+		log.Printf("Slack alert failed with status code: %d", resp.StatusCode)
+	}
+}
+
+// slackPayload builds the Slack message for an alert: the attachment layout
+// below, or alerting.slack_template if one is set
+func (a *Alerter) slackPayload(subject, message, alertType string, endpoint Endpoint, state *EndpointState) map[string]interface{} {
 	color := "danger"
 	emoji := "🔴"
-	if alertType == "recovery" {
+	switch alertType {
+	case "recovery", "degraded_recovery":
 		color = "good"
 		emoji = "✅"
+	case "flapping":
+		color = "warning"
+		emoji = "🟠"
+	case "degraded":
+		color = "warning"
+		emoji = "🟡"
+	}
+
+	// Page people for failures only; recoveries and notices stay quiet
+	var mention string
+	switch alertType {
+	case "failure", "repeat", "first_failure":
+		mention = endpoint.SlackMention
+		if mention == "" {
+			mention = a.cfg().SlackMention
+		}
+	}
+	withMention := func(text string) string {
+		if mention == "" {
+			return text
+		}
+		return mention + " " + text
 	}
 
+	if tmpl := a.cfg().SlackTemplate; tmpl != "" {
+		rendered, err := renderSlackTemplate(tmpl, newSlackTemplateData(subject, message, alertType, endpoint, state))
+		if err == nil {
+			return map[string]interface{}{"text": withMention(rendered)}
+		}
+		log.Printf("Error rendering slack_template, using the default message: %v", err)
+	}
+
+	text := withMention(fmt.Sprintf("%s %s", emoji, subject))
+
 	payload := map[string]interface{}{
-		"text": fmt.Sprintf("%s %s", emoji, subject),
+		"text": text,
 		"attachments": []map[string]interface{}{
 			{
 				"color": color,
@@ -196,42 +704,29 @@ func (a *Alerter) sendSlackAlert(subject, message, alertType string, endpoint En
 			"short": false,
 		})
 	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("Failed to marshal Slack payload: %v", err)
-		return
-	}
-
-	resp, err := http.Post(a.config.SlackWebhook, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Printf("Failed to send Slack alert: %v", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		log.Printf("Slack alert sent successfully for endpoint: %s", endpoint.Name)
-	} else {
-		log.Printf("Slack alert failed with status code: %d", resp.StatusCode)
-	}
+	return payload
 }
 
-// sendEmailAlert sends an email alert
-func (a *Alerter) sendEmailAlert(subject, message string) {
-	if a.config.EmailConfig.SMTPHost == "" {
+// sendEmailAlert sends an email alert to recipients, or to email_config.to
+// if recipients is empty
+func (a *Alerter) sendEmailAlert(subject, message string, recipients []string) {
+	email := a.cfg().EmailConfig
+	if email.SMTPHost == "" {
 		log.Println("Email SMTP host not configured")
 		return
 	}
+	if len(recipients) == 0 {
+		recipients = email.To
+	}
 
 	auth := smtp.PlainAuth(
 		"",
-		a.config.EmailConfig.Username,
-		a.config.EmailConfig.Password,
-		a.config.EmailConfig.SMTPHost,
+		email.Username,
+		email.Password,
+		email.SMTPHost,
 	)
 
-	to := strings.Join(a.config.EmailConfig.To, ",")
+	to := strings.Join(recipients, ",")
 	
 	emailBody := fmt.Sprintf(
 		"From: %s\r\n"+
@@ -239,19 +734,19 @@ func (a *Alerter) sendEmailAlert(subject, message string) {
 			"Subject: %s\r\n"+
 			"\r\n"+
 			"%s\r\n",
-		a.config.EmailConfig.From,
+		email.From,
 		to,
 		subject,
 		message,
 	)
 
-	addr := fmt.Sprintf("%s:%d", a.config.EmailConfig.SMTPHost, a.config.EmailConfig.SMTPPort)
+	addr := fmt.Sprintf("%s:%d", email.SMTPHost, email.SMTPPort)
 	
 	err := smtp.SendMail(
 		addr,
 		auth,
-		a.config.EmailConfig.From,
-		a.config.EmailConfig.To,
+		email.From,
+		recipients,
 		[]byte(emailBody),
 	)
 
@@ -265,25 +760,22 @@ func (a *Alerter) sendEmailAlert(subject, message string) {
 
 //send alerts to teams 
 
-func (a *Alerter) sendTeamsAlert(endpoint Endpoint, state *EndpointState) {
+func (a *Alerter) sendTeamsAlert(alertType string, endpoint Endpoint, state *EndpointState) {
 
-	if !a.config.TeamsEnabled || a.config.TeamsWebhook == "" {
+	if !a.cfg().TeamsEnabled || a.cfg().TeamsWebhook == "" || a.quiet(endpoint) || snoozed(state) {
 		return
 	}
-	loc, err := time.LoadLocation("Asia/Kolkata")
-	if err != nil {
-		loc = time.FixedZone("IST", 5*60*60+30*60)
-	}
-
-	istTime := state.LastCheck.In(loc)
-
+	title, color := teamsCardStyle(alertType, endpoint.Name)
 	payload := map[string]interface{}{
+		"title":          title,
+		"theme_color":    color,
+		"alert_type":     alertType,
 		"service":        endpoint.Name,
 		"url":            endpoint.URL,
 		"status":         string(state.Status),
 		"failures":       state.ConsecutiveFailures,
 		"response_time":  state.ResponseTime.String(),
-		"timestamp":      istTime.Format("02 Jan 2006, 03:04:05 PM"),
+		"timestamp":      teamsTime(state.LastCheck),
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -293,7 +785,7 @@ func (a *Alerter) sendTeamsAlert(endpoint Endpoint, state *EndpointState) {
 	}
 
 	resp, err := http.Post(
-		a.config.TeamsWebhook,
+		a.cfg().TeamsWebhook,
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
@@ -310,3 +802,25 @@ func (a *Alerter) sendTeamsAlert(endpoint Endpoint, state *EndpointState) {
 	}
 }
 
+
+// teamsTime formats a time for a Teams card, in India Standard Time
+func teamsTime(t time.Time) string {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		loc = time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return t.In(loc).Format("02 Jan 2006, 03:04:05 PM")
+}
+
+// teamsCardStyle returns the title and theme colour of the Teams card for an
+// alert, so an early warning doesn't look like an outage
+func teamsCardStyle(alertType, name string) (title, color string) {
+	switch alertType {
+	case "first_failure":
+		return name + " failed a check", "FFA500"
+	case "repeat":
+		return name + " is still DOWN", "D13438"
+	default:
+		return name + " is DOWN", "D13438"
+	}
+}
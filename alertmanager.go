@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// alertmanagerResendInterval is how often a firing alert is pushed again while
+// its endpoint stays down. Alertmanager resolves an alert on its own once its
+// endsAt passes, so each push extends endsAt to a few intervals ahead.
+const alertmanagerResendInterval = time.Minute
+
+// alertmanagerAlert is an alert in the body of Alertmanager's POST /api/v2/alerts
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    string            `json:"startsAt"`
+	EndsAt      string            `json:"endsAt"`
+}
+
+// firingAlert is the last push of an endpoint's firing alert
+type firingAlert struct {
+	alert  alertmanagerAlert
+	pushed time.Time
+}
+
+// alertmanagerURL returns the alerts API of the configured Alertmanager, or ""
+// if none is configured
+func (a *Alerter) alertmanagerURL() string {
+	base := a.cfg().AlertmanagerURL
+	if base == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(base, "/"), "/api/v2/alerts") + "/api/v2/alerts"
+}
+
+// newAlertmanagerAlert builds the alert for an endpoint's outage, ending at
+// endsAt. Caller must hold state.mu.
+func (a *Alerter) newAlertmanagerAlert(endpoint Endpoint, state *EndpointState, endsAt time.Time) alertmanagerAlert {
+	return alertmanagerAlert{
+		Labels: map[string]string{
+			"alertname": "CronzeeEndpointDown",
+			"endpoint":  endpoint.Name,
+			"severity":  a.cfg().AlertmanagerSeverity,
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("%s is DOWN", endpoint.Name),
+			"description": fmt.Sprintf("%s failed %d consecutive checks: %s", endpoint.URL, state.ConsecutiveFailures, state.LastError),
+		},
+		StartsAt: state.LastStatusChange.Format(time.RFC3339),
+		EndsAt:   endsAt.Format(time.RFC3339),
+	}
+}
+
+// fireAlertmanagerAlert pushes a firing alert for an endpoint that went down
+// and remembers it, so it is kept fresh and later resolved. Caller must hold
+// state.mu.
+func (a *Alerter) fireAlertmanagerAlert(endpoint Endpoint, state *EndpointState) {
+	url := a.alertmanagerURL()
+	if url == "" {
+		return
+	}
+
+	// Leave room for a backed-off or long check interval before the next push
+	ttl := 4 * max(alertmanagerResendInterval, time.Until(state.NextCheck))
+	alert := a.newAlertmanagerAlert(endpoint, state, time.Now().Add(ttl))
+
+	a.mu.Lock()
+	a.firing[state.ID] = firingAlert{alert: alert, pushed: time.Now()}
+	a.mu.Unlock()
+	go a.postJSON("Alertmanager", url, []alertmanagerAlert{alert})
+}
+
+// SyncAlertmanagerAlert keeps Alertmanager in step with an endpoint's status
+// after each check. An unhealthy endpoint's alert is pushed when it goes down
+// and again at most every alertmanagerResendInterval while the outage lasts;
+// any other status resolves it. It follows the status rather than the
+// notifications, so quiet hours and held dependency alerts don't delay it. A
+// snoozed, suppressed or flapping endpoint's alert is left to expire, and is
+// pushed afresh if the endpoint is still down afterwards. Caller must hold
+// state.mu.
+func (a *Alerter) SyncAlertmanagerAlert(endpoint Endpoint, state *EndpointState) {
+	if state.Status != StatusUnhealthy {
+		a.resolveAlertmanagerAlert(state.ID)
+		return
+	}
+
+	a.mu.Lock()
+	last, ok := a.firing[state.ID]
+	a.mu.Unlock()
+	if ok && time.Since(last.pushed) < alertmanagerResendInterval {
+		return
+	}
+	if !a.cfg().Enabled || state.AlertsSuppressed || state.Flapping || snoozed(state) {
+		return
+	}
+	a.fireAlertmanagerAlert(endpoint, state)
+}
+
+// resolveAlertmanagerAlert resolves an endpoint's firing alert, if it has one,
+// by pushing its last version again with endsAt set to now
+func (a *Alerter) resolveAlertmanagerAlert(id string) {
+	a.mu.Lock()
+	last, ok := a.firing[id]
+	delete(a.firing, id)
+	a.mu.Unlock()
+
+	url := a.alertmanagerURL()
+	if !ok || url == "" {
+		return
+	}
+	alert := last.alert
+	alert.EndsAt = time.Now().Format(time.RFC3339)
+	go a.postJSON("Alertmanager", url, []alertmanagerAlert{alert})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// archiveRecords appends records to JSONL files in dir, one file per UTC day
+// of the records' timestamps named history-YYYY-MM-DD.jsonl. Each line is a
+// HealthCheckRecord in the same JSON form the history API returns, so the
+// files can be read back line by line. Records already in a day's file are
+// skipped, so records archived by a cleanup whose delete then failed aren't
+// written again when the next cleanup retries.
+func archiveRecords(dir string, records []*HealthCheckRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	byDay := make(map[string][]*HealthCheckRecord)
+	for _, rec := range records {
+		day := rec.Timestamp.UTC().Format("2006-01-02")
+		byDay[day] = append(byDay[day], rec)
+	}
+
+	days := make([]string, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	for _, day := range days {
+		path := filepath.Join(dir, "history-"+day+".jsonl")
+		archived, err := archivedRecords(path)
+		if err != nil {
+			return fmt.Errorf("failed to read history archive: %w", err)
+		}
+		var dayRecords []*HealthCheckRecord
+		for _, rec := range byDay[day] {
+			if !archived[archiveKey{rec.EndpointID, rec.Timestamp.UnixNano()}] {
+				dayRecords = append(dayRecords, rec)
+			}
+		}
+		sort.Slice(dayRecords, func(i, j int) bool { return dayRecords[i].Timestamp.Before(dayRecords[j].Timestamp) })
+		if err := appendJSONL(path, dayRecords); err != nil {
+			return fmt.Errorf("failed to archive history: %w", err)
+		}
+	}
+	return nil
+}
+
+// archiveKey identifies a history record: an endpoint has at most one check
+// result per instant
+type archiveKey struct {
+	endpointID string
+	timestamp  int64
+}
+
+// archivedRecords returns the keys of the records already in the archive file
+// at path, which may not exist yet. Lines that can't be decoded are ignored.
+func archivedRecords(path string) (map[archiveKey]bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	archived := make(map[archiveKey]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		var rec struct {
+			EndpointID string    `json:"endpoint_id"`
+			Timestamp  time.Time `json:"timestamp"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			archived[archiveKey{rec.EndpointID, rec.Timestamp.UnixNano()}] = true
+		}
+	}
+	return archived, scanner.Err()
+}
+
+// appendJSONL appends records to path, one JSON object per line, and syncs
+// the file so the records are safe on disk before they are deleted
+func appendJSONL(path string, records []*HealthCheckRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// backupPrefix and backupTimeFormat name scheduled backup files so that they
+// sort chronologically, e.g. cronzee-20240131-020000.db
+const (
+	backupPrefix     = "cronzee-"
+	backupTimeFormat = "20060102-150405"
+)
+
+// startBackupRoutine writes a timestamped backup to config.BackupDir every
+// BackupInterval and keeps only the newest BackupKeep files. The schedule
+// continues from the newest existing backup, so restarts don't reset it.
+func startBackupRoutine(store BackupStore, config StorageConfig) {
+	if err := os.MkdirAll(config.BackupDir, 0700); err != nil {
+		log.Printf("Scheduled backups disabled: %v", err)
+		return
+	}
+
+	wait := time.Duration(0)
+	if files := listBackups(config.BackupDir); len(files) > 0 {
+		if info, err := os.Stat(files[len(files)-1]); err == nil {
+			wait = time.Until(info.ModTime().Add(config.BackupInterval))
+		}
+	}
+
+	timer := time.NewTimer(max(wait, 0))
+	for range timer.C {
+		path, err := writeBackup(store, config.BackupDir)
+		if err != nil {
+			log.Printf("Error writing scheduled backup: %v", err)
+		} else {
+			log.Printf("Wrote database backup: %s", path)
+			pruneBackups(config.BackupDir, config.BackupKeep)
+		}
+		timer.Reset(config.BackupInterval)
+	}
+}
+
+// writeBackup writes a new backup file into dir. The data goes to a temporary
+// file first so a failed backup never leaves a truncated file behind.
+func writeBackup(store BackupStore, dir string) (string, error) {
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupTimeFormat)+".db")
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := store.Backup(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to move backup into place: %w", err)
+	}
+	return path, nil
+}
+
+// listBackups returns the scheduled backup files in dir, oldest first
+func listBackups(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, backupPrefix+"*.db"))
+	sort.Strings(files)
+	return files
+}
+
+// pruneBackups deletes all but the newest keep backups in dir
+func pruneBackups(dir string, keep int) {
+	files := listBackups(dir)
+	if keep <= 0 || len(files) <= keep {
+		return
+	}
+	for _, f := range files[:len(files)-keep] {
+		if err := os.Remove(f); err != nil {
+			log.Printf("Error removing old backup %s: %v", f, err)
+			continue
+		}
+		log.Printf("Removed old backup: %s", f)
+	}
+}
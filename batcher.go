@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// batcher queues items for an exporter and sends them in batches of up to
+// size, at least every interval and sooner when a batch fills. While sending
+// falls behind at most maxPending items wait; beyond that the oldest are
+// dropped. Items from a failed send are dropped rather than retried, since
+// the history in the store is the record of truth.
+type batcher[T any] struct {
+	kind       string // what the items are, for log messages, e.g. "spans"
+	dest       string // where they go, for log messages
+	size       int
+	maxPending int
+	interval   time.Duration
+	send       func([]T) error
+
+	mu      sync.Mutex
+	pending []T
+	full    chan struct{}
+}
+
+// newBatcher creates a batcher that hands each batch to send
+func newBatcher[T any](kind, dest string, size, maxPending int, interval time.Duration, send func([]T) error) *batcher[T] {
+	return &batcher[T]{
+		kind:       kind,
+		dest:       dest,
+		size:       size,
+		maxPending: maxPending,
+		interval:   interval,
+		send:       send,
+		full:       make(chan struct{}, 1),
+	}
+}
+
+// add queues an item for the next batch
+func (b *batcher[T]) add(item T) {
+	b.mu.Lock()
+	b.pending = append(b.pending, item)
+	if over := len(b.pending) - b.maxPending; over > 0 {
+		b.pending = append(b.pending[:0:0], b.pending[over:]...)
+		log.Printf("Export to %s falling behind, dropped %d %s", b.dest, over, b.kind)
+	}
+	full := len(b.pending) >= b.size
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends every queued item, in batches of at most size
+func (b *batcher[T]) Flush() error {
+	b.mu.Lock()
+	items := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	for len(items) > 0 {
+		n := min(len(items), b.size)
+		if err := b.send(items[:n]); err != nil {
+			log.Printf("Error exporting %d %s to %s, dropping them: %v", len(items), b.kind, b.dest, err)
+			return err
+		}
+		items = items[n:]
+	}
+	return nil
+}
+
+// run sends queued items every interval, or sooner when a batch fills, until
+// ctx is cancelled
+func (b *batcher[T]) run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.full:
+		}
+		b.Flush()
+	}
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Check types supported by the monitor; an empty type means HTTP
+const (
+	CheckTypeHTTP      = "http"
+	CheckTypeDNS       = "dns"
+	CheckTypePing      = "ping"
+	CheckTypeGraphQL   = "graphql"
+	CheckTypeHeartbeat = "heartbeat"
+	CheckTypeWebSocket = "websocket"
+)
+
+// Address families an endpoint can be restricted to; empty allows either
+const (
+	AddressFamilyIPv4 = "ip4"
+	AddressFamilyIPv6 = "ip6"
+)
+
+// validAddressFamily reports whether f names a supported address family
+func validAddressFamily(f string) bool {
+	return f == "" || f == AddressFamilyIPv4 || f == AddressFamilyIPv6
+}
+
+// validateMethod checks an HTTP check's method, where empty means GET, and
+// that a request body is only sent with a method that carries one
+func validateMethod(method, body string) error {
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		if body != "" {
+			return fmt.Errorf("a request body needs method POST, PUT, PATCH or DELETE")
+		}
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("unsupported method %q: use GET, HEAD, OPTIONS, POST, PUT, PATCH or DELETE", method)
+	}
+	return nil
+}
+
+// validateBodySize checks an endpoint's body size assertions, where 0 means
+// no limit
+func validateBodySize(minBytes, maxBytes int64) error {
+	if minBytes < 0 || maxBytes < 0 {
+		return fmt.Errorf("body sizes can't be negative")
+	}
+	if maxBytes > 0 && minBytes > maxBytes {
+		return fmt.Errorf("assert_min_body_bytes %d is above assert_max_body_bytes %d", minBytes, maxBytes)
+	}
+	return nil
+}
+
+// redirectPolicy returns the CheckRedirect function for an endpoint's
+// max_redirects. Past the limit the check fails with an error naming it, so a
+// redirect loop fails fast instead of running into the timeout.
+func redirectPolicy(maxRedirects int) func(*http.Request, []*http.Request) error {
+	limit := maxRedirects
+	if limit == 0 {
+		limit = DefaultMaxRedirects
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if maxRedirects == MaxRedirectsNone {
+			return http.ErrUseLastResponse
+		}
+		// via holds the requests made so far, the same count net/http's
+		// default policy compares against its limit of 10
+		if len(via) >= limit {
+			return fmt.Errorf("too many redirects: stopped after %d", limit)
+		}
+		return nil
+	}
+}
+
+// familyNetwork narrows a dial or lookup network such as "tcp" or "ip" to the
+// address family, e.g. "tcp6"; with no family it is returned unchanged
+func familyNetwork(network, family string) string {
+	if family == "" {
+		return network
+	}
+	return strings.TrimRight(network, "46") + strings.TrimPrefix(family, "ip")
+}
+
+// heartbeatPath is the API path that heartbeat endpoints' jobs ping, followed
+// by the endpoint ID
+const heartbeatPath = "/api/ping/"
+
+// defaultGraphQLQuery is sent when a graphql check has no query configured;
+// every GraphQL server can answer it
+const defaultGraphQLQuery = "{ __typename }"
+
+// validCheckType reports whether t names a supported check type
+func validCheckType(t string) bool {
+	switch t {
+	case "", CheckTypeHTTP, CheckTypeDNS, CheckTypePing, CheckTypeGraphQL, CheckTypeHeartbeat, CheckTypeWebSocket:
+		return true
+	}
+	return false
+}
+
+// parseProxyURL parses a forward proxy URL, returning nil for an empty string
+func parseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return u, nil
+}
+
+// normalizeTarget checks that target can be checked with checkType and returns
+// it in canonical form. HTTP and GraphQL targets must be http or https URLs
+// with a host; one without a scheme gets https://, and the host is lowercased.
+// DNS and ping targets may be a bare host or a URL. Heartbeat URLs are never
+// fetched, so they are returned as is.
+func normalizeTarget(checkType, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	switch checkType {
+	case CheckTypeHeartbeat:
+		return target, nil
+	case CheckTypeDNS, CheckTypePing:
+		if host := checkHost(target); host == "" || strings.ContainsAny(host, " \t/") {
+			return "", fmt.Errorf("%q is not a host name or URL", target)
+		}
+		return target, nil
+	}
+
+	// WebSocket checks take ws and wss URLs instead of http and https
+	plain, secure := "http", "https"
+	if checkType == CheckTypeWebSocket {
+		plain, secure = "ws", "wss"
+	}
+	if !strings.Contains(target, "://") {
+		target = secure + "://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != plain && u.Scheme != secure {
+		return "", fmt.Errorf("unsupported scheme %q in %q, use %s or %s", u.Scheme, target, plain, secure)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%q has no host", target)
+	}
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), nil
+}
+
+// targetKey returns the form of target used to detect duplicate endpoints.
+// URLs that differ only in the case of the scheme or host, an explicit default
+// port or a trailing slash get the same key; the query is kept and the
+// fragment ignored. Anything that isn't an absolute URL is just lowercased.
+func targetKey(target string) string {
+	target = strings.TrimSpace(target)
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(target)
+	}
+
+	port := u.Port()
+	plain, secure := u.Scheme == "http" || u.Scheme == "ws", u.Scheme == "https" || u.Scheme == "wss"
+	if (plain && port == "80") || (secure && port == "443") {
+		port = ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	key := u.Scheme + "://" + host + strings.TrimRight(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// normalizeTargets applies normalizeTarget to an endpoint's URL and its
+// failover URLs
+func normalizeTargets(checkType, target string, targets []string) (string, []string, error) {
+	normalized, err := normalizeTarget(checkType, target)
+	if err != nil {
+		return "", nil, err
+	}
+	var list []string
+	if targets != nil {
+		list = make([]string, len(targets))
+	}
+	for i, t := range targets {
+		if list[i], err = normalizeTarget(checkType, t); err != nil {
+			return "", nil, err
+		}
+	}
+	return normalized, list, nil
+}
+
+// validateResolveOverride checks an endpoint's resolve_override. The override
+// only changes where checks connect, so it can't work through the endpoint's
+// own proxy, which resolves the host itself.
+func validateResolveOverride(override, proxyURL string) error {
+	if _, _, err := parseResolveOverride(override); err != nil {
+		return err
+	}
+	if override != "" && proxyURL != "" {
+		return fmt.Errorf("can't be combined with proxy_url, since the proxy resolves the host")
+	}
+	return nil
+}
+
+// validateExpectedIP checks that a DNS check's expected_ip is an IP address
+func validateExpectedIP(expected string) error {
+	if expected != "" && net.ParseIP(expected) == nil {
+		return fmt.Errorf("%q is not an IP address", expected)
+	}
+	return nil
+}
+
+// parseResolveOverride splits a "host:ip" override, like curl's --resolve
+// without the port, returning empty strings for an empty override. The IP may
+// be IPv6, with or without brackets.
+func parseResolveOverride(raw string) (host, ip string, err error) {
+	if raw == "" {
+		return "", "", nil
+	}
+	host, ip, ok := strings.Cut(raw, ":")
+	ip = strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]")
+	if !ok || host == "" || net.ParseIP(ip) == nil {
+		return "", "", fmt.Errorf("%q is not in host:ip form", raw)
+	}
+	return host, ip, nil
+}
+
+// The phases of an HTTP request that a timeout is attributed to
+const (
+	phaseDNS      = "DNS lookup"
+	phaseConnect  = "connect"
+	phaseTLS      = "TLS handshake"
+	phaseSend     = "request send"
+	phaseResponse = "response"
+)
+
+// requestTrace follows an HTTP request through its httptrace hooks, noting the
+// phase it has reached and the address it connected to
+type requestTrace struct {
+	phase      atomic.Value // string
+	remoteAddr atomic.Value // string
+}
+
+// trace returns req with hooks that update t. Each phase starts when its hook
+// fires, so the phase noted last is the one a timeout interrupted.
+func (t *requestTrace) trace(req *http.Request) *http.Request {
+	set := func(phase string) { t.phase.Store(phase) }
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(phaseDNS) },
+		ConnectStart:      func(string, string) { set(phaseConnect) },
+		TLSHandshakeStart: func() { set(phaseTLS) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			set(phaseSend)
+			if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+				t.remoteAddr.Store(host)
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { set(phaseResponse) },
+	}))
+}
+
+// currentPhase returns the phase the request reached. Before any hook fires it
+// is still connecting, e.g. to a proxy.
+func (t *requestTrace) currentPhase() string {
+	if phase, ok := t.phase.Load().(string); ok {
+		return phase
+	}
+	return phaseConnect
+}
+
+// address returns the IP address the request connected to, if it got that far
+func (t *requestTrace) address() string {
+	addr, _ := t.remoteAddr.Load().(string)
+	return addr
+}
+
+// describeRequestError turns an HTTP client error into a check error message,
+// naming the phase that timed out as traced by t. Looking up and connecting
+// are limited by the connect timeout, if the endpoint has one.
+func describeRequestError(err error, endpoint Endpoint, t *requestTrace) string {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return fmt.Sprintf("request failed: %v", err)
+	}
+	phase, limit := t.currentPhase(), endpoint.Timeout
+	if (phase == phaseDNS || phase == phaseConnect) && endpoint.ConnectTimeout > 0 {
+		limit = endpoint.ConnectTimeout
+	}
+	return fmt.Sprintf("%s timeout after %v: %v", phase, limit, err)
+}
+
+// checkGraphQLResponse verifies a GraphQL response has no top-level errors
+// and, if dataPath is set, a non-null value at that dotted path under data
+func checkGraphQLResponse(body []byte, dataPath string) string {
+	var resp struct {
+		Data   interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Sprintf("invalid GraphQL response: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return "graphql error: " + strings.Join(messages, "; ")
+	}
+	if dataPath != "" {
+		if v, ok := lookupJSONPath(resp.Data, dataPath); !ok || v == nil {
+			return fmt.Sprintf("graphql response has no data at %s", dataPath)
+		}
+	}
+	return ""
+}
+
+// checkJSONPath verifies the value at path in a JSON body. With an empty
+// expected value the path only has to be present and not null. Otherwise
+// expected is compared as JSON when it parses as JSON (true, 42, "UP") and as
+// a plain string when it doesn't, so both UP and "UP" match the string "UP".
+func checkJSONPath(body []byte, path, expected string) string {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Sprintf("json path %s: response is not valid JSON: %v", path, err)
+	}
+	got, ok := lookupJSONPath(doc, path)
+	if !ok || (got == nil && expected == "") {
+		return fmt.Sprintf("json path %s not found in response", path)
+	}
+	if expected == "" {
+		return ""
+	}
+
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		want = expected
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		return fmt.Sprintf("json path %s mismatch: got %s, expected %s", path, gotJSON, wantJSON)
+	}
+	return ""
+}
+
+// lookupJSONPath follows a dotted path such as "db.status" or "$.items.0.id"
+// through decoded JSON. Numeric segments index into arrays. It reports false
+// if any segment is missing.
+func lookupJSONPath(v interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// readBody reads at most limit bytes of an HTTP response body. Gzip bodies the
+// transport left encoded (e.g. because the request set its own Accept-Encoding)
+// are decompressed, with the limit applied to the decompressed size.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	body, _, err := readBodyCounting(resp, limit, 0)
+	return body, err
+}
+
+// readBodyCounting reads a body like readBody, then counts the bytes past
+// limit without keeping them, until countTo bytes have been seen in all. It
+// returns the body and the size seen, so a body size assertion above the
+// memory cap costs reading time but not memory.
+func readBodyCounting(resp *http.Response, limit, countTo int64) ([]byte, int64, error) {
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+
+	var r io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err == io.EOF {
+			return nil, 0, nil // empty body
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit))
+	size := int64(len(body))
+	if err != nil || size < limit || size >= countTo {
+		return body, size, err
+	}
+	skipped, err := io.Copy(io.Discard, io.LimitReader(r, countTo-size))
+	return body, size + skipped, err
+}
+
+// bodyCountLimit returns how many bytes of a response body must be seen to
+// check the endpoint's body size assertions, or 0 if it has none. One byte
+// past assert_max_body_bytes is needed so a larger body can be told apart.
+func bodyCountLimit(endpoint Endpoint) int64 {
+	limit := endpoint.MinBodyBytes
+	if endpoint.MaxBodyBytes > 0 {
+		limit = max(limit, endpoint.MaxBodyBytes+1)
+	}
+	return limit
+}
+
+// checkBodySize verifies the size of a body, counted up to countTo bytes by
+// readBodyCounting, against an endpoint's assert_min_body_bytes and
+// assert_max_body_bytes, where 0 means no limit. It catches empty or truncated
+// responses that still get a 200.
+func checkBodySize(size, countTo, minBytes, maxBytes int64) string {
+	if size < minBytes {
+		return fmt.Sprintf("response body too small: got %d bytes, expected at least %d", size, minBytes)
+	}
+	if maxBytes > 0 && size > maxBytes {
+		// The rest of the body was never read
+		if size >= countTo {
+			return fmt.Sprintf("response body too large: got at least %d bytes, expected at most %d", size, maxBytes)
+		}
+		return fmt.Sprintf("response body too large: got %d bytes, expected at most %d", size, maxBytes)
+	}
+	return ""
+}
+
+// bodySnapshot returns up to limit bytes of body as text for a history record.
+// A negative limit disables snapshots.
+func bodySnapshot(body []byte, limit int) string {
+	if limit < 0 || len(body) == 0 {
+		return ""
+	}
+	if limit == 0 {
+		limit = DefaultFailureSnapshotBytes
+	}
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	snapshot := strings.ToValidUTF8(string(body), "\uFFFD")
+	if truncated {
+		snapshot += "…"
+	}
+	return snapshot
+}
+
+// checkHost returns the hostname to check, accepting either a full URL or a bare host
+func checkHost(target string) string {
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return target
+}
+
+// probeDNS resolves the host of target and optionally asserts an expected IP is returned
+func (m *Monitor) probeDNS(endpoint Endpoint, target string) checkResult {
+	host := checkHost(target)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(m.ctx, endpoint.Timeout)
+	defer cancel()
+
+	// With an address family only A or AAAA records count
+	var resolver net.Resolver
+	ips, err := resolver.LookupIP(ctx, familyNetwork("ip", endpoint.AddressFamily), host)
+	responseTime := time.Since(start)
+
+	if err != nil {
+		return checkResult{responseTime: responseTime, err: fmt.Sprintf("dns lookup failed: %v", err)}
+	}
+	if len(ips) == 0 {
+		return checkResult{responseTime: responseTime, err: fmt.Sprintf("dns lookup for %s returned no records", host)}
+	}
+
+	if expected := endpoint.ExpectedIP; expected != "" {
+		want := net.ParseIP(expected)
+		found := false
+		addrs := make([]string, len(ips))
+		for i, ip := range ips {
+			addrs[i] = ip.String()
+			if want != nil && ip.Equal(want) {
+				found = true
+			}
+		}
+		if !found {
+			return checkResult{
+				responseTime: responseTime,
+				err:          fmt.Sprintf("dns lookup for %s did not return %s (got %s)", host, expected, strings.Join(addrs, ", ")),
+			}
+		}
+	}
+
+	return checkResult{responseTime: responseTime}
+}
+
+// heartbeatGrace returns how late a ping may arrive before it counts as
+// missed: a tenth of the check interval, so a job's own run time doesn't
+// trip the deadline
+func heartbeatGrace(interval time.Duration) time.Duration {
+	return interval / 10
+}
+
+// checkHeartbeat fails a heartbeat endpoint whose job hasn't pinged within its
+// check interval plus grace. Until then it just moves the next check to the
+// deadline.
+func (m *Monitor) checkHeartbeat(state *EndpointState) {
+	state.mu.Lock()
+	// A new or reloaded endpoint gets a full interval for its first ping
+	if state.lastPing.IsZero() {
+		state.lastPing = time.Now()
+	}
+	last, interval := state.lastPing, state.CheckInterval
+	if deadline := last.Add(interval + heartbeatGrace(interval)); time.Now().Before(deadline) {
+		state.NextCheck = deadline
+		state.mu.Unlock()
+		return
+	}
+	state.mu.Unlock()
+
+	m.handleCheckFailure(state,
+		fmt.Sprintf("no heartbeat received for %v (expected every %v)", time.Since(last).Round(time.Second), interval),
+		0, nil)
+}
+
+// pingSeq numbers outgoing ICMP echo requests so replies can be matched
+var pingSeq atomic.Uint32
+
+// probePing sends an ICMP echo request to the host of target and records the round-trip time
+func (m *Monitor) probePing(endpoint Endpoint, target string) checkResult {
+	host := checkHost(target)
+
+	ctx, cancel := context.WithTimeout(m.ctx, endpoint.Timeout)
+	defer cancel()
+
+	ip, err := resolvePingAddr(ctx, host, endpoint.AddressFamily)
+	if err != nil {
+		return checkResult{err: fmt.Sprintf("ping failed: %v", err)}
+	}
+
+	rtt, err := pingHost(ctx, ip)
+	if err != nil {
+		return checkResult{responseTime: rtt, err: fmt.Sprintf("ping failed: %v", err), remoteAddr: ip.String()}
+	}
+
+	return checkResult{responseTime: rtt, remoteAddr: ip.String()}
+}
+
+// resolvePingAddr picks the address to ping for host: the first one of the
+// given family, or with no family the first IPv4 address if there is one
+func resolvePingAddr(ctx context.Context, host, family string) (net.IP, error) {
+	var resolver net.Resolver
+	ips, err := resolver.LookupIP(ctx, familyNetwork("ip", family), host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip, nil
+		}
+	}
+	return ips[0], nil
+}
+
+// pingHost sends a single ICMP echo to ip and waits for the matching reply.
+// It prefers unprivileged datagram ICMP sockets and falls back to raw sockets,
+// which need root or CAP_NET_RAW.
+func pingHost(ctx context.Context, ip net.IP) (time.Duration, error) {
+	v4 := ip.To4() != nil
+
+	conn, privileged, err := listenICMP(v4)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Reads don't watch ctx, so wake them up when it is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	// Raw sockets see every echo reply on the host, so ours carry the process
+	// ID. Datagram sockets get the ID rewritten by the kernel to the socket's
+	// local port, which is what the reply comes back with.
+	id := os.Getpid() & 0xffff
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !privileged {
+		id = addr.Port
+	}
+	seq := int(pingSeq.Add(1) & 0xffff)
+	msg := icmp.Message{
+		Code: 0,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("cronzee")},
+	}
+	proto := 1 // ICMP for IPv4
+	msg.Type = ipv4.ICMPTypeEcho
+	if !v4 {
+		proto = 58 // ICMPv6
+		msg.Type = ipv6.ICMPTypeEchoRequest
+	}
+	payload, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		dst = &net.IPAddr{IP: ip}
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(payload, dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return time.Since(start), err
+		}
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			continue
+		}
+		if reply.Type != ipv4.ICMPTypeEchoReply && reply.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.ID == id && echo.Seq == seq {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// listenICMP opens an ICMP socket, reporting whether it is a privileged raw socket
+func listenICMP(v4 bool) (*icmp.PacketConn, bool, error) {
+	network, rawNetwork, addr := "udp4", "ip4:icmp", "0.0.0.0"
+	if !v4 {
+		network, rawNetwork, addr = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	if conn, err := icmp.ListenPacket(network, addr); err == nil {
+		return conn, false, nil
+	}
+	conn, err := icmp.ListenPacket(rawNetwork, addr)
+	if err != nil {
+		return nil, false, errors.New("icmp not permitted: run as root, grant CAP_NET_RAW, or allow unprivileged ping via net.ipv4.ping_group_range")
+	}
+	return conn, true, nil
+}
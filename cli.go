@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// checkOnceOptions describes the endpoint checked by -check-url
+type checkOnceOptions struct {
+	URL            string
+	Type           string
+	Method         string
+	Timeout        time.Duration
+	ExpectedStatus int
+}
+
+// runCheckOnce checks a URL once, prints the result to out and returns the
+// exit code: 0 if the check passed, 1 if it failed, 2 for invalid options.
+// Global check settings such as proxy_url, user_agent and defaults.headers are
+// taken from the config file when it exists. Nothing is stored and no alerts
+// are sent.
+func runCheckOnce(configFile string, opts checkOnceOptions, out io.Writer) int {
+	config, err := LoadConfig(configFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		config = &Config{}
+	} else if err != nil {
+		fmt.Fprintf(out, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	if !validCheckType(opts.Type) || opts.Type == CheckTypeHeartbeat {
+		fmt.Fprintf(out, "Invalid check type: %s\n", opts.Type)
+		return 2
+	}
+	if err := validateMethod(opts.Method, ""); err != nil {
+		fmt.Fprintf(out, "Invalid method: %v\n", err)
+		return 2
+	}
+	if opts.Timeout <= 0 {
+		fmt.Fprintln(out, "Timeout must be positive")
+		return 2
+	}
+	endpoint := Endpoint{
+		Name:           opts.URL,
+		Type:           opts.Type,
+		URL:            opts.URL,
+		Method:         opts.Method,
+		Timeout:        opts.Timeout,
+		ExpectedStatus: opts.ExpectedStatus,
+	}
+
+	// The monitor gets an empty store and no alerting, so the check can't
+	// touch the real database or notify anyone
+	monitor := NewMonitor(&Config{
+		UserAgent:    config.UserAgent,
+		ProxyURL:     config.ProxyURL,
+		MaxBodyBytes: config.MaxBodyBytes,
+		Defaults:     config.Defaults,
+	}, NewMemoryStore(&StorageConfig{}))
+	defer monitor.Stop()
+
+	result := monitor.prober(endpoint.Type)(endpoint, endpoint.URL)
+	details := result.responseTime.Round(time.Millisecond).String()
+	if result.remoteAddr != "" {
+		details += ", " + result.remoteAddr
+	}
+	if result.err != "" {
+		fmt.Fprintf(out, "✗ unhealthy %s (%s): %s\n", endpoint.URL, details, result.err)
+		return 1
+	}
+	fmt.Fprintf(out, "✓ healthy %s (%s)\n", endpoint.URL, details)
+	return 0
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"regexp"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,26 +17,280 @@ import (
 type Config struct {
 	Server        ServerConfig  `yaml:"server"`
 	CheckInterval time.Duration `yaml:"check_interval"`
+	// DefaultTimeout is the check timeout of endpoints that don't set their own
+	DefaultTimeout time.Duration `yaml:"default_timeout"`
 	Endpoints     []Endpoint    `yaml:"endpoints"`
 	Alerting      Alerting      `yaml:"alerting"`
+	Storage       StorageConfig `yaml:"storage"`
+	// UserAgent is the default User-Agent for HTTP checks; empty means Cronzee/<version>
+	UserAgent string `yaml:"user_agent"`
+	// ProxyURL is the default forward proxy for HTTP checks; empty falls back
+	// to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	ProxyURL string `yaml:"proxy_url"`
+	// MaxBodyBytes caps how much of each HTTP check response body is read
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+	// FailureSnapshotBytes is how much of the response body to keep with a
+	// failed HTTP check's history record; -1 disables snapshots
+	FailureSnapshotBytes int `yaml:"failure_snapshot_bytes"`
+	// RedactHeaders names the headers whose values are masked in failure
+	// messages and body snapshots before they are logged or stored
+	RedactHeaders []string `yaml:"redact_headers"`
+	// redactPattern matches the redacted headers' values in text, compiled
+	// from RedactHeaders when the config is loaded
+	redactPattern *regexp.Regexp
+	// Defaults are settings applied to every endpoint
+	Defaults DefaultsConfig `yaml:"defaults"`
+	// Regression flags endpoints that have become slower than usual
+	Regression RegressionConfig `yaml:"regression"`
+	// Exporters send every check result to external systems as well
+	Exporters ExportersConfig `yaml:"exporters"`
+}
+
+// DefaultMaxBodyBytes is the response body cap used when max_body_bytes is unset
+const DefaultMaxBodyBytes = 1 << 20
+
+// DefaultCheckTimeout is the check timeout used when neither the endpoint nor
+// default_timeout sets one
+const DefaultCheckTimeout = 10 * time.Second
+
+// DefaultCheckInterval is the check interval used when check_interval is unset
+const DefaultCheckInterval = 30 * time.Second
+
+// DefaultFailureSnapshotBytes is the body snapshot size used when failure_snapshot_bytes is unset
+const DefaultFailureSnapshotBytes = 2048
+
+// DefaultLogMaxSizeMB and DefaultLogMaxBackups are the log rotation settings
+// used when server.log_max_size_mb and server.log_max_backups are unset or 0
+const (
+	DefaultLogMaxSizeMB  = 100
+	DefaultLogMaxBackups = 5
+)
+
+// DefaultRefreshInterval and DefaultChartPoints are the dashboard settings used
+// when server.refresh_interval and server.chart_points are unset
+const (
+	DefaultRefreshInterval = 30 * time.Second
+	DefaultChartPoints     = 50
+)
+
+// DefaultTitle is the dashboard title used when server.title is unset
+const DefaultTitle = "Site Watch"
+
+// accentColorPattern matches the hex colors accepted for server.accent_color
+var accentColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// DefaultRateLimitBurst is the burst allowed when server.rate_limit is set but
+// server.rate_limit_burst is not; the dashboard loads several resources at once
+const DefaultRateLimitBurst = 20
+
+// checkTimeout returns the timeout for checks of an endpoint: its own, then
+// default_timeout, then DefaultCheckTimeout
+func (c *Config) checkTimeout(endpoint Endpoint) time.Duration {
+	if endpoint.Timeout > 0 {
+		return endpoint.Timeout
+	}
+	if c.DefaultTimeout > 0 {
+		return c.DefaultTimeout
+	}
+	return DefaultCheckTimeout
+}
+
+// checkInterval returns how often an endpoint is checked: its own interval,
+// or check_interval if it has none
+func (c *Config) checkInterval(stored *StoredEndpoint) time.Duration {
+	if stored.CheckInterval > 0 {
+		return stored.CheckInterval
+	}
+	return c.CheckInterval
+}
+
+// DefaultsConfig holds settings shared by all endpoints
+type DefaultsConfig struct {
+	// Headers are sent with every HTTP and GraphQL check; an endpoint's own
+	// headers override them
+	Headers map[string]string `yaml:"headers"`
+}
+
+// RegressionConfig marks an endpoint degraded while the median of its last
+// RecentChecks response times is more than Multiplier times its baseline, the
+// median over BaselineWindow. A zero Multiplier disables it.
+type RegressionConfig struct {
+	Multiplier     float64       `yaml:"multiplier"`
+	BaselineWindow time.Duration `yaml:"baseline_window"`
+	RecentChecks   int           `yaml:"recent_checks"`
+	Alert          bool          `yaml:"alert"`
+}
+
+// ExportersConfig configures the systems that receive every check result
+type ExportersConfig struct {
+	InfluxDB InfluxDBConfig `yaml:"influxdb"`
+	// StatsDAddress is the host:port of a StatsD server that gets a timing
+	// and an outcome counter per check; StatsDTags sends the endpoint and
+	// status as DogStatsD tags instead of in the metric names
+	StatsDAddress string `yaml:"statsd_address"`
+	StatsDPrefix  string `yaml:"statsd_prefix"`
+	StatsDTags    bool   `yaml:"statsd_tags"`
+	// OTLP sends a trace span per check to an OpenTelemetry collector
+	OTLP OTLPConfig `yaml:"otlp"`
+}
+
+// OTLPConfig sends a span for every check to an OpenTelemetry collector with
+// OTLP over HTTP. Endpoint is the collector's base URL, e.g.
+// http://localhost:4318; spans go to its /v1/traces. Headers are added to
+// each export, e.g. for authentication. Propagate adds a traceparent header to
+// HTTP and GraphQL checks. An empty Endpoint disables tracing.
+type OTLPConfig struct {
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"service_name"`
+	Propagate   bool              `yaml:"propagate"`
+}
+
+// InfluxDBConfig writes check results to InfluxDB through its line protocol
+// HTTP API. Setting Bucket uses the v2 API (/api/v2/write), otherwise
+// Database uses the v1 API (/write). Results are sent in batches of up to
+// BatchSize, at least every FlushInterval. An empty URL disables it.
+type InfluxDBConfig struct {
+	URL           string        `yaml:"url"`
+	Org           string        `yaml:"org"`
+	Bucket        string        `yaml:"bucket"`
+	Token         string        `yaml:"token"`
+	Database      string        `yaml:"database"`
+	Username      string        `yaml:"username"`
+	Password      string        `yaml:"password"`
+	Measurement   string        `yaml:"measurement"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	BatchSize     int           `yaml:"batch_size"`
 }
 
 // ServerConfig represents web server configuration
 type ServerConfig struct {
-	Enabled bool `yaml:"enabled"`
-	Port    int  `yaml:"port"`
+	Enabled        bool     `yaml:"enabled"`
+	BindAddress    string   `yaml:"bind_address"`
+	Port           int      `yaml:"port"`
+	ReadOnly       bool     `yaml:"read_only"`
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// BackupToken enables GET /api/backup for requests bearing this token
+	BackupToken string `yaml:"backup_token"`
+	// IncidentToken enables the /api/incidents endpoints for external tools
+	// bearing this token
+	IncidentToken string `yaml:"incident_token"`
+	// RateLimit caps each client IP, or all clients together with
+	// RateLimitGlobal, at this many /api requests per second, allowing bursts
+	// of RateLimitBurst
+	RateLimit       float64 `yaml:"rate_limit"`
+	RateLimitBurst  int     `yaml:"rate_limit_burst"`
+	RateLimitGlobal bool    `yaml:"rate_limit_global"`
+	// RefreshInterval is how often the dashboard reloads the status, and
+	// ChartPoints how many recent checks each endpoint's sparkline shows
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	ChartPoints     int           `yaml:"chart_points"`
+	// Title and AccentColor brand the dashboard; AccentColor is a hex color
+	// such as "#0f766e"
+	Title       string `yaml:"title"`
+	AccentColor string `yaml:"accent_color"`
+	// LogFile sends the log to this file instead of stdout, rotating it at
+	// LogMaxSizeMB and keeping LogMaxBackups old files
+	LogFile       string `yaml:"log_file"`
+	LogMaxSizeMB  int    `yaml:"log_max_size_mb"`
+	LogMaxBackups int    `yaml:"log_max_backups"`
+}
+
+// StorageConfig represents health history storage configuration
+type StorageConfig struct {
+	// MaxRecordsPerEndpoint is enforced by the periodic cleanup rather than on
+	// every save, so an endpoint can go over it until the next cleanup runs
+	MaxRecordsPerEndpoint int `yaml:"max_records_per_endpoint"`
+	// BackupDir enables scheduled database backups into this directory
+	BackupDir      string        `yaml:"backup_dir"`
+	BackupInterval time.Duration `yaml:"backup_interval"`
+	BackupKeep     int           `yaml:"backup_keep"`
+	// ArchiveDir keeps history removed by cleanup as daily JSONL files here
+	ArchiveDir string `yaml:"archive_dir"`
+	// CleanupInterval is how often history past retention is removed
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	// OpenTimeout is how long to wait for another process to release the
+	// BoltDB file lock; it comes from the -db-timeout flag
+	OpenTimeout time.Duration `yaml:"-"`
+	// VerifyOnOpen checks every page of the BoltDB file when it is opened, so
+	// corruption is found at startup; it is set by the -recover flag
+	VerifyOnOpen bool `yaml:"-"`
 }
 
+// DefaultDBOpenTimeout is how long opening a locked BoltDB file waits when
+// no timeout is set
+const DefaultDBOpenTimeout = time.Second
+
+// DefaultCleanupInterval is how often old history is cleaned up when
+// cleanup_interval is unset
+const DefaultCleanupInterval = time.Hour
+
+// ExpectedStatusAny is the expected_status sentinel that accepts any HTTP
+// status code, so the check only verifies that the endpoint responds
+const ExpectedStatusAny = -1
+
+// validExpectedStatus reports whether code can be used as expected_status:
+// an HTTP status code, ExpectedStatusAny, or 0 for the default of 200
+func validExpectedStatus(code int) bool {
+	return code == 0 || code == ExpectedStatusAny || (code >= 100 && code <= 599)
+}
+
+// DefaultMaxRedirects is how many redirects an HTTP check follows when
+// max_redirects is unset, the same as Go's default client
+const DefaultMaxRedirects = 10
+
+// MaxRedirectsNone is the max_redirects sentinel that doesn't follow
+// redirects, so the redirect response itself is checked
+const MaxRedirectsNone = -1
+
 // Endpoint represents a monitored endpoint
 type Endpoint struct {
-	Name             string            `yaml:"name"`
-	URL              string            `yaml:"url"`
-	Method           string            `yaml:"method"`
-	Timeout          time.Duration     `yaml:"timeout"`
-	ExpectedStatus   int               `yaml:"expected_status"`
-	Headers          map[string]string `yaml:"headers"`
-	FailureThreshold int               `yaml:"failure_threshold"`
-	SuccessThreshold int               `yaml:"success_threshold"`
+	Name                string            `yaml:"name"`
+	Type                string            `yaml:"type"`
+	URL                 string            `yaml:"url"`
+	URLs                []string          `yaml:"urls"`
+	Method              string            `yaml:"method"`
+	Body                string            `yaml:"body"`
+	Timeout             time.Duration     `yaml:"timeout"`
+	ConnectTimeout      time.Duration     `yaml:"connect_timeout"`
+	ExpectedStatus      int               `yaml:"expected_status"`
+	Headers             map[string]string `yaml:"headers"`
+	ExpectedHeaders     map[string]string `yaml:"expected_headers"`
+	ExpectedIP          string            `yaml:"expected_ip"`
+	GraphQLQuery        string            `yaml:"graphql_query"`
+	GraphQLDataPath     string            `yaml:"graphql_data_path"`
+	JSONPath            string            `yaml:"json_path"`
+	JSONPathExpected    string            `yaml:"json_path_expected"`
+	Invert              bool              `yaml:"invert"`
+	DependsOn           []string          `yaml:"depends_on"`
+	Tags                []string          `yaml:"tags"`
+	AlwaysAlert         bool              `yaml:"always_alert"`
+	AlertOnFirstFailure bool              `yaml:"alert_on_first_failure"`
+	SlackMention        string            `yaml:"slack_mention"`
+	AlertEmails         []string          `yaml:"alert_emails"`
+	UserAgent           string            `yaml:"user_agent"`
+	ProxyURL            string            `yaml:"proxy_url"`
+	AddressFamily       string            `yaml:"address_family"`
+	ResolveOverride     string            `yaml:"resolve_override"`
+	DisableKeepAlives   bool              `yaml:"disable_keep_alives"`
+	MaxRedirects        int               `yaml:"max_redirects"`
+	WebSocketPing       bool              `yaml:"websocket_ping"`
+	MinBodyBytes        int64             `yaml:"assert_min_body_bytes"`
+	MaxBodyBytes        int64             `yaml:"assert_max_body_bytes"`
+	BodyMustNotContain  string            `yaml:"body_must_not_contain"`
+	HeartbeatToken      string            `yaml:"heartbeat_token"`
+	Backoff             bool              `yaml:"backoff"`
+	BackoffMax          time.Duration     `yaml:"backoff_max"`
+	FailureThreshold    int               `yaml:"failure_threshold"`
+	FailureDuration     time.Duration     `yaml:"failure_duration"`
+	SuccessThreshold    int               `yaml:"success_threshold"`
+	ResponseTimeSLO     time.Duration     `yaml:"response_time_slo"`
+}
+
+// Targets returns the addresses to check in order: URL followed by URLs. The
+// endpoint is healthy if any of them passes.
+func (e Endpoint) Targets() []string {
+	return mergeURLs(e.URL, e.URLs)
 }
 
 // Alerting represents alerting configuration
@@ -44,7 +303,115 @@ type Alerting struct {
 	EmailConfig  EmailConfig       `yaml:"email_config"`
 	SlackEnabled bool              `yaml:"slack_enabled"`
 	SlackWebhook string            `yaml:"slack_webhook"`
+	SlackMention string            `yaml:"slack_mention"`
 	CustomFields map[string]string `yaml:"custom_fields"`
+	// SlackBotToken and SlackChannel post Slack alerts with chat.postMessage,
+	// which threads each incident's reminders and recovery under its failure
+	SlackBotToken string `yaml:"slack_bot_token"`
+	SlackChannel  string `yaml:"slack_channel"`
+	// SlackTemplate is a text/template replacing the Slack message layout
+	SlackTemplate string `yaml:"slack_template"`
+	// WebhookURLs and SlackWebhooks add destinations alongside webhook_url and slack_webhook
+	WebhookURLs   []string `yaml:"webhook_urls"`
+	SlackWebhooks []string `yaml:"slack_webhooks"`
+	// RepeatInterval re-sends failure alerts while an endpoint stays unhealthy (0 = disabled)
+	RepeatInterval time.Duration `yaml:"repeat_interval"`
+	// FlapThreshold marks an endpoint as flapping after this many status changes within FlapWindow (0 = disabled)
+	FlapThreshold int           `yaml:"flap_threshold"`
+	FlapWindow    time.Duration `yaml:"flap_window"`
+	// QuietHours holds back alerts for endpoints not flagged always_alert
+	QuietHours QuietHours `yaml:"quiet_hours"`
+	// SummarySchedule sends an uptime report through the alert channels: "daily", "weekly" or "" (disabled)
+	SummarySchedule string `yaml:"summary_schedule"`
+	// AlertmanagerURL pushes outages to a Prometheus Alertmanager, e.g.
+	// http://alertmanager:9093, labelled with AlertmanagerSeverity
+	AlertmanagerURL      string `yaml:"alertmanager_url"`
+	AlertmanagerSeverity string `yaml:"alertmanager_severity"`
+}
+
+// WebhookTargets returns every configured generic webhook URL
+func (a *Alerting) WebhookTargets() []string {
+	return mergeURLs(a.WebhookURL, a.WebhookURLs)
+}
+
+// SlackTargets returns every configured Slack webhook URL
+func (a *Alerting) SlackTargets() []string {
+	return mergeURLs(a.SlackWebhook, a.SlackWebhooks)
+}
+
+// mergeURLs combines a singular URL setting with its list form, dropping
+// blanks and duplicates
+func mergeURLs(single string, list []string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range append([]string{single}, list...) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// QuietHours is a daily window, e.g. 22:00 to 07:00, during which alerts are
+// dropped or, with Digest set, collected and sent together when it ends
+type QuietHours struct {
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	Timezone string `yaml:"timezone"`
+	Digest   bool   `yaml:"digest"`
+}
+
+// enabled reports whether a quiet hours window is configured
+func (q QuietHours) enabled() bool {
+	return q.Start != "" && q.End != "" && q.Start != q.End
+}
+
+// location returns the time zone the window is defined in, local time by default
+func (q QuietHours) location() *time.Location {
+	if loc, err := time.LoadLocation(q.Timezone); err == nil && q.Timezone != "" {
+		return loc
+	}
+	return time.Local
+}
+
+// clockTime parses an HH:MM time of day into minutes after midnight
+func clockTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active reports whether t falls within quiet hours. A window whose end is
+// before its start runs past midnight.
+func (q QuietHours) Active(t time.Time) bool {
+	if !q.enabled() {
+		return false
+	}
+	start, err1 := clockTime(q.Start)
+	end, err2 := clockTime(q.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	t = t.In(q.location())
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// NextEnd returns the first end of quiet hours after t
+func (q QuietHours) NextEnd(t time.Time) time.Time {
+	end, _ := clockTime(q.End)
+	t = t.In(q.location())
+	next := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
 }
 
 // EmailConfig represents email configuration
@@ -57,6 +424,18 @@ type EmailConfig struct {
 	Password string   `yaml:"password"`
 }
 
+// validateAlertEmails checks that every entry of an endpoint's alert_emails
+// is a plain address such as ops@example.com, as SMTP needs for recipients
+func validateAlertEmails(emails []string) error {
+	for _, email := range emails {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			return fmt.Errorf("invalid email address %q", email)
+		}
+	}
+	return nil
+}
+
 // LoadConfig loads configuration from a YAML file
 func LoadConfig(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename)
@@ -69,22 +448,65 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	for _, problem := range config.legacyProblems() {
+		log.Printf("Config warning: %s; this will be an error in the next release", problem)
+	}
+
 	// Set defaults
-	if config.CheckInterval == 0 {
-		config.CheckInterval = 30 * time.Second
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultCheckInterval
+	}
+	if config.DefaultTimeout == 0 {
+		config.DefaultTimeout = DefaultCheckTimeout
+	}
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if config.FailureSnapshotBytes == 0 {
+		config.FailureSnapshotBytes = DefaultFailureSnapshotBytes
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = DefaultRedactHeaders
+	}
+	config.redactPattern, err = compileRedactPattern(config.RedactHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file: redact_headers: %w", err)
 	}
 	
 	if config.Server.Port == 0 {
 		config.Server.Port = 8080
 	}
+	if config.Server.RefreshInterval == 0 {
+		config.Server.RefreshInterval = DefaultRefreshInterval
+	}
+	if config.Server.ChartPoints == 0 {
+		config.Server.ChartPoints = DefaultChartPoints
+	}
+	if config.Server.LogMaxSizeMB == 0 {
+		config.Server.LogMaxSizeMB = DefaultLogMaxSizeMB
+	}
+	if config.Server.LogMaxBackups == 0 {
+		config.Server.LogMaxBackups = DefaultLogMaxBackups
+	}
+	if config.Server.Title == "" {
+		config.Server.Title = DefaultTitle
+	}
+	if config.Server.RateLimit > 0 && config.Server.RateLimitBurst == 0 {
+		config.Server.RateLimitBurst = DefaultRateLimitBurst
+	}
 
 	for i := range config.Endpoints {
+		if config.Endpoints[i].URL == "" && len(config.Endpoints[i].URLs) > 0 {
+			config.Endpoints[i].URL = config.Endpoints[i].URLs[0]
+		}
+		// Invalid URLs are left as they are for Validate to report
+		ep := &config.Endpoints[i]
+		if target, targets, err := normalizeTargets(ep.Type, ep.URL, ep.URLs); err == nil {
+			ep.URL, ep.URLs = target, targets
+		}
 		if config.Endpoints[i].Method == "" {
 			config.Endpoints[i].Method = "GET"
 		}
-		if config.Endpoints[i].Timeout == 0 {
-			config.Endpoints[i].Timeout = 10 * time.Second
-		}
 		if config.Endpoints[i].ExpectedStatus == 0 {
 			config.Endpoints[i].ExpectedStatus = 200
 		}
@@ -96,5 +518,240 @@ func LoadConfig(filename string) (*Config, error) {
 		}
 	}
 
+	if config.Storage.BackupDir != "" {
+		if config.Storage.BackupInterval == 0 {
+			config.Storage.BackupInterval = 24 * time.Hour
+		}
+		if config.Storage.BackupKeep == 0 {
+			config.Storage.BackupKeep = 7
+		}
+	}
+
+	if config.Alerting.FlapWindow == 0 {
+		config.Alerting.FlapWindow = time.Hour
+	}
+	if config.Alerting.AlertmanagerURL != "" && config.Alerting.AlertmanagerSeverity == "" {
+		config.Alerting.AlertmanagerSeverity = "critical"
+	}
+
+	if config.Exporters.OTLP.Endpoint != "" && config.Exporters.OTLP.ServiceName == "" {
+		config.Exporters.OTLP.ServiceName = "cronzee"
+	}
+	if config.Exporters.StatsDAddress != "" && config.Exporters.StatsDPrefix == "" {
+		config.Exporters.StatsDPrefix = "cronzee"
+	}
+	if influx := &config.Exporters.InfluxDB; influx.URL != "" {
+		if influx.Measurement == "" {
+			influx.Measurement = "cronzee_check"
+		}
+		if influx.FlushInterval == 0 {
+			influx.FlushInterval = 10 * time.Second
+		}
+		if influx.BatchSize == 0 {
+			influx.BatchSize = 1000
+		}
+	}
+
+	// The baseline can't reach further back than the history that is kept
+	if config.Regression.BaselineWindow == 0 {
+		config.Regression.BaselineWindow = DataRetentionDays * 24 * time.Hour
+	}
+	if config.Regression.RecentChecks == 0 {
+		config.Regression.RecentChecks = 5
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+
 	return &config, nil
 }
+
+// legacyProblems reports mistakes in settings that older versions loaded
+// without complaint. They are only warned about for now, so that existing
+// configs keep loading after an upgrade; Validate rejects the same mistakes in
+// settings that are new. Called on the config as read, before defaults.
+func (c *Config) legacyProblems() []string {
+	var problems []string
+	if c.CheckInterval < 0 {
+		problems = append(problems, "check_interval must not be negative, using 30s")
+	}
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port out of range: %d", c.Server.Port))
+	}
+	for _, ep := range c.Endpoints {
+		if !validExpectedStatus(ep.ExpectedStatus) {
+			problems = append(problems, fmt.Sprintf("endpoint %q: expected_status must be an HTTP status code or -1", ep.Name))
+		}
+		if err := validateMethod(ep.Method, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("endpoint %q: %v", ep.Name, err))
+		}
+		if ep.Type == "" && ep.URLs == nil {
+			if _, err := normalizeTarget(ep.Type, ep.URL); err != nil {
+				problems = append(problems, fmt.Sprintf("endpoint %q: url: %v", ep.Name, err))
+			}
+		}
+	}
+	if c.Alerting.SlackEnabled && len(c.Alerting.SlackTargets()) == 0 && !c.Alerting.slackBotEnabled() {
+		problems = append(problems, "alerting.slack_webhook, slack_webhooks or slack_bot_token is required when slack_enabled is true")
+	}
+	if c.Alerting.TeamsEnabled && c.Alerting.TeamsWebhook == "" {
+		problems = append(problems, "alerting.teams_webhook is required when teams_enabled is true")
+	}
+	if c.Alerting.EmailEnabled && c.Alerting.EmailConfig.SMTPHost == "" {
+		problems = append(problems, "alerting.email_config.smtp_host is required when email_enabled is true")
+	}
+	return problems
+}
+
+// Validate checks the configuration for values that would break monitoring or
+// alerting. Mistakes older versions accepted are left to legacyProblems.
+func (c *Config) Validate() error {
+	if c.DefaultTimeout < 0 {
+		return fmt.Errorf("default_timeout must not be negative")
+	}
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("max_body_bytes must not be negative")
+	}
+	if c.FailureSnapshotBytes < -1 {
+		return fmt.Errorf("failure_snapshot_bytes must be -1 (disabled) or a size in bytes")
+	}
+	if _, err := parseProxyURL(c.ProxyURL); err != nil {
+		return fmt.Errorf("proxy_url: %w", err)
+	}
+	if c.Server.RefreshInterval < 0 || (c.Server.RefreshInterval > 0 && c.Server.RefreshInterval < time.Second) {
+		return fmt.Errorf("server.refresh_interval must be at least 1s")
+	}
+	if c.Server.ChartPoints < 0 || c.Server.ChartPoints > 1000 {
+		return fmt.Errorf("server.chart_points must be between 1 and 1000")
+	}
+	if c.Server.LogMaxSizeMB < 0 || c.Server.LogMaxBackups < 0 {
+		return fmt.Errorf("server.log_max_size_mb and server.log_max_backups must not be negative")
+	}
+	if c.Server.AccentColor != "" && !accentColorPattern.MatchString(c.Server.AccentColor) {
+		return fmt.Errorf("server.accent_color must be a hex color such as #0f766e")
+	}
+	if c.Server.RateLimit < 0 || c.Server.RateLimitBurst < 0 {
+		return fmt.Errorf("server.rate_limit and server.rate_limit_burst must not be negative")
+	}
+	if c.Storage.MaxRecordsPerEndpoint < 0 {
+		return fmt.Errorf("storage.max_records_per_endpoint must not be negative")
+	}
+	for _, ep := range c.Endpoints {
+		if ep.FailureDuration < 0 {
+			return fmt.Errorf("endpoint %q: failure_duration must not be negative", ep.Name)
+		}
+		if ep.ConnectTimeout < 0 {
+			return fmt.Errorf("endpoint %q: connect_timeout must not be negative", ep.Name)
+		}
+		if ep.BackoffMax < 0 {
+			return fmt.Errorf("endpoint %q: backoff_max must not be negative", ep.Name)
+		}
+		if ep.ResponseTimeSLO < 0 {
+			return fmt.Errorf("endpoint %q: response_time_slo must not be negative", ep.Name)
+		}
+		if _, err := parseProxyURL(ep.ProxyURL); err != nil {
+			return fmt.Errorf("endpoint %q: proxy_url: %w", ep.Name, err)
+		}
+		if err := validateAlertEmails(ep.AlertEmails); err != nil {
+			return fmt.Errorf("endpoint %q: alert_emails: %w", ep.Name, err)
+		}
+		if !validAddressFamily(ep.AddressFamily) {
+			return fmt.Errorf("endpoint %q: address_family must be ip4, ip6 or empty", ep.Name)
+		}
+		if ep.Body != "" {
+			if err := validateMethod(ep.Method, ep.Body); err != nil {
+				return fmt.Errorf("endpoint %q: %w", ep.Name, err)
+			}
+		}
+		if ep.MaxRedirects < MaxRedirectsNone {
+			return fmt.Errorf("endpoint %q: max_redirects must be -1 (don't follow) or a number of redirects", ep.Name)
+		}
+		if err := validateBodySize(ep.MinBodyBytes, ep.MaxBodyBytes); err != nil {
+			return fmt.Errorf("endpoint %q: %w", ep.Name, err)
+		}
+		if ep.Type != "" || ep.URLs != nil {
+			if _, _, err := normalizeTargets(ep.Type, ep.URL, ep.URLs); err != nil {
+				return fmt.Errorf("endpoint %q: url: %w", ep.Name, err)
+			}
+		}
+		if err := validateResolveOverride(ep.ResolveOverride, ep.ProxyURL); err != nil {
+			return fmt.Errorf("endpoint %q: resolve_override: %w", ep.Name, err)
+		}
+		if err := validateExpectedIP(ep.ExpectedIP); err != nil {
+			return fmt.Errorf("endpoint %q: expected_ip: %w", ep.Name, err)
+		}
+	}
+	if c.Storage.BackupInterval < 0 || c.Storage.BackupKeep < 0 {
+		return fmt.Errorf("storage.backup_interval and storage.backup_keep must not be negative")
+	}
+	if c.Storage.BackupDir != "" && c.Storage.BackupInterval > 0 && c.Storage.BackupInterval < time.Minute {
+		return fmt.Errorf("storage.backup_interval must be at least 1m")
+	}
+	if c.Storage.CleanupInterval != 0 && c.Storage.CleanupInterval < time.Minute {
+		return fmt.Errorf("storage.cleanup_interval must be at least 1m")
+	}
+	if c.Alerting.RepeatInterval < 0 {
+		return fmt.Errorf("alerting.repeat_interval must not be negative")
+	}
+	if c.Alerting.FlapThreshold < 0 || c.Alerting.FlapWindow < 0 {
+		return fmt.Errorf("alerting.flap_threshold and alerting.flap_window must not be negative")
+	}
+	if r := c.Regression; r.Multiplier < 0 || (r.Multiplier > 0 && r.Multiplier <= 1) {
+		return fmt.Errorf("regression.multiplier must be greater than 1, or 0 to disable")
+	}
+	if c.Regression.BaselineWindow < 0 || c.Regression.RecentChecks < 0 {
+		return fmt.Errorf("regression.baseline_window and regression.recent_checks must not be negative")
+	}
+	if q := c.Alerting.QuietHours; q.Start != "" || q.End != "" {
+		if _, err := clockTime(q.Start); err != nil {
+			return fmt.Errorf("alerting.quiet_hours.start: %w", err)
+		}
+		if _, err := clockTime(q.End); err != nil {
+			return fmt.Errorf("alerting.quiet_hours.end: %w", err)
+		}
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("alerting.quiet_hours.timezone: %w", err)
+		}
+	}
+	switch c.Alerting.SummarySchedule {
+	case "", SummaryDaily, SummaryWeekly:
+	default:
+		return fmt.Errorf("alerting.summary_schedule must be %q or %q", SummaryDaily, SummaryWeekly)
+	}
+	if (c.Alerting.SlackBotToken == "") != (c.Alerting.SlackChannel == "") {
+		return fmt.Errorf("alerting.slack_bot_token and slack_channel must be set together")
+	}
+	if c.Alerting.SlackTemplate != "" {
+		if err := validateSlackTemplate(c.Alerting.SlackTemplate); err != nil {
+			return fmt.Errorf("alerting.slack_template: %w", err)
+		}
+	}
+	if c.Alerting.AlertmanagerURL != "" {
+		if _, err := url.ParseRequestURI(c.Alerting.AlertmanagerURL); err != nil {
+			return fmt.Errorf("alerting.alertmanager_url: %w", err)
+		}
+	}
+	if influx := c.Exporters.InfluxDB; influx.URL != "" {
+		if _, err := url.ParseRequestURI(influx.URL); err != nil {
+			return fmt.Errorf("exporters.influxdb.url: %w", err)
+		}
+		if influx.Bucket == "" && influx.Database == "" {
+			return fmt.Errorf("exporters.influxdb.bucket (v2) or database (v1) is required")
+		}
+		if influx.FlushInterval < 0 || influx.BatchSize < 0 {
+			return fmt.Errorf("exporters.influxdb.flush_interval and batch_size must not be negative")
+		}
+	}
+	if endpoint := c.Exporters.OTLP.Endpoint; endpoint != "" {
+		if _, err := url.ParseRequestURI(endpoint); err != nil {
+			return fmt.Errorf("exporters.otlp.endpoint: %w", err)
+		}
+	}
+	if addr := c.Exporters.StatsDAddress; addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("exporters.statsd_address: %w", err)
+		}
+	}
+	return nil
+}
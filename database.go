@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
@@ -22,26 +29,102 @@ const (
 
 // Database wraps BoltDB operations
 type Database struct {
-	db *bolt.DB
-	mu sync.RWMutex
+	db       *bolt.DB
+	config   *StorageConfig
+	mu       sync.RWMutex
+	cleanups cleanupTracker
+
+	// countMu guards the cached history record count DBStats reports
+	countMu          sync.Mutex
+	historyCount     int
+	historyCountedAt time.Time
+
+	// summaryMu guards the decoded history GetHistoryStats aggregates, nil
+	// until it is first needed
+	summaryMu sync.Mutex
+	summaries map[string][]checkSummary
+}
+
+// checkSummary is the part of a history record GetHistoryStats needs
+type checkSummary struct {
+	timestamp    int64 // Unix nanoseconds
+	responseTime time.Duration
+	healthy      bool
 }
 
 // StoredEndpoint represents an endpoint stored in the database
 type StoredEndpoint struct {
-	ID               string            `json:"id"`
-	Name             string            `json:"name"`
-	URL              string            `json:"url"`
-	Method           string            `json:"method"`
-	Timeout          time.Duration     `json:"timeout"`
-	CheckInterval    time.Duration     `json:"check_interval"`
-	ExpectedStatus   int               `json:"expected_status"`
-	Headers          map[string]string `json:"headers"`
-	FailureThreshold int               `json:"failure_threshold"`
-	SuccessThreshold int               `json:"success_threshold"`
-	Enabled          bool              `json:"enabled"`
-	AlertsSuppressed bool              `json:"alerts_suppressed"`
-	CreatedAt        time.Time         `json:"created_at"`
-	UpdatedAt        time.Time         `json:"updated_at"`
+	ID                  string            `json:"id"`
+	Name                string            `json:"name"`
+	Type                string            `json:"type,omitempty"`
+	URL                 string            `json:"url"`
+	URLs                []string          `json:"urls,omitempty"`
+	Method              string            `json:"method"`
+	Body                string            `json:"body,omitempty"`
+	Timeout             time.Duration     `json:"timeout"`
+	ConnectTimeout      time.Duration     `json:"connect_timeout,omitempty"`
+	CheckInterval       time.Duration     `json:"check_interval"`
+	ExpectedStatus      int               `json:"expected_status"`
+	Headers             map[string]string `json:"headers"`
+	ExpectedHeaders     map[string]string `json:"expected_headers,omitempty"`
+	ExpectedIP          string            `json:"expected_ip,omitempty"`
+	GraphQLQuery        string            `json:"graphql_query,omitempty"`
+	GraphQLDataPath     string            `json:"graphql_data_path,omitempty"`
+	JSONPath            string            `json:"json_path,omitempty"`
+	JSONPathExpected    string            `json:"json_path_expected,omitempty"`
+	Invert              bool              `json:"invert,omitempty"`
+	DependsOn           []string          `json:"depends_on,omitempty"`
+	Tags                []string          `json:"tags,omitempty"`
+	AlwaysAlert         bool              `json:"always_alert,omitempty"`
+	AlertOnFirstFailure bool              `json:"alert_on_first_failure,omitempty"`
+	SlackMention        string            `json:"slack_mention,omitempty"`
+	AlertEmails         []string          `json:"alert_emails,omitempty"`
+	UserAgent           string            `json:"user_agent,omitempty"`
+	ProxyURL            string            `json:"proxy_url,omitempty"`
+	AddressFamily       string            `json:"address_family,omitempty"`
+	ResolveOverride     string            `json:"resolve_override,omitempty"`
+	DisableKeepAlives   bool              `json:"disable_keep_alives,omitempty"`
+	MaxRedirects        int               `json:"max_redirects,omitempty"`
+	WebSocketPing       bool              `json:"websocket_ping,omitempty"`
+	MinBodyBytes        int64             `json:"assert_min_body_bytes,omitempty"`
+	MaxBodyBytes        int64             `json:"assert_max_body_bytes,omitempty"`
+	BodyMustNotContain  string            `json:"body_must_not_contain,omitempty"`
+	HeartbeatToken      string            `json:"heartbeat_token,omitempty"`
+	Backoff             bool              `json:"backoff,omitempty"`
+	BackoffMax          time.Duration     `json:"backoff_max,omitempty"`
+	FailureThreshold    int               `json:"failure_threshold"`
+	FailureDuration     time.Duration     `json:"failure_duration,omitempty"`
+	SuccessThreshold    int               `json:"success_threshold"`
+	ResponseTimeSLO     time.Duration     `json:"response_time_slo,omitempty"`
+	Enabled             bool              `json:"enabled"`
+	AlertsSuppressed    bool              `json:"alerts_suppressed"`
+	SnoozeUntil         time.Time         `json:"snooze_until,omitempty"`
+	Acknowledged        bool              `json:"acknowledged"`
+	AcknowledgedAt      time.Time         `json:"acknowledged_at,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+// UnmarshalJSON decodes a stored endpoint, also reading the body size
+// assertions from min_body_bytes and max_body_bytes, the keys they were saved
+// under before they were renamed.
+func (s *StoredEndpoint) UnmarshalJSON(data []byte) error {
+	type storedEndpoint StoredEndpoint
+	decoded := struct {
+		*storedEndpoint
+		OldMinBodyBytes int64 `json:"min_body_bytes"`
+		OldMaxBodyBytes int64 `json:"max_body_bytes"`
+	}{storedEndpoint: (*storedEndpoint)(s)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if s.MinBodyBytes == 0 {
+		s.MinBodyBytes = decoded.OldMinBodyBytes
+	}
+	if s.MaxBodyBytes == 0 {
+		s.MaxBodyBytes = decoded.OldMaxBodyBytes
+	}
+	return nil
 }
 
 // HealthCheckRecord represents a single health check result stored in history
@@ -52,11 +135,36 @@ type HealthCheckRecord struct {
 	ResponseTime time.Duration `json:"response_time"`
 	StatusCode   int           `json:"status_code"`
 	Error        string        `json:"error,omitempty"`
+	BodySnapshot string        `json:"body_snapshot,omitempty"`
+	URL          string        `json:"url,omitempty"`         // failover URL that answered, for multi-URL endpoints
+	RemoteAddr   string        `json:"remote_addr,omitempty"` // IP address an HTTP or ping check reached
+	Degraded     bool          `json:"degraded,omitempty"`    // passed, but much slower than the endpoint's baseline
+	SLOBreach    bool          `json:"slo_breach,omitempty"`  // took longer than the endpoint's response_time_slo
 }
 
+// errCorruptDatabase marks a database file that bbolt panicked on or that
+// failed its consistency check
+var errCorruptDatabase = errors.New("database file is corrupt")
+
 // NewDatabase creates and initializes a new BoltDB database
-func NewDatabase(path string) (*Database, error) {
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+func NewDatabase(path string, config *StorageConfig) (database *Database, err error) {
+	// bbolt panics instead of returning an error when a data or freelist page
+	// is damaged; report that as corruption so -recover can deal with it
+	defer func() {
+		if r := recover(); r != nil {
+			database, err = nil, fmt.Errorf("%w: %v", errCorruptDatabase, r)
+		}
+	}()
+
+	timeout := config.OpenTimeout
+	if timeout <= 0 {
+		timeout = DefaultDBOpenTimeout
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		// BoltDB takes an exclusive file lock, so only one process can open it
+		return nil, fmt.Errorf("database %s is locked by another process (is cronzee already running?); gave up after %v: %w", path, timeout, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -77,19 +185,274 @@ func NewDatabase(path string) (*Database, error) {
 		return nil, err
 	}
 
-	database := &Database{db: db}
+	// The meta pages are validated on open, but the rest of the file is
+	// only read when used
+	if config.VerifyOnOpen {
+		err = db.View(func(tx *bolt.Tx) error {
+			var first error
+			// Drain the channel so the check finishes before the tx closes
+			for err := range tx.Check() {
+				if first == nil {
+					first = err
+				}
+			}
+			return first
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%w: %v", errCorruptDatabase, err)
+		}
+	}
+
+	database = &Database{db: db, config: config}
+
+	if err := database.migrateLegacyIDs(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate endpoint IDs: %w", err)
+	}
 
 	// Start cleanup goroutine
-	go database.startCleanupRoutine()
+	go startCleanupRoutine(database, config)
 
 	return database, nil
 }
 
+// isCorruptDatabase reports whether err from NewDatabase means the file is
+// damaged, as opposed to locked, missing or unreadable
+func isCorruptDatabase(err error) bool {
+	return errors.Is(err, errCorruptDatabase) || errors.Is(err, bolt.ErrInvalid) || errors.Is(err, bolt.ErrChecksum) || errors.Is(err, bolt.ErrVersionMismatch)
+}
+
+// recoverCorruptDatabase moves the corrupt database at path aside to
+// <path>.corrupt-<timestamp>, where it can be inspected or repaired, and opens
+// an empty database in its place. Endpoints and history are lost, but the
+// monitor comes up instead of refusing to start.
+func recoverCorruptDatabase(path string, config *StorageConfig, cause error) (*Database, error) {
+	saved := path + ".corrupt-" + time.Now().Format("20060102-150405")
+	if err := os.Rename(path, saved); err != nil {
+		return nil, fmt.Errorf("failed to move corrupt database aside: %w", err)
+	}
+	log.Printf("WARNING: database %s is corrupt (%v); moved it to %s and starting with an EMPTY database", path, cause, saved)
+	return NewDatabase(path, config)
+}
+
 // Close closes the database
 func (d *Database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	return d.db.Close()
 }
 
+// Backup writes a consistent snapshot of the whole database file to w. The
+// snapshot is copied to a temporary file first and streamed from there: an
+// open read transaction holds up writes that need to grow the file, so it
+// must not last as long as a slow download.
+func (d *Database) Backup(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "cronzee-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "backup.db")
+	d.mu.RLock()
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0600)
+	})
+	d.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// compactTxMaxSize bounds how much Compact copies per write transaction
+const compactTxMaxSize = 64 << 20
+
+// Compact rewrites the database into a new file without the free pages left
+// by deleted records and swaps it in place of the old one. All other database
+// operations wait until it finishes. It returns the file size before and after.
+func (d *Database) Compact() (before, after int64, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.db.Path()
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	before = info.Size()
+
+	tmpPath := path + ".compact"
+	os.Remove(tmpPath)
+	dst, err := bolt.Open(tmpPath, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create compacted database: %w", err)
+	}
+	if err := bolt.Compact(dst, d.db, compactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to compact database: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to write compacted database: %w", err)
+	}
+
+	// Swap the files while the old database is closed. The original is kept
+	// until the compacted file has opened, so every failure falls back to it
+	// and d.db is never left closed.
+	if err := d.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, d.reopen(path, fmt.Errorf("failed to close database: %w", err))
+	}
+	oldPath := path + ".precompact"
+	if err := os.Rename(path, oldPath); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, d.reopen(path, fmt.Errorf("failed to replace database file: %w", err))
+	}
+	fallBack := func(cause error) error {
+		os.Remove(tmpPath)
+		if err := os.Rename(oldPath, path); err != nil {
+			return fmt.Errorf("%w; restoring the original database from %s also failed: %v", cause, oldPath, err)
+		}
+		return d.reopen(path, cause)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, 0, fallBack(fmt.Errorf("failed to replace database file: %w", err))
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: DefaultDBOpenTimeout})
+	if err != nil {
+		return 0, 0, fallBack(fmt.Errorf("failed to open compacted database: %w", err))
+	}
+	d.db = db
+	os.Remove(oldPath)
+
+	info, err = os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, info.Size(), nil
+}
+
+// reopen opens path as d.db again after a failed compaction and returns
+// cause, noting if the reopen failed as well
+func (d *Database) reopen(path string, cause error) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: DefaultDBOpenTimeout})
+	if err != nil {
+		return fmt.Errorf("%w; reopening the database also failed: %v", cause, err)
+	}
+	d.db = db
+	return cause
+}
+
+// BoltStats is the subset of BoltDB's runtime statistics worth watching
+type BoltStats struct {
+	FreePages          int   `json:"free_pages"`
+	PendingPages       int   `json:"pending_pages"`
+	FreeAllocBytes     int   `json:"free_alloc_bytes"`
+	FreelistInuseBytes int   `json:"freelist_inuse_bytes"`
+	OpenTxs            int   `json:"open_txs"`
+	TotalTxs           int   `json:"total_txs"`
+	PagesAllocated     int64 `json:"pages_allocated"`
+}
+
+// DBStats reports record counts, the file size and BoltDB's statistics
+func (d *Database) DBStats() (*DBStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	s := d.db.Stats()
+	stats := &DBStats{
+		Driver: "bolt",
+		Bolt: &BoltStats{
+			FreePages:          s.FreePageN,
+			PendingPages:       s.PendingPageN,
+			FreeAllocBytes:     s.FreeAlloc,
+			FreelistInuseBytes: s.FreelistInuse,
+			OpenTxs:            s.OpenTxN,
+			TotalTxs:           s.TxN,
+			PagesAllocated:     s.TxStats.GetPageCount(),
+		},
+	}
+	info, err := os.Stat(d.db.Path())
+	if err != nil {
+		return nil, err
+	}
+	stats.SizeBytes = info.Size()
+
+	err = d.db.View(func(tx *bolt.Tx) error {
+		stats.Endpoints = tx.Bucket([]byte(EndpointsBucket)).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stats.HistoryRecords, err = d.historyRecords(); err != nil {
+		return nil, err
+	}
+	stats.LastCleanup = d.cleanups.stats()
+	return stats, nil
+}
+
+// historyCountMaxAge is how long DBStats trusts its count of history records.
+// Counting walks every page of the history bucket, so it isn't done on each
+// dashboard refresh; saves add to the count in between, and cleanup and
+// clearing history make the next call recount.
+const historyCountMaxAge = 10 * time.Minute
+
+// historyRecords returns the number of history records, recounting them at
+// most every historyCountMaxAge. Caller must hold d.mu.
+func (d *Database) historyRecords() (int, error) {
+	d.countMu.Lock()
+	defer d.countMu.Unlock()
+
+	if !d.historyCountedAt.IsZero() && time.Since(d.historyCountedAt) < historyCountMaxAge {
+		return d.historyCount, nil
+	}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		d.historyCount = tx.Bucket([]byte(HistoryBucket)).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	d.historyCountedAt = time.Now()
+	return d.historyCount, nil
+}
+
+// addHistoryCount adjusts the cached history record count by n saved records
+func (d *Database) addHistoryCount(n int) {
+	d.countMu.Lock()
+	d.historyCount += n
+	d.countMu.Unlock()
+}
+
+// recountHistory makes the next DBStats count the history records afresh
+func (d *Database) recountHistory() {
+	d.countMu.Lock()
+	d.historyCountedAt = time.Time{}
+	d.countMu.Unlock()
+}
+
+// Ping verifies the database is readable with a cheap read transaction
+func (d *Database) Ping() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(EndpointsBucket)) == nil {
+			return fmt.Errorf("endpoints bucket missing")
+		}
+		return nil
+	})
+}
+
 // SaveEndpoint saves or updates an endpoint
 func (d *Database) SaveEndpoint(endpoint *StoredEndpoint) error {
 	d.mu.Lock()
@@ -98,32 +461,7 @@ func (d *Database) SaveEndpoint(endpoint *StoredEndpoint) error {
 	return d.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(EndpointsBucket))
 
-		// Set timestamps
-		now := time.Now()
-		if endpoint.CreatedAt.IsZero() {
-			endpoint.CreatedAt = now
-		}
-		endpoint.UpdatedAt = now
-
-		// Set defaults
-		if endpoint.Method == "" {
-			endpoint.Method = "GET"
-		}
-		if endpoint.Timeout == 0 {
-			endpoint.Timeout = 10 * time.Second
-		}
-		if endpoint.ExpectedStatus == 0 {
-			endpoint.ExpectedStatus = 200
-		}
-		if endpoint.FailureThreshold == 0 {
-			endpoint.FailureThreshold = 3
-		}
-		if endpoint.SuccessThreshold == 0 {
-			endpoint.SuccessThreshold = 2
-		}
-		if endpoint.CheckInterval == 0 {
-			endpoint.CheckInterval = 30 * time.Second
-		}
+		endpoint.applyDefaults()
 
 		data, err := json.Marshal(endpoint)
 		if err != nil {
@@ -177,6 +515,20 @@ func (d *Database) GetAllEndpoints() ([]*StoredEndpoint, error) {
 	return endpoints, nil
 }
 
+// FindEndpointByURL returns the endpoint monitoring url, or nil if there is none
+func (d *Database) FindEndpointByURL(url string) (*StoredEndpoint, error) {
+	all, err := d.GetAllEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range all {
+		if ep.URL == url {
+			return ep, nil
+		}
+	}
+	return nil, nil
+}
+
 // GetEnabledEndpoints retrieves only enabled endpoints
 func (d *Database) GetEnabledEndpoints() ([]*StoredEndpoint, error) {
 	all, err := d.GetAllEndpoints()
@@ -244,40 +596,227 @@ func (d *Database) UnsuppressAlerts(id string) error {
 	return d.SaveEndpoint(endpoint)
 }
 
+// SnoozeAlerts silences alerts for an endpoint until the given time; a zero
+// time ends the snooze
+func (d *Database) SnoozeAlerts(id string, until time.Time) error {
+	endpoint, err := d.GetEndpoint(id)
+	if err != nil {
+		return err
+	}
+	endpoint.SnoozeUntil = until
+	return d.SaveEndpoint(endpoint)
+}
+
+// AcknowledgeIncident marks the current incident for an endpoint as acknowledged
+func (d *Database) AcknowledgeIncident(id string, at time.Time) error {
+	endpoint, err := d.GetEndpoint(id)
+	if err != nil {
+		return err
+	}
+	endpoint.Acknowledged = true
+	endpoint.AcknowledgedAt = at
+	return d.SaveEndpoint(endpoint)
+}
+
+// ClearAcknowledgement clears the incident acknowledgement for an endpoint
+func (d *Database) ClearAcknowledgement(id string) error {
+	endpoint, err := d.GetEndpoint(id)
+	if err != nil {
+		return err
+	}
+	endpoint.Acknowledged = false
+	endpoint.AcknowledgedAt = time.Time{}
+	return d.SaveEndpoint(endpoint)
+}
+
 // SaveHealthCheckRecord saves a health check result to history
 func (d *Database) SaveHealthCheckRecord(record *HealthCheckRecord) error {
+	return d.SaveHealthCheckRecords([]*HealthCheckRecord{record})
+}
+
+// SaveHealthCheckRecords saves several health check results in one transaction
+func (d *Database) SaveHealthCheckRecords(records []*HealthCheckRecord) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	return d.db.Update(func(tx *bolt.Tx) error {
+	err := d.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(HistoryBucket))
 
-		// Create a unique key using endpoint ID and timestamp
-		key := fmt.Sprintf("%s:%d", record.EndpointID, record.Timestamp.UnixNano())
+		for _, record := range records {
+			// Create a unique key using endpoint ID and timestamp
+			key := fmt.Sprintf("%s:%d", record.EndpointID, record.Timestamp.UnixNano())
 
-		data, err := json.Marshal(record)
-		if err != nil {
-			return fmt.Errorf("failed to marshal health check record: %w", err)
-		}
+			data, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to marshal health check record: %w", err)
+			}
 
-		return b.Put([]byte(key), data)
+			if err := b.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	if err == nil {
+		d.addHistoryCount(len(records))
+		d.addSummaries(records)
+	}
+	return err
+}
+
+// HistoryQuery filters and paginates a health history lookup
+type HistoryQuery struct {
+	Limit  int       // maximum records to return, 0 for no limit
+	Offset int       // number of newest matching records to skip
+	From   time.Time // inclusive lower bound, zero for unbounded
+	To     time.Time // inclusive upper bound, zero for unbounded
+	Status string    // only records with this status, "degraded" or "slo_breach"; empty for all
 }
 
-// GetHealthHistory retrieves health check history for an endpoint
-func (d *Database) GetHealthHistory(endpointID string, limit int) ([]*HealthCheckRecord, error) {
+// HistoryStatusDegraded selects the passed checks that were flagged as slow,
+// and HistoryStatusSLOBreach the checks slower than the endpoint's
+// response_time_slo, when used as HistoryQuery.Status
+const (
+	HistoryStatusDegraded  = "degraded"
+	HistoryStatusSLOBreach = "slo_breach"
+)
+
+// validHistoryStatus reports whether status can be used to filter history
+func validHistoryStatus(status string) bool {
+	switch HealthStatus(status) {
+	case StatusHealthy, StatusUnhealthy, StatusUnknown, HistoryStatusDegraded, HistoryStatusSLOBreach:
+		return true
+	}
+	return false
+}
+
+// matches reports whether record passes the query's status filter. The time
+// range is applied by the stores' scans.
+func (q HistoryQuery) matches(record *HealthCheckRecord) bool {
+	switch q.Status {
+	case "":
+		return true
+	case HistoryStatusDegraded:
+		return record.Degraded
+	case HistoryStatusSLOBreach:
+		return record.SLOBreach
+	}
+	return record.Status == q.Status
+}
+
+// GetHealthHistory retrieves health check history for an endpoint, newest first.
+// It also returns the total number of records matching the time range so callers can paginate.
+func (d *Database) GetHealthHistory(endpointID string, query HistoryQuery) ([]*HealthCheckRecord, int, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var records []*HealthCheckRecord
+	total := 0
 	prefix := []byte(endpointID + ":")
 
+	// Keys sort by timestamp within an endpoint, so walk backwards from the upper bound
+	upper := []byte(endpointID + ";")
+	if !query.To.IsZero() {
+		upper = []byte(fmt.Sprintf("%s:%d", endpointID, query.To.UnixNano()+1))
+	}
+
 	err := d.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(HistoryBucket))
 		c := b.Cursor()
 
-		// Collect all matching records
-		for k, v := c.Seek(prefix); k != nil && len(k) >= len(prefix) && string(k[:len(prefix)]) == string(prefix); k, v = c.Next() {
+		k, v := c.Seek(upper)
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			if !query.From.IsZero() {
+				nanos, err := strconv.ParseInt(string(k[len(prefix):]), 10, 64)
+				if err == nil && nanos < query.From.UnixNano() {
+					break
+				}
+			}
+
+			// Only decode records that are skipped anyway when filtering by status
+			var record *HealthCheckRecord
+			if query.Status != "" {
+				if err := json.Unmarshal(v, &record); err != nil || !query.matches(record) {
+					continue
+				}
+			}
+
+			total++
+			if total <= query.Offset || (query.Limit > 0 && len(records) >= query.Limit) {
+				continue
+			}
+
+			if record == nil {
+				if err := json.Unmarshal(v, &record); err != nil {
+					continue
+				}
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// StreamHealthHistory calls fn for each of an endpoint's history records
+// between from and to (zero for unbounded), oldest first, stopping at the
+// first error fn returns. Records are read in chunks and fn is called
+// without the database lock held.
+func (d *Database) StreamHealthHistory(endpointID string, from, to time.Time, fn func(*HealthCheckRecord) error) error {
+	prefix := []byte(endpointID + ":")
+	start := prefix
+	if !from.IsZero() {
+		start = []byte(fmt.Sprintf("%s:%d", endpointID, from.UnixNano()))
+	}
+
+	for start != nil {
+		records, next, err := d.historyChunk(prefix, start, to)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		start = next
+	}
+	return nil
+}
+
+// historyChunk reads up to historyStreamChunk records under prefix starting
+// at key start, and returns the key to continue from, or nil at the end
+func (d *Database) historyChunk(prefix, start []byte, to time.Time) ([]*HealthCheckRecord, []byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var records []*HealthCheckRecord
+	var next []byte
+	err := d.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(HistoryBucket)).Cursor()
+		for k, v := c.Seek(start); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if !to.IsZero() {
+				nanos, err := strconv.ParseInt(string(k[len(prefix):]), 10, 64)
+				if err == nil && nanos > to.UnixNano() {
+					return nil
+				}
+			}
+			if len(records) == historyStreamChunk {
+				// Keys are only valid inside the transaction
+				next = append([]byte(nil), k...)
+				return nil
+			}
+
 			var record HealthCheckRecord
 			if err := json.Unmarshal(v, &record); err != nil {
 				continue
@@ -287,28 +826,137 @@ func (d *Database) GetHealthHistory(endpointID string, limit int) ([]*HealthChec
 		return nil
 	})
 	if err != nil {
+		return nil, nil, err
+	}
+	return records, next, nil
+}
+
+// HistoryStats summarizes stored health check history across all endpoints
+type HistoryStats struct {
+	TotalChecks       int
+	WindowChecks      int
+	WindowHealthy     int
+	WindowRespTotal   time.Duration
+	WindowRespSamples int
+	// WindowByEndpoint counts the window's checks per endpoint ID
+	WindowByEndpoint map[string]*WindowCounts
+}
+
+// WindowCounts counts one endpoint's checks in a HistoryStats window
+type WindowCounts struct {
+	Checks  int
+	Healthy int
+}
+
+// addWindowCheck counts a check from the window
+func (s *HistoryStats) addWindowCheck(endpointID string, healthy bool, responseTime time.Duration) {
+	counts := s.WindowByEndpoint[endpointID]
+	if counts == nil {
+		counts = &WindowCounts{}
+		s.WindowByEndpoint[endpointID] = counts
+	}
+	s.WindowChecks++
+	counts.Checks++
+	if healthy {
+		s.WindowHealthy++
+		counts.Healthy++
+	}
+	if responseTime > 0 {
+		s.WindowRespTotal += responseTime
+		s.WindowRespSamples++
+	}
+}
+
+// GetHistoryStats aggregates all history records, counting those at or after since in the window
+func (d *Database) GetHistoryStats(since time.Time) (*HistoryStats, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	d.summaryMu.Lock()
+	defer d.summaryMu.Unlock()
+
+	if err := d.loadSummaries(); err != nil {
 		return nil, err
 	}
+	stats := &HistoryStats{WindowByEndpoint: make(map[string]*WindowCounts)}
+	cutoff := since.UnixNano()
+	for endpointID, checks := range d.summaries {
+		stats.TotalChecks += len(checks)
+		for _, check := range checks {
+			if check.timestamp >= cutoff {
+				stats.addWindowCheck(endpointID, check.healthy, check.responseTime)
+			}
+		}
+	}
+	return stats, nil
+}
 
-	// Sort by timestamp descending and limit
-	// Records are already sorted by key (timestamp), so reverse for descending
-	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
-		records[i], records[j] = records[j], records[i]
+// loadSummaries decodes the history bucket into d.summaries unless it already
+// holds them. Decoding every record is what makes the stats expensive, so it
+// is done once; saves then add to the summaries and deleting history drops
+// them. Caller must hold d.mu and d.summaryMu.
+func (d *Database) loadSummaries() error {
+	if d.summaries != nil {
+		return nil
 	}
+	summaries := make(map[string][]checkSummary)
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(HistoryBucket)).ForEach(func(k, v []byte) error {
+			var record struct {
+				EndpointID   string        `json:"endpoint_id"`
+				Timestamp    time.Time     `json:"timestamp"`
+				Status       string        `json:"status"`
+				ResponseTime time.Duration `json:"response_time"`
+			}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return nil
+			}
+			summaries[record.EndpointID] = append(summaries[record.EndpointID], checkSummary{
+				timestamp:    record.Timestamp.UnixNano(),
+				responseTime: record.ResponseTime,
+				healthy:      record.Status == string(StatusHealthy),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	d.summaries = summaries
+	return nil
+}
+
+// addSummaries adds saved records to the decoded history, if it is loaded
+func (d *Database) addSummaries(records []*HealthCheckRecord) {
+	d.summaryMu.Lock()
+	defer d.summaryMu.Unlock()
 
-	if limit > 0 && len(records) > limit {
-		records = records[:limit]
+	if d.summaries == nil {
+		return
 	}
+	for _, record := range records {
+		d.summaries[record.EndpointID] = append(d.summaries[record.EndpointID], checkSummary{
+			timestamp:    record.Timestamp.UnixNano(),
+			responseTime: record.ResponseTime,
+			healthy:      record.Status == string(StatusHealthy),
+		})
+	}
+}
 
-	return records, nil
+// dropSummaries makes the next GetHistoryStats decode the history afresh,
+// after records were deleted
+func (d *Database) dropSummaries() {
+	d.summaryMu.Lock()
+	d.summaries = nil
+	d.summaryMu.Unlock()
 }
 
-// CleanupOldData removes data older than retention period
-func (d *Database) CleanupOldData() error {
+// ClearHistory deletes all health check records for an endpoint
+func (d *Database) ClearHistory(endpointID string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	cutoff := time.Now().AddDate(0, 0, -DataRetentionDays)
+	prefix := []byte(endpointID + ":")
 	deletedCount := 0
 
 	err := d.db.Update(func(tx *bolt.Tx) error {
@@ -316,14 +964,115 @@ func (d *Database) CleanupOldData() error {
 		c := b.Cursor()
 
 		var keysToDelete [][]byte
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keysToDelete = append(keysToDelete, k)
+		}
+
+		for _, key := range keysToDelete {
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+			deletedCount++
+		}
+		return nil
+	})
+
+	if err == nil {
+		d.recountHistory()
+		d.dropSummaries()
+		log.Printf("Cleared %d health check records for endpoint: %s", deletedCount, endpointID)
+	}
+	return err
+}
+
+// GetSetting returns the value stored under key in the settings bucket, or nil if unset
+func (d *Database) GetSetting(key string) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var value []byte
+	err := d.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket([]byte(SettingsBucket)).Get([]byte(key)); v != nil {
+			value = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return value, err
+}
+
+// SaveSetting stores value under key in the settings bucket
+func (d *Database) SaveSetting(key string, value []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(SettingsBucket)).Put([]byte(key), value)
+	})
+}
+
+// CleanupOldData removes data older than retention period and returns how
+// many records it deleted
+func (d *Database) CleanupOldData() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cutoff := time.Now().AddDate(0, 0, -DataRetentionDays)
+	deletedCount, skipped := 0, 0
+
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(HistoryBucket))
+		c := b.Cursor()
+
+		var keysToDelete [][]byte
+		expired := make(map[string]int)
+		retained := make(map[string]int)
 
 		for k, v := c.First(); k != nil; k, v = c.Next() {
 			var record HealthCheckRecord
 			if err := json.Unmarshal(v, &record); err != nil {
+				skipped++
 				continue
 			}
 			if record.Timestamp.Before(cutoff) {
 				keysToDelete = append(keysToDelete, k)
+				expired[historyKeyEndpoint(k)]++
+			} else {
+				retained[historyKeyEndpoint(k)]++
+			}
+		}
+
+		// Enforce the per-endpoint cap on whatever survives the time cutoff.
+		// Keys sort oldest first within an endpoint, so the expired records come
+		// first and the next excess records are the oldest retained ones.
+		if limit := d.config.MaxRecordsPerEndpoint; limit > 0 {
+			seen := make(map[string]int)
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				endpointID := historyKeyEndpoint(k)
+				if retained[endpointID] <= limit {
+					continue
+				}
+				seen[endpointID]++
+				if seen[endpointID] <= expired[endpointID] {
+					continue
+				}
+				if seen[endpointID]-expired[endpointID] <= retained[endpointID]-limit {
+					keysToDelete = append(keysToDelete, k)
+				}
+			}
+		}
+
+		// Archive first; if that fails the transaction rolls back and
+		// nothing is lost
+		if dir := d.config.ArchiveDir; dir != "" {
+			records := make([]*HealthCheckRecord, 0, len(keysToDelete))
+			for _, key := range keysToDelete {
+				var record HealthCheckRecord
+				if err := json.Unmarshal(b.Get(key), &record); err == nil {
+					records = append(records, &record)
+				}
+			}
+			if err := archiveRecords(dir, records); err != nil {
+				return err
 			}
 		}
 
@@ -338,49 +1087,82 @@ func (d *Database) CleanupOldData() error {
 	})
 
 	if err == nil && deletedCount > 0 {
-		log.Printf("Cleaned up %d old health check records (older than %d days)", deletedCount, DataRetentionDays)
+		log.Printf("Cleaned up %d old health check records (older than %d days or over per-endpoint cap)", deletedCount, DataRetentionDays)
 	}
-
-	return err
-}
-
-// startCleanupRoutine runs periodic cleanup of old data
-func (d *Database) startCleanupRoutine() {
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	// Run initial cleanup
-	if err := d.CleanupOldData(); err != nil {
-		log.Printf("Error during initial cleanup: %v", err)
+	if err != nil {
+		// The transaction rolled back, so nothing was deleted
+		deletedCount = 0
 	}
+	d.recountHistory()
+	d.dropSummaries()
+	d.cleanups.record(deletedCount, skipped, err)
+	return deletedCount, err
+}
 
-	for range ticker.C {
-		if err := d.CleanupOldData(); err != nil {
-			log.Printf("Error during cleanup: %v", err)
-		}
+// historyKeyEndpoint extracts the endpoint ID from a history key of the form "<id>:<nanos>"
+func historyKeyEndpoint(key []byte) string {
+	if i := bytes.LastIndexByte(key, ':'); i >= 0 {
+		return string(key[:i])
 	}
+	return string(key)
 }
 
 // MigrateFromConfig imports endpoints from config file to database
 func (d *Database) MigrateFromConfig(endpoints []Endpoint) error {
+	existing, err := d.GetAllEndpoints()
+	if err != nil {
+		return fmt.Errorf("failed to read endpoints: %w", err)
+	}
+
 	for _, ep := range endpoints {
 		stored := &StoredEndpoint{
-			ID:               generateIDWithURL(ep.Name, ep.URL),
-			Name:             ep.Name,
-			URL:              ep.URL,
-			Method:           ep.Method,
-			Timeout:          ep.Timeout,
-			ExpectedStatus:   ep.ExpectedStatus,
-			Headers:          ep.Headers,
-			FailureThreshold: ep.FailureThreshold,
-			SuccessThreshold: ep.SuccessThreshold,
-			Enabled:          true,
-			AlertsSuppressed: false,
+			ID:                  newEndpointID(),
+			Name:                ep.Name,
+			Type:                ep.Type,
+			URL:                 ep.URL,
+			URLs:                ep.URLs,
+			Method:              ep.Method,
+			Body:                ep.Body,
+			Timeout:             ep.Timeout,
+			ConnectTimeout:      ep.ConnectTimeout,
+			ExpectedStatus:      ep.ExpectedStatus,
+			Headers:             ep.Headers,
+			ExpectedHeaders:     ep.ExpectedHeaders,
+			ExpectedIP:          ep.ExpectedIP,
+			GraphQLQuery:        ep.GraphQLQuery,
+			GraphQLDataPath:     ep.GraphQLDataPath,
+			JSONPath:            ep.JSONPath,
+			JSONPathExpected:    ep.JSONPathExpected,
+			Invert:              ep.Invert,
+			DependsOn:           ep.DependsOn,
+			Tags:                ep.Tags,
+			AlwaysAlert:         ep.AlwaysAlert,
+			AlertOnFirstFailure: ep.AlertOnFirstFailure,
+			SlackMention:        ep.SlackMention,
+			AlertEmails:         ep.AlertEmails,
+			UserAgent:           ep.UserAgent,
+			ProxyURL:            ep.ProxyURL,
+			AddressFamily:       ep.AddressFamily,
+			ResolveOverride:     ep.ResolveOverride,
+			DisableKeepAlives:   ep.DisableKeepAlives,
+			MaxRedirects:        ep.MaxRedirects,
+			WebSocketPing:       ep.WebSocketPing,
+			MinBodyBytes:        ep.MinBodyBytes,
+			MaxBodyBytes:        ep.MaxBodyBytes,
+			BodyMustNotContain:  ep.BodyMustNotContain,
+			HeartbeatToken:      ep.HeartbeatToken,
+			Backoff:             ep.Backoff,
+			BackoffMax:          ep.BackoffMax,
+			FailureThreshold:    ep.FailureThreshold,
+			FailureDuration:     ep.FailureDuration,
+			SuccessThreshold:    ep.SuccessThreshold,
+			ResponseTimeSLO:     ep.ResponseTimeSLO,
+			Enabled:             true,
+			AlertsSuppressed:    false,
 		}
 
 		// Check if endpoint already exists
-		existing, err := d.GetEndpoint(stored.ID)
-		if err == nil && existing != nil {
+		if hasTarget(existing, ep.URL, ep.AddressFamily) {
 			// Keep existing settings
 			continue
 		}
@@ -388,67 +1170,187 @@ func (d *Database) MigrateFromConfig(endpoints []Endpoint) error {
 		if err := d.SaveEndpoint(stored); err != nil {
 			return fmt.Errorf("failed to migrate endpoint %s: %w", ep.Name, err)
 		}
+		existing = append(existing, stored)
 		log.Printf("Migrated endpoint from config: %s", ep.Name)
 	}
 	return nil
 }
 
-// generateID creates a URL-safe ID from name and URL combination
-// This ensures that endpoints with the same name but different URLs have different IDs
-func generateID(name string) string {
-	id := ""
-	for _, c := range name {
-		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
-			id += string(c)
-		} else if c == ' ' || c == '-' || c == '_' {
-			id += "-"
+// hasTarget reports whether one of endpoints already checks url over the given
+// address family, comparing URLs by targetKey. A URL may be monitored once per
+// family, so v4 and v6 reachability of a host can be tracked separately.
+func hasTarget(endpoints []*StoredEndpoint, url, family string) bool {
+	key := targetKey(url)
+	for _, ep := range endpoints {
+		if targetKey(ep.URL) == key && ep.AddressFamily == family {
+			return true
 		}
 	}
-	return id
+	return false
 }
 
-// generateIDWithURL creates a URL-safe ID from name and URL combination
-func generateIDWithURL(name, url string) string {
-	combined := name + "-" + url
-	id := ""
-	for _, c := range combined {
-		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
-			id += string(c)
-		} else if c == ' ' || c == '-' || c == '_' || c == '/' || c == ':' || c == '.' {
-			id += "-"
-		}
+// newEndpointID generates a random UUIDv4 used as a stable endpoint ID
+func newEndpointID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand should never fail; fall back to a time-based ID
+		return fmt.Sprintf("ep-%d", time.Now().UnixNano())
 	}
-	// Trim multiple dashes and trailing dashes
-	result := ""
-	prevDash := false
-	for _, c := range id {
-		if c == '-' {
-			if !prevDash {
-				result += string(c)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// newHeartbeatToken generates the secret a heartbeat endpoint's job sends
+// with each ping
+func newHeartbeatToken() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return fmt.Sprintf("%x", b)
+}
+
+// isGeneratedID reports whether id has the UUID shape produced by newEndpointID
+func isGeneratedID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, c := range id {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f')) {
+				return false
 			}
-			prevDash = true
-		} else {
-			result += string(c)
-			prevDash = false
 		}
 	}
-	// Trim trailing dash
-	for len(result) > 0 && result[len(result)-1] == '-' {
-		result = result[:len(result)-1]
-	}
-	return result
+	return true
+}
+
+// legacyIDSetting prefixes the settings key that maps an endpoint's old
+// name/URL-derived ID to its random one
+const legacyIDSetting = "legacy_id:"
+
+// migrateLegacyIDs reassigns endpoints still keyed by the old name/URL-derived IDs
+// to random IDs, moving their history along with them. The old-to-new mapping is
+// kept in the settings bucket under "legacy_id:<old>", so the API still accepts
+// the old IDs.
+func (d *Database) migrateLegacyIDs() error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		endpoints := tx.Bucket([]byte(EndpointsBucket))
+		history := tx.Bucket([]byte(HistoryBucket))
+		settings := tx.Bucket([]byte(SettingsBucket))
+
+		var legacy []*StoredEndpoint
+		err := endpoints.ForEach(func(k, v []byte) error {
+			if isGeneratedID(string(k)) {
+				return nil
+			}
+			var endpoint StoredEndpoint
+			if err := json.Unmarshal(v, &endpoint); err != nil {
+				return err
+			}
+			endpoint.ID = string(k)
+			legacy = append(legacy, &endpoint)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, endpoint := range legacy {
+			oldID := endpoint.ID
+			endpoint.ID = newEndpointID()
+
+			data, err := json.Marshal(endpoint)
+			if err != nil {
+				return fmt.Errorf("failed to marshal endpoint: %w", err)
+			}
+			if err := endpoints.Put([]byte(endpoint.ID), data); err != nil {
+				return err
+			}
+			if err := endpoints.Delete([]byte(oldID)); err != nil {
+				return err
+			}
+
+			// Copy history under the new prefix before deleting the old keys
+			prefix := []byte(oldID + ":")
+			var keys, values [][]byte
+			c := history.Cursor()
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				keys = append(keys, append([]byte(nil), k...))
+				values = append(values, append([]byte(nil), v...))
+			}
+			for i, key := range keys {
+				var record HealthCheckRecord
+				if err := json.Unmarshal(values[i], &record); err == nil {
+					record.EndpointID = endpoint.ID
+					if data, err := json.Marshal(&record); err == nil {
+						values[i] = data
+					}
+				}
+				newKey := []byte(endpoint.ID + string(key[len(oldID):]))
+				if err := history.Put(newKey, values[i]); err != nil {
+					return err
+				}
+				if err := history.Delete(key); err != nil {
+					return err
+				}
+			}
+
+			if err := settings.Put([]byte(legacyIDSetting+oldID), []byte(endpoint.ID)); err != nil {
+				return err
+			}
+			log.Printf("Migrated endpoint ID %s -> %s (%d history records)", oldID, endpoint.ID, len(keys))
+		}
+		return nil
+	})
 }
 
 // ToEndpoint converts StoredEndpoint to Endpoint for monitoring
 func (s *StoredEndpoint) ToEndpoint() Endpoint {
 	return Endpoint{
-		Name:             s.Name,
-		URL:              s.URL,
-		Method:           s.Method,
-		Timeout:          s.Timeout,
-		ExpectedStatus:   s.ExpectedStatus,
-		Headers:          s.Headers,
-		FailureThreshold: s.FailureThreshold,
-		SuccessThreshold: s.SuccessThreshold,
+		Name:                s.Name,
+		Type:                s.Type,
+		URL:                 s.URL,
+		URLs:                s.URLs,
+		Method:              s.Method,
+		Body:                s.Body,
+		Timeout:             s.Timeout,
+		ConnectTimeout:      s.ConnectTimeout,
+		ExpectedStatus:      s.ExpectedStatus,
+		Headers:             s.Headers,
+		ExpectedHeaders:     s.ExpectedHeaders,
+		ExpectedIP:          s.ExpectedIP,
+		GraphQLQuery:        s.GraphQLQuery,
+		GraphQLDataPath:     s.GraphQLDataPath,
+		JSONPath:            s.JSONPath,
+		JSONPathExpected:    s.JSONPathExpected,
+		Invert:              s.Invert,
+		DependsOn:           s.DependsOn,
+		Tags:                s.Tags,
+		AlwaysAlert:         s.AlwaysAlert,
+		AlertOnFirstFailure: s.AlertOnFirstFailure,
+		SlackMention:        s.SlackMention,
+		AlertEmails:         s.AlertEmails,
+		UserAgent:           s.UserAgent,
+		ProxyURL:            s.ProxyURL,
+		AddressFamily:       s.AddressFamily,
+		ResolveOverride:     s.ResolveOverride,
+		DisableKeepAlives:   s.DisableKeepAlives,
+		MaxRedirects:        s.MaxRedirects,
+		WebSocketPing:       s.WebSocketPing,
+		MinBodyBytes:        s.MinBodyBytes,
+		MaxBodyBytes:        s.MaxBodyBytes,
+		BodyMustNotContain:  s.BodyMustNotContain,
+		HeartbeatToken:      s.HeartbeatToken,
+		Backoff:             s.Backoff,
+		BackoffMax:          s.BackoffMax,
+		FailureThreshold:    s.FailureThreshold,
+		FailureDuration:     s.FailureDuration,
+		SuccessThreshold:    s.SuccessThreshold,
+		ResponseTimeSLO:     s.ResponseTimeSLO,
 	}
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigrateLegacyIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cronzee.db")
+	db, err := NewDatabase(path, &StorageConfig{})
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+
+	// An endpoint saved under a random ID is left as it is
+	current := &StoredEndpoint{ID: newEndpointID(), Name: "current", URL: "https://current.example.com"}
+	if err := db.SaveEndpoint(current); err != nil {
+		t.Fatalf("SaveEndpoint: %v", err)
+	}
+	saveRecords(t, db, current.ID, time.Now(), minutes(2)...)
+
+	// Write endpoints the way older versions keyed them, bypassing SaveEndpoint
+	legacy := map[string]int{"api-example-com": 3, "web-example-com": 0}
+	err = db.db.Update(func(tx *bolt.Tx) error {
+		for oldID, records := range legacy {
+			data, err := json.Marshal(&StoredEndpoint{ID: oldID, Name: oldID, URL: "https://" + oldID})
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(EndpointsBucket)).Put([]byte(oldID), data); err != nil {
+				return err
+			}
+			for i := 0; i < records; i++ {
+				record := &HealthCheckRecord{EndpointID: oldID, Timestamp: time.Now().Add(-time.Duration(i) * time.Minute), Status: string(StatusHealthy)}
+				data, err := json.Marshal(record)
+				if err != nil {
+					return err
+				}
+				key := fmt.Sprintf("%s:%d", oldID, record.Timestamp.UnixNano())
+				if err := tx.Bucket([]byte(HistoryBucket)).Put([]byte(key), data); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("writing legacy endpoints: %v", err)
+	}
+	db.Close()
+
+	// Opening the database migrates them
+	db, err = NewDatabase(path, &StorageConfig{})
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+
+	endpoints, err := db.GetAllEndpoints()
+	if err != nil {
+		t.Fatalf("GetAllEndpoints: %v", err)
+	}
+	if len(endpoints) != len(legacy)+1 {
+		t.Fatalf("got %d endpoints after migration, want %d", len(endpoints), len(legacy)+1)
+	}
+	for _, ep := range endpoints {
+		if !isGeneratedID(ep.ID) {
+			t.Errorf("endpoint %q still has legacy ID %q", ep.Name, ep.ID)
+		}
+	}
+
+	for oldID, want := range legacy {
+		newID, err := db.GetSetting(legacyIDSetting + oldID)
+		if err != nil || len(newID) == 0 {
+			t.Fatalf("%s: no legacy ID mapping (%v)", oldID, err)
+		}
+		ep, err := db.GetEndpoint(string(newID))
+		if err != nil {
+			t.Fatalf("%s: migrated endpoint %s not found: %v", oldID, newID, err)
+		}
+		if ep.Name != oldID {
+			t.Errorf("%s: mapped to endpoint %q", oldID, ep.Name)
+		}
+		if _, err := db.GetEndpoint(oldID); err == nil {
+			t.Errorf("%s: still stored under its legacy ID", oldID)
+		}
+
+		records, total, err := db.GetHealthHistory(string(newID), HistoryQuery{})
+		if err != nil {
+			t.Fatalf("%s: GetHealthHistory: %v", oldID, err)
+		}
+		if total != want {
+			t.Errorf("%s: %d history records moved, want %d", oldID, total, want)
+		}
+		for _, record := range records {
+			if record.EndpointID != string(newID) {
+				t.Errorf("%s: moved record has endpoint ID %q", oldID, record.EndpointID)
+			}
+		}
+		if _, total, _ := db.GetHealthHistory(oldID, HistoryQuery{}); total != 0 {
+			t.Errorf("%s: %d history records left under the legacy ID", oldID, total)
+		}
+	}
+
+	if _, total, _ := db.GetHealthHistory(current.ID, HistoryQuery{}); total != 2 {
+		t.Errorf("current endpoint has %d history records, want 2", total)
+	}
+	if ep, err := db.GetEndpoint(current.ID); err != nil || ep.Name != "current" {
+		t.Errorf("current endpoint changed by the migration: %v, %v", ep, err)
+	}
+}
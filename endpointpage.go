@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// endpointPageTemplate renders the /endpoint/<id> page
+var endpointPageTemplate = template.Must(template.ParseFS(webAssets, "web/endpoint.html"))
+
+// The endpoint page charts its history in endpointPageBuckets time buckets
+// and lists at most endpointPageIncidents incidents and endpointPageFailures
+// failed checks, newest first.
+const (
+	endpointPagePath      = "/endpoint/"
+	endpointPageBuckets   = 200
+	endpointPageIncidents = 50
+	endpointPageFailures  = 10
+)
+
+// Incident is a run of consecutive failed checks. End is the first passed
+// check after it, and zero while the incident is ongoing.
+type Incident struct {
+	Start  time.Time
+	End    time.Time
+	Checks int
+	Error  string // error of the first failed check
+}
+
+// incidentFinder finds the incidents in records fed to it oldest first,
+// keeping only the newest keep of them
+type incidentFinder struct {
+	keep      int
+	incidents []Incident
+	current   *Incident
+}
+
+// add feeds the next record to the finder
+func (f *incidentFinder) add(rec *HealthCheckRecord) {
+	if rec.Status != string(StatusUnhealthy) {
+		if f.current != nil && rec.Status == string(StatusHealthy) {
+			f.current.End = rec.Timestamp
+			f.push(*f.current)
+			f.current = nil
+		}
+		return
+	}
+	if f.current == nil {
+		f.current = &Incident{Start: rec.Timestamp, Error: rec.Error}
+	}
+	f.current.Checks++
+}
+
+// push records a finished incident, dropping the oldest beyond keep
+func (f *incidentFinder) push(incident Incident) {
+	f.incidents = append(f.incidents, incident)
+	if len(f.incidents) > f.keep {
+		f.incidents = f.incidents[1:]
+	}
+}
+
+// result returns the incidents found, newest first, the ongoing one included
+func (f *incidentFinder) result() []Incident {
+	if f.current != nil {
+		f.push(*f.current)
+		f.current = nil
+	}
+	incidents := slices.Clone(f.incidents)
+	slices.Reverse(incidents)
+	return incidents
+}
+
+// endpointPage is the data behind the endpoint page. Times and durations are
+// formatted here so the template stays simple.
+type endpointPage struct {
+	pageBranding
+	Version        string
+	RefreshSeconds int
+	Endpoint       *StoredEndpoint
+	Status         EndpointStatus
+	Config         string // the endpoint as it would be exported, header values hidden
+
+	Checks      int
+	Healthy     int
+	Uptime      string
+	AvgResponse string
+	RangeStart  string
+	RangeEnd    string
+	Timeline    []timelineBar
+	Chart       responseChart
+
+	Incidents []incidentRow
+	Failures  []failureRow
+}
+
+// timelineBar is one bucket of the status timeline
+type timelineBar struct {
+	Class string // healthy, unhealthy, partial or empty
+	Title string
+}
+
+// responseChart holds SVG polylines of the average and p95 response time per
+// bucket, in a responseChartWidth by responseChartHeight view box
+type responseChart struct {
+	Avg   string
+	P95   string
+	MaxMs string
+}
+
+const (
+	responseChartWidth  = 1000
+	responseChartHeight = 200
+)
+
+// incidentRow is an incident as shown on the page
+type incidentRow struct {
+	Start    string
+	End      string
+	Duration string
+	Checks   int
+	Error    string
+	Ongoing  bool
+}
+
+// failureRow is a failed check as shown on the page
+type failureRow struct {
+	Time  string
+	Error string
+	Body  string
+}
+
+// handleEndpointPage renders a standalone page for one endpoint at
+// /endpoint/<id>, with its configuration, current status, history chart and
+// incidents, so it can be linked to directly
+func (s *Server) handleEndpointPage(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, endpointPagePath)
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	id = s.resolveEndpointID(id)
+
+	stored, err := s.db.GetEndpoint(id)
+	if err != nil {
+		http.Error(w, "Endpoint not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	refresh := s.config.RefreshInterval
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	page := endpointPage{
+		pageBranding:   s.branding(),
+		Version:        version,
+		RefreshSeconds: int(refresh.Seconds()),
+		Endpoint:       stored,
+		Status:         EndpointStatus{ID: stored.ID, Name: stored.Name, URL: stored.URL, Status: string(StatusUnknown)},
+		Config:         endpointConfigYAML(stored, s.monitor.currentConfig()),
+	}
+	if state, ok := s.monitor.GetStatus()[id]; ok {
+		state.mu.RLock()
+		page.Status = s.endpointStatus(state)
+		state.mu.RUnlock()
+	}
+
+	if err := page.fillHistory(s.db, id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := endpointPageTemplate.Execute(w, page); err != nil {
+		log.Printf("Endpoint page template error: %v", err)
+	}
+}
+
+// endpointConfigYAML renders an endpoint in the export format without its
+// secrets, as a redacted export has them. All header values are hidden, since
+// they often carry credentials, and the header values are masked in the
+// request body too.
+func endpointConfigYAML(stored *StoredEndpoint, cfg *Config) string {
+	exported := exportEndpoint(stored)
+	exported.Body = redactSecrets(exported.Body, exported.Headers, cfg.RedactHeaders, cfg.redactPattern)
+	exported.redact(cfg.RedactHeaders)
+	if len(exported.Headers) > 0 {
+		headers := make(map[string]string, len(exported.Headers))
+		for name := range exported.Headers {
+			headers[name] = "(hidden)"
+		}
+		exported.Headers = headers
+	}
+	data, err := yaml.Marshal(exported)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// fillHistory computes the page's statistics, charts, incidents and recent
+// failures from the endpoint's history. The history is streamed, since the
+// page is public and the whole retention window would otherwise be loaded on
+// every view; only the buckets and the rows the page lists are kept.
+func (p *endpointPage) fillHistory(store Store, id string) error {
+	var total time.Duration
+	samples := 0
+	incidents := incidentFinder{keep: endpointPageIncidents}
+	var failures []*HealthCheckRecord
+	all := func(*HealthCheckRecord) bool { return true }
+	buckets, err := streamHistoryBuckets(store, id, endpointPageBuckets, time.Time{}, time.Time{}, all, func(rec *HealthCheckRecord) {
+		p.Checks++
+		if rec.Status == string(StatusHealthy) {
+			p.Healthy++
+		}
+		if rec.ResponseTime > 0 {
+			total += rec.ResponseTime
+			samples++
+		}
+		incidents.add(rec)
+		if rec.Error != "" {
+			failures = append(failures, rec)
+			if len(failures) > endpointPageFailures {
+				failures = failures[1:]
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	p.Uptime = "-"
+	if p.Checks > 0 {
+		p.Uptime = fmt.Sprintf("%.2f%%", float64(p.Healthy)/float64(p.Checks)*100)
+	}
+	p.AvgResponse = "-"
+	if samples > 0 {
+		p.AvgResponse = formatMs(float64((total / time.Duration(samples)).Microseconds()) / 1000.0)
+	}
+
+	if len(buckets) > 0 {
+		p.RangeStart = buckets[0].Start.Local().Format("2006-01-02 15:04")
+		p.RangeEnd = buckets[len(buckets)-1].End.Local().Format("2006-01-02 15:04")
+	}
+	for _, b := range buckets {
+		bar := timelineBar{Class: "partial"}
+		switch {
+		case b.Checks == 0:
+			bar.Class = "empty"
+			bar.Title = "no checks, " + b.Start.Local().Format("2006-01-02 15:04")
+		case b.Healthy == b.Checks:
+			bar.Class = "healthy"
+		case b.Healthy == 0:
+			bar.Class = "unhealthy"
+		}
+		if b.Checks > 0 {
+			bar.Title = fmt.Sprintf("%.1f%% up (%d checks), avg %s, p95 %s, %s",
+				b.UptimePercent, b.Checks, formatMs(b.AvgResponseTimeMs), formatMs(b.P95ResponseTimeMs),
+				b.Start.Local().Format("2006-01-02 15:04"))
+		}
+		p.Timeline = append(p.Timeline, bar)
+	}
+	p.Chart = buildResponseChart(buckets)
+
+	for _, incident := range incidents.result() {
+		row := incidentRow{
+			Start:   incident.Start.Local().Format("2006-01-02 15:04:05"),
+			Checks:  incident.Checks,
+			Error:   incident.Error,
+			Ongoing: incident.End.IsZero(),
+		}
+		if row.Ongoing {
+			row.Duration = time.Since(incident.Start).Round(time.Second).String()
+		} else {
+			row.End = incident.End.Local().Format("2006-01-02 15:04:05")
+			row.Duration = incident.End.Sub(incident.Start).Round(time.Second).String()
+		}
+		p.Incidents = append(p.Incidents, row)
+	}
+
+	// Newest first
+	for i := len(failures) - 1; i >= 0; i-- {
+		rec := failures[i]
+		p.Failures = append(p.Failures, failureRow{
+			Time:  rec.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			Error: rec.Error,
+			Body:  rec.BodySnapshot,
+		})
+	}
+	return nil
+}
+
+// buildResponseChart scales the buckets' average and p95 response times to
+// the chart's view box, skipping buckets without checks
+func buildResponseChart(buckets []HistoryPoint) responseChart {
+	maxMs := 1.0
+	for _, b := range buckets {
+		maxMs = max(maxMs, b.P95ResponseTimeMs)
+	}
+
+	var avg, p95 []string
+	for i, b := range buckets {
+		if b.Checks == 0 {
+			continue
+		}
+		x := float64(i) / float64(max(len(buckets)-1, 1)) * responseChartWidth
+		yFor := func(ms float64) float64 { return responseChartHeight - ms/maxMs*responseChartHeight }
+		avg = append(avg, fmt.Sprintf("%.1f,%.1f", x, yFor(b.AvgResponseTimeMs)))
+		p95 = append(p95, fmt.Sprintf("%.1f,%.1f", x, yFor(b.P95ResponseTimeMs)))
+	}
+	return responseChart{
+		Avg:   strings.Join(avg, " "),
+		P95:   strings.Join(p95, " "),
+		MaxMs: formatMs(maxMs),
+	}
+}
+
+// formatMs formats a duration in milliseconds for display
+func formatMs(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Endpoint lists from /api/endpoints and /api/status can be ordered with
+// ?sort=. Ties are broken by name, then ID, so the order is stable between
+// requests.
+const (
+	SortByStatus       = "status"        // unhealthy first, then unknown, healthy and disabled
+	SortByName         = "name"          // alphabetical, ignoring case
+	SortByResponseTime = "response_time" // slowest last check first
+)
+
+// endpointListQuery is the ordering and filtering requested for an endpoint
+// list
+type endpointListQuery struct {
+	Sort   string
+	Search string
+}
+
+// parseEndpointListQuery reads ?sort= and ?search=. The sort defaults to
+// SortByStatus so the endpoints needing attention come first.
+func parseEndpointListQuery(r *http.Request) (endpointListQuery, error) {
+	q := endpointListQuery{
+		Sort:   r.URL.Query().Get("sort"),
+		Search: strings.TrimSpace(r.URL.Query().Get("search")),
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = SortByStatus
+	case SortByStatus, SortByName, SortByResponseTime:
+	default:
+		return q, fmt.Errorf("unknown sort %q (want %s, %s or %s)", q.Sort, SortByStatus, SortByName, SortByResponseTime)
+	}
+	return q, nil
+}
+
+// matches reports whether the search text appears, ignoring case, in the
+// endpoint's ID, name, URL or one of its tags. An empty search matches
+// everything.
+func (q endpointListQuery) matches(id, name, url string, tags []string) bool {
+	if q.Search == "" {
+		return true
+	}
+	search := strings.ToLower(q.Search)
+	for _, field := range append([]string{id, name, url}, tags...) {
+		if strings.Contains(strings.ToLower(field), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointSortKey holds the values an endpoint list is sorted on
+type endpointSortKey struct {
+	id           string
+	name         string
+	rank         int
+	responseTime time.Duration
+}
+
+// statusRank orders statuses so that the endpoints needing attention come
+// first
+func statusRank(status string, enabled bool) int {
+	if !enabled {
+		return 3
+	}
+	switch HealthStatus(status) {
+	case StatusUnhealthy:
+		return 0
+	case StatusHealthy:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// less reports whether a sorts before b
+func (q endpointListQuery) less(a, b endpointSortKey) bool {
+	switch q.Sort {
+	case SortByStatus:
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+	case SortByResponseTime:
+		if a.responseTime != b.responseTime {
+			return a.responseTime > b.responseTime
+		}
+	}
+	if an, bn := strings.ToLower(a.name), strings.ToLower(b.name); an != bn {
+		return an < bn
+	}
+	return a.id < b.id
+}
+
+// stateSortKey returns the sort key of a monitored endpoint. Caller must hold
+// state.mu.
+func stateSortKey(state *EndpointState) endpointSortKey {
+	return endpointSortKey{
+		id:           state.ID,
+		name:         state.Endpoint.Name,
+		rank:         statusRank(string(state.Status), state.Enabled),
+		responseTime: state.ResponseTime,
+	}
+}
+
+// sortStatuses sorts endpoint statuses as requested, using the sort keys of
+// their endpoints by ID
+func (q endpointListQuery) sortStatuses(statuses []EndpointStatus, keys map[string]endpointSortKey) {
+	sort.SliceStable(statuses, func(i, j int) bool { return q.less(keys[statuses[i].ID], keys[statuses[j].ID]) })
+}
+
+// sortEndpoints sorts stored endpoints as requested, taking their status and
+// response time from the monitor's states
+func (q endpointListQuery) sortEndpoints(endpoints []*StoredEndpoint, states map[string]*EndpointState) {
+	keys := make(map[string]endpointSortKey, len(endpoints))
+	for _, ep := range endpoints {
+		key := endpointSortKey{id: ep.ID, name: ep.Name, rank: statusRank(string(StatusUnknown), ep.Enabled)}
+		if state, ok := states[ep.ID]; ok {
+			state.mu.RLock()
+			key = stateSortKey(state)
+			state.mu.RUnlock()
+		}
+		keys[ep.ID] = key
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool { return q.less(keys[endpoints[i].ID], keys[endpoints[j].ID]) })
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// EndpointExport is the document produced by /api/endpoints/export and
+// accepted by /api/endpoints/import, in either JSON or YAML
+type EndpointExport struct {
+	Endpoints []ExportedEndpoint `json:"endpoints" yaml:"endpoints"`
+}
+
+// ExportedEndpoint is the portable form of a StoredEndpoint. Durations are
+// written as strings such as "30s" so the file is easy to edit by hand.
+type ExportedEndpoint struct {
+	ID                  string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Name                string            `json:"name" yaml:"name"`
+	Type                string            `json:"type,omitempty" yaml:"type,omitempty"`
+	URL                 string            `json:"url" yaml:"url"`
+	URLs                []string          `json:"urls,omitempty" yaml:"urls,omitempty"`
+	Method              string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Body                string            `json:"body,omitempty" yaml:"body,omitempty"`
+	Timeout             string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ConnectTimeout      string            `json:"connect_timeout,omitempty" yaml:"connect_timeout,omitempty"`
+	CheckInterval       string            `json:"check_interval,omitempty" yaml:"check_interval,omitempty"`
+	ExpectedStatus      int               `json:"expected_status,omitempty" yaml:"expected_status,omitempty"`
+	Headers             map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	ExpectedHeaders     map[string]string `json:"expected_headers,omitempty" yaml:"expected_headers,omitempty"`
+	ExpectedIP          string            `json:"expected_ip,omitempty" yaml:"expected_ip,omitempty"`
+	GraphQLQuery        string            `json:"graphql_query,omitempty" yaml:"graphql_query,omitempty"`
+	GraphQLDataPath     string            `json:"graphql_data_path,omitempty" yaml:"graphql_data_path,omitempty"`
+	JSONPath            string            `json:"json_path,omitempty" yaml:"json_path,omitempty"`
+	JSONPathExpected    string            `json:"json_path_expected,omitempty" yaml:"json_path_expected,omitempty"`
+	Invert              bool              `json:"invert,omitempty" yaml:"invert,omitempty"`
+	DependsOn           []string          `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Tags                []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	AlwaysAlert         bool              `json:"always_alert,omitempty" yaml:"always_alert,omitempty"`
+	AlertOnFirstFailure bool              `json:"alert_on_first_failure,omitempty" yaml:"alert_on_first_failure,omitempty"`
+	SlackMention        string            `json:"slack_mention,omitempty" yaml:"slack_mention,omitempty"`
+	AlertEmails         []string          `json:"alert_emails,omitempty" yaml:"alert_emails,omitempty"`
+	UserAgent           string            `json:"user_agent,omitempty" yaml:"user_agent,omitempty"`
+	ProxyURL            string            `json:"proxy_url,omitempty" yaml:"proxy_url,omitempty"`
+	AddressFamily       string            `json:"address_family,omitempty" yaml:"address_family,omitempty"`
+	ResolveOverride     string            `json:"resolve_override,omitempty" yaml:"resolve_override,omitempty"`
+	DisableKeepAlives   bool              `json:"disable_keep_alives,omitempty" yaml:"disable_keep_alives,omitempty"`
+	MaxRedirects        int               `json:"max_redirects,omitempty" yaml:"max_redirects,omitempty"`
+	WebSocketPing       bool              `json:"websocket_ping,omitempty" yaml:"websocket_ping,omitempty"`
+	MinBodyBytes        int64             `json:"assert_min_body_bytes,omitempty" yaml:"assert_min_body_bytes,omitempty"`
+	MaxBodyBytes        int64             `json:"assert_max_body_bytes,omitempty" yaml:"assert_max_body_bytes,omitempty"`
+	BodyMustNotContain  string            `json:"body_must_not_contain,omitempty" yaml:"body_must_not_contain,omitempty"`
+	HeartbeatToken      string            `json:"heartbeat_token,omitempty" yaml:"heartbeat_token,omitempty"`
+	Backoff             bool              `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	BackoffMax          string            `json:"backoff_max,omitempty" yaml:"backoff_max,omitempty"`
+	FailureThreshold    int               `json:"failure_threshold,omitempty" yaml:"failure_threshold,omitempty"`
+	FailureDuration     string            `json:"failure_duration,omitempty" yaml:"failure_duration,omitempty"`
+	SuccessThreshold    int               `json:"success_threshold,omitempty" yaml:"success_threshold,omitempty"`
+	ResponseTimeSLO     string            `json:"response_time_slo,omitempty" yaml:"response_time_slo,omitempty"`
+	Enabled             *bool             `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	AlertsSuppressed    bool              `json:"alerts_suppressed,omitempty" yaml:"alerts_suppressed,omitempty"`
+}
+
+// exportEndpoint converts a stored endpoint to its portable form
+func exportEndpoint(s *StoredEndpoint) ExportedEndpoint {
+	enabled := s.Enabled
+	var timeout, connectTimeout, checkInterval, failureDuration, backoffMax, responseTimeSLO string
+	if s.CheckInterval > 0 {
+		checkInterval = s.CheckInterval.String()
+	}
+	if s.Timeout > 0 {
+		timeout = s.Timeout.String()
+	}
+	if s.ConnectTimeout > 0 {
+		connectTimeout = s.ConnectTimeout.String()
+	}
+	if s.FailureDuration > 0 {
+		failureDuration = s.FailureDuration.String()
+	}
+	if s.BackoffMax > 0 {
+		backoffMax = s.BackoffMax.String()
+	}
+	if s.ResponseTimeSLO > 0 {
+		responseTimeSLO = s.ResponseTimeSLO.String()
+	}
+	return ExportedEndpoint{
+		ID:                  s.ID,
+		Name:                s.Name,
+		Type:                s.Type,
+		URL:                 s.URL,
+		URLs:                s.URLs,
+		Method:              s.Method,
+		Body:                s.Body,
+		Timeout:             timeout,
+		ConnectTimeout:      connectTimeout,
+		CheckInterval:       checkInterval,
+		ExpectedStatus:      s.ExpectedStatus,
+		Headers:             s.Headers,
+		ExpectedHeaders:     s.ExpectedHeaders,
+		ExpectedIP:          s.ExpectedIP,
+		GraphQLQuery:        s.GraphQLQuery,
+		GraphQLDataPath:     s.GraphQLDataPath,
+		JSONPath:            s.JSONPath,
+		JSONPathExpected:    s.JSONPathExpected,
+		Invert:              s.Invert,
+		DependsOn:           s.DependsOn,
+		Tags:                s.Tags,
+		AlwaysAlert:         s.AlwaysAlert,
+		AlertOnFirstFailure: s.AlertOnFirstFailure,
+		SlackMention:        s.SlackMention,
+		AlertEmails:         s.AlertEmails,
+		UserAgent:           s.UserAgent,
+		ProxyURL:            s.ProxyURL,
+		AddressFamily:       s.AddressFamily,
+		ResolveOverride:     s.ResolveOverride,
+		DisableKeepAlives:   s.DisableKeepAlives,
+		MaxRedirects:        s.MaxRedirects,
+		WebSocketPing:       s.WebSocketPing,
+		MinBodyBytes:        s.MinBodyBytes,
+		MaxBodyBytes:        s.MaxBodyBytes,
+		BodyMustNotContain:  s.BodyMustNotContain,
+		HeartbeatToken:      s.HeartbeatToken,
+		Backoff:             s.Backoff,
+		BackoffMax:          backoffMax,
+		FailureThreshold:    s.FailureThreshold,
+		FailureDuration:     failureDuration,
+		SuccessThreshold:    s.SuccessThreshold,
+		ResponseTimeSLO:     responseTimeSLO,
+		Enabled:             &enabled,
+		AlertsSuppressed:    s.AlertsSuppressed,
+	}
+}
+
+// toStored validates an imported endpoint and converts it to a StoredEndpoint.
+// Unset settings get the usual defaults when the endpoint is saved; a missing
+// enabled flag means enabled.
+func (e ExportedEndpoint) toStored() (*StoredEndpoint, error) {
+	if e.URL == "" && len(e.URLs) > 0 {
+		e.URL = e.URLs[0]
+	}
+	if e.URL == "" && e.Type == CheckTypeHeartbeat && e.ID != "" {
+		e.URL = heartbeatPath + e.ID
+	}
+	if e.Name == "" || e.URL == "" {
+		return nil, fmt.Errorf("name and url are required")
+	}
+	if !validCheckType(e.Type) {
+		return nil, fmt.Errorf("unknown check type: %s", e.Type)
+	}
+	target, targets, err := normalizeTargets(e.Type, e.URL, e.URLs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	e.URL, e.URLs = target, targets
+	if !validExpectedStatus(e.ExpectedStatus) {
+		return nil, fmt.Errorf("invalid expected_status: %d", e.ExpectedStatus)
+	}
+	if e.FailureThreshold < 0 || e.SuccessThreshold < 0 {
+		return nil, fmt.Errorf("thresholds must not be negative")
+	}
+	if _, err := parseProxyURL(e.ProxyURL); err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	if !validAddressFamily(e.AddressFamily) {
+		return nil, fmt.Errorf("invalid address_family: %q", e.AddressFamily)
+	}
+	if err := validateMethod(e.Method, e.Body); err != nil {
+		return nil, err
+	}
+	if e.MaxRedirects < MaxRedirectsNone {
+		return nil, fmt.Errorf("invalid max_redirects: %d", e.MaxRedirects)
+	}
+	if err := validateBodySize(e.MinBodyBytes, e.MaxBodyBytes); err != nil {
+		return nil, err
+	}
+	if err := validateResolveOverride(e.ResolveOverride, e.ProxyURL); err != nil {
+		return nil, fmt.Errorf("invalid resolve_override: %w", err)
+	}
+	if err := validateExpectedIP(e.ExpectedIP); err != nil {
+		return nil, fmt.Errorf("invalid expected_ip: %w", err)
+	}
+	if err := validateAlertEmails(e.AlertEmails); err != nil {
+		return nil, fmt.Errorf("invalid alert_emails: %w", err)
+	}
+
+	var timeout, connectTimeout, interval, failureDuration, backoffMax, responseTimeSLO time.Duration
+	if e.Timeout != "" {
+		if timeout, err = time.ParseDuration(e.Timeout); err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid timeout: %q", e.Timeout)
+		}
+	}
+	if e.ConnectTimeout != "" {
+		if connectTimeout, err = time.ParseDuration(e.ConnectTimeout); err != nil || connectTimeout < 0 {
+			return nil, fmt.Errorf("invalid connect_timeout: %q", e.ConnectTimeout)
+		}
+	}
+	if e.CheckInterval != "" {
+		if interval, err = time.ParseDuration(e.CheckInterval); err != nil || interval < 0 {
+			return nil, fmt.Errorf("invalid check_interval: %q", e.CheckInterval)
+		}
+	}
+	if e.FailureDuration != "" {
+		if failureDuration, err = time.ParseDuration(e.FailureDuration); err != nil || failureDuration < 0 {
+			return nil, fmt.Errorf("invalid failure_duration: %q", e.FailureDuration)
+		}
+	}
+	if e.BackoffMax != "" {
+		if backoffMax, err = time.ParseDuration(e.BackoffMax); err != nil || backoffMax < 0 {
+			return nil, fmt.Errorf("invalid backoff_max: %q", e.BackoffMax)
+		}
+	}
+	if e.ResponseTimeSLO != "" {
+		if responseTimeSLO, err = time.ParseDuration(e.ResponseTimeSLO); err != nil || responseTimeSLO < 0 {
+			return nil, fmt.Errorf("invalid response_time_slo: %q", e.ResponseTimeSLO)
+		}
+	}
+
+	enabled := true
+	if e.Enabled != nil {
+		enabled = *e.Enabled
+	}
+
+	return &StoredEndpoint{
+		ID:                  e.ID,
+		Name:                e.Name,
+		Type:                e.Type,
+		URL:                 e.URL,
+		URLs:                e.URLs,
+		Method:              e.Method,
+		Body:                e.Body,
+		Timeout:             timeout,
+		ConnectTimeout:      connectTimeout,
+		CheckInterval:       interval,
+		ExpectedStatus:      e.ExpectedStatus,
+		Headers:             e.Headers,
+		ExpectedHeaders:     e.ExpectedHeaders,
+		ExpectedIP:          e.ExpectedIP,
+		GraphQLQuery:        e.GraphQLQuery,
+		GraphQLDataPath:     e.GraphQLDataPath,
+		JSONPath:            e.JSONPath,
+		JSONPathExpected:    e.JSONPathExpected,
+		Invert:              e.Invert,
+		DependsOn:           e.DependsOn,
+		Tags:                e.Tags,
+		AlwaysAlert:         e.AlwaysAlert,
+		AlertOnFirstFailure: e.AlertOnFirstFailure,
+		SlackMention:        e.SlackMention,
+		AlertEmails:         e.AlertEmails,
+		UserAgent:           e.UserAgent,
+		ProxyURL:            e.ProxyURL,
+		AddressFamily:       e.AddressFamily,
+		ResolveOverride:     e.ResolveOverride,
+		DisableKeepAlives:   e.DisableKeepAlives,
+		MaxRedirects:        e.MaxRedirects,
+		WebSocketPing:       e.WebSocketPing,
+		MinBodyBytes:        e.MinBodyBytes,
+		MaxBodyBytes:        e.MaxBodyBytes,
+		BodyMustNotContain:  e.BodyMustNotContain,
+		HeartbeatToken:      e.HeartbeatToken,
+		Backoff:             e.Backoff,
+		BackoffMax:          backoffMax,
+		FailureThreshold:    e.FailureThreshold,
+		FailureDuration:     failureDuration,
+		SuccessThreshold:    e.SuccessThreshold,
+		ResponseTimeSLO:     responseTimeSLO,
+		Enabled:             enabled,
+		AlertsSuppressed:    e.AlertsSuppressed,
+	}, nil
+}
+
+// redact masks the endpoint's secrets for an export made without the backup
+// token: the values of the redact headers, sent or expected, the proxy's
+// username and password, and the heartbeat token
+func (e *ExportedEndpoint) redact(redact []string) {
+	e.HeartbeatToken = ""
+	e.ProxyURL = redactURLUserinfo(e.ProxyURL)
+	e.Headers = redactHeaderValues(e.Headers, redact)
+	e.ExpectedHeaders = redactHeaderValues(e.ExpectedHeaders, redact)
+}
+
+// redactHeaderValues returns a copy of headers with the values of the redact
+// headers masked
+func redactHeaderValues(headers map[string]string, redact []string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+	masked := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if sensitiveHeader(name, redact) {
+			value = redactedValue
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// restoreSecrets puts back the secrets a redacted export masked, taking them
+// from old, the endpoint the import overwrites
+func (s *StoredEndpoint) restoreSecrets(old *StoredEndpoint) {
+	if s.HeartbeatToken == "" {
+		s.HeartbeatToken = old.HeartbeatToken
+	}
+	s.ProxyURL = unredactURL(s.ProxyURL, old.ProxyURL)
+	restoreHeaderValues(s.Headers, old.Headers)
+	restoreHeaderValues(s.ExpectedHeaders, old.ExpectedHeaders)
+}
+
+// restoreHeaderValues replaces masked values in headers with the old ones
+func restoreHeaderValues(headers, old map[string]string) {
+	for name, value := range headers {
+		if oldValue, ok := old[name]; ok && value == redactedValue {
+			headers[name] = oldValue
+		}
+	}
+}
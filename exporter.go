@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// Exporter sends check results to an external system. Export is called for
+// every check and must not block; run delivers the results until ctx is
+// cancelled, and Flush sends whatever is still waiting on shutdown.
+type Exporter interface {
+	Export(endpoint Endpoint, record *HealthCheckRecord)
+	run(ctx context.Context)
+	Flush() error
+}
+
+// newExporters creates the exporters enabled in config
+func newExporters(config ExportersConfig) []Exporter {
+	var exporters []Exporter
+	if config.InfluxDB.URL != "" {
+		exporters = append(exporters, newInfluxDBExporter(config.InfluxDB))
+	}
+	if config.StatsDAddress != "" {
+		statsd, err := newStatsDExporter(config)
+		if err != nil {
+			log.Printf("StatsD exporter disabled: %v", err)
+		} else {
+			exporters = append(exporters, statsd)
+		}
+	}
+	return exporters
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+)
+
+// faviconSize is the width and height of the favicon in pixels
+const faviconSize = 32
+
+// faviconColors are the favicon's colors by ?status=; anything else gets the
+// neutral one, so a backgrounded dashboard tab shows when something is down
+var faviconColors = map[string]color.RGBA{
+	"":                      {0x63, 0x66, 0xf1, 0xff},
+	string(StatusHealthy):   {0x10, 0xb9, 0x81, 0xff},
+	string(StatusUnhealthy): {0xef, 0x44, 0x44, 0xff},
+}
+
+// favicons holds the rendered favicon for each entry of faviconColors
+var favicons = func() map[string][]byte {
+	icons := make(map[string][]byte, len(faviconColors))
+	for status, c := range faviconColors {
+		icons[status] = renderFavicon(c)
+	}
+	return icons
+}()
+
+// renderFavicon draws a filled circle of color c and wraps the PNG in an ICO
+// container, which every browser accepts at /favicon.ico
+func renderFavicon(c color.RGBA) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, faviconSize, faviconSize))
+	center := float64(faviconSize-1) / 2
+	radius := float64(faviconSize)/2 - 1
+	for y := 0; y < faviconSize; y++ {
+		for x := 0; x < faviconSize; x++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	var pngData bytes.Buffer
+	png.Encode(&pngData, img)
+
+	// ICONDIR header followed by a single ICONDIRENTRY pointing at the PNG
+	var ico bytes.Buffer
+	binary.Write(&ico, binary.LittleEndian, struct {
+		Reserved, Type, Count uint16
+		Width, Height         uint8
+		Colors, Reserved2     uint8
+		Planes, BitCount      uint16
+		Size, Offset          uint32
+	}{
+		Type: 1, Count: 1,
+		Width: faviconSize, Height: faviconSize,
+		Planes: 1, BitCount: 32,
+		Size: uint32(pngData.Len()), Offset: 22,
+	})
+	ico.Write(pngData.Bytes())
+	return ico.Bytes()
+}
+
+// handleFavicon serves the favicon, colored by ?status=healthy or
+// ?status=unhealthy as the dashboard's overall status
+func (s *Server) handleFavicon(w http.ResponseWriter, r *http.Request) {
+	icon, ok := favicons[r.URL.Query().Get("status")]
+	if !ok {
+		icon = favicons[""]
+	}
+	w.Header().Set("Content-Type", "image/x-icon")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(icon)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IncidentStatus is an endpoint's incident as returned to external incident
+// tools. State is "open" until the endpoint recovers or the incident is
+// resolved. Incidents are outages only: checks that pass but breach the
+// response time SLO are recorded in the history, not as incidents.
+type IncidentStatus struct {
+	ID                  string `json:"id"`
+	EndpointID          string `json:"endpoint_id"`
+	EndpointName        string `json:"endpoint_name"`
+	State               string `json:"state"`
+	StartedAt           string `json:"started_at"`
+	ResolvedAt          string `json:"resolved_at,omitempty"`
+	Acknowledged        bool   `json:"acknowledged"`
+	AcknowledgedAt      string `json:"acknowledged_at,omitempty"`
+	SnoozeUntil         string `json:"snooze_until,omitempty"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// incidentID identifies an unhealthy endpoint's current incident by the
+// endpoint ID and the time it went down, so an action meant for an incident
+// that has since ended can't touch a later one. Caller must hold state.mu.
+func incidentID(state *EndpointState) string {
+	return fmt.Sprintf("%s-%d", state.ID, state.LastStatusChange.Unix())
+}
+
+// incidentStatus builds the API view of an endpoint's incident. Caller must
+// hold state.mu.
+func incidentStatus(state *EndpointState) IncidentStatus {
+	incident := IncidentStatus{
+		ID:                  incidentID(state),
+		EndpointID:          state.ID,
+		EndpointName:        state.Endpoint.Name,
+		State:               "open",
+		StartedAt:           state.LastStatusChange.Format(time.RFC3339),
+		Acknowledged:        state.Acknowledged,
+		ConsecutiveFailures: state.ConsecutiveFailures,
+		LastError:           state.LastError,
+	}
+	if state.Acknowledged {
+		incident.AcknowledgedAt = state.AcknowledgedAt.Format(time.RFC3339)
+	}
+	if snoozed(state) {
+		incident.SnoozeUntil = state.SnoozeUntil.Format(time.RFC3339)
+	}
+	return incident
+}
+
+// handleIncidentAck acknowledges an endpoint's open incident, silencing
+// repeat alerts until it recovers
+func (s *Server) handleIncidentAck(w http.ResponseWriter, r *http.Request) {
+	s.handleIncidentAction(w, r, s.monitor.AcknowledgeEndpoint, "acknowledged")
+}
+
+// handleIncidentResolve closes an endpoint's open incident
+func (s *Server) handleIncidentResolve(w http.ResponseWriter, r *http.Request) {
+	s.handleIncidentAction(w, r, s.monitor.ResolveIncident, "resolved")
+}
+
+// handleIncidentAction applies action to the open incident selected by
+// endpoint_id or incident_id, optionally snoozes the endpoint's alerts, and
+// returns the updated incident. It serves chat bots and incident tools, so it
+// requires server.incident_token rather than relying on read_only.
+func (s *Server) handleIncidentAction(w http.ResponseWriter, r *http.Request, action func(string) error, actionName string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !checkBearerToken(w, r, s.config.IncidentToken, "Incident actions are disabled; set server.incident_token to enable them") {
+		return
+	}
+
+	query := r.URL.Query()
+	req := struct {
+		EndpointID string `json:"endpoint_id"`
+		IncidentID string `json:"incident_id"`
+		Snooze     string `json:"snooze"`
+	}{
+		EndpointID: query.Get("endpoint_id"),
+		IncidentID: query.Get("incident_id"),
+		Snooze:     query.Get("snooze"),
+	}
+	if req.EndpointID == "" && req.IncidentID == "" {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+	req.EndpointID = s.resolveEndpointID(req.EndpointID)
+	if req.EndpointID == "" && req.IncidentID == "" {
+		http.Error(w, "Endpoint ID or incident ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var snooze time.Duration
+	if req.Snooze != "" {
+		var err error
+		snooze, err = time.ParseDuration(req.Snooze)
+		if err != nil || snooze <= 0 {
+			http.Error(w, "Invalid snooze: use a value like 30m or 2h", http.StatusBadRequest)
+			return
+		}
+	}
+
+	state := s.findIncident(req.EndpointID, req.IncidentID)
+	if state == nil {
+		if req.IncidentID != "" {
+			http.Error(w, "Incident not found or no longer open: "+req.IncidentID, http.StatusNotFound)
+		} else {
+			http.Error(w, "Endpoint not found: "+req.EndpointID, http.StatusNotFound)
+		}
+		return
+	}
+
+	state.mu.RLock()
+	id := state.ID
+	before := incidentStatus(state)
+	open := state.Status == StatusUnhealthy
+	state.mu.RUnlock()
+	if !open {
+		http.Error(w, "Endpoint "+id+" has no open incident", http.StatusConflict)
+		return
+	}
+
+	if err := action(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if snooze > 0 {
+		if _, err := s.monitor.SnoozeAlerts(id, snooze); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	state.mu.RLock()
+	incident := before
+	if state.Status == StatusUnhealthy && incidentID(state) == before.ID {
+		incident = incidentStatus(state)
+	} else {
+		incident.State = "resolved"
+		incident.ResolvedAt = time.Now().Format(time.RFC3339)
+		incident.Acknowledged = false
+		incident.AcknowledgedAt = ""
+		if snoozed(state) {
+			incident.SnoozeUntil = state.SnoozeUntil.Format(time.RFC3339)
+		}
+	}
+	state.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "Incident " + actionName,
+		"incident": incident,
+	})
+}
+
+// findIncident returns the state of the endpoint with endpointID, or of the
+// endpoint whose open incident is wantIncident. When both are given they must
+// agree. It returns nil if there is no match.
+func (s *Server) findIncident(endpointID, wantIncident string) *EndpointState {
+	states := s.monitor.GetStatus()
+	if wantIncident == "" {
+		return states[endpointID]
+	}
+	for id, state := range states {
+		if endpointID != "" && id != endpointID {
+			continue
+		}
+		state.mu.RLock()
+		match := state.Status == StatusUnhealthy && incidentID(state) == wantIncident
+		state.mu.RUnlock()
+		if match {
+			return state
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxDBTimeout bounds each write to InfluxDB, and influxDBMaxBatches is how
+// many batches may wait while InfluxDB is slow before the oldest results are
+// dropped
+const (
+	influxDBTimeout    = 10 * time.Second
+	influxDBMaxBatches = 10
+)
+
+// influxDBExporter writes check results to InfluxDB in the line protocol.
+// Each result is a point tagged with endpoint and status, with the
+// response_time_ms and status_code fields.
+type influxDBExporter struct {
+	*batcher[string] // lines waiting to be written
+	config           InfluxDBConfig
+	writeURL         string
+	client           *http.Client
+}
+
+// newInfluxDBExporter creates an exporter for config, which LoadConfig has
+// validated and filled in with defaults
+func newInfluxDBExporter(config InfluxDBConfig) *influxDBExporter {
+	base := strings.TrimSuffix(config.URL, "/")
+	query := url.Values{"precision": {"ms"}}
+	writeURL := base + "/write?"
+	if config.Bucket != "" {
+		query.Set("org", config.Org)
+		query.Set("bucket", config.Bucket)
+		writeURL = base + "/api/v2/write?"
+	} else {
+		query.Set("db", config.Database)
+	}
+	e := &influxDBExporter{
+		config:   config,
+		writeURL: writeURL + query.Encode(),
+		client:   &http.Client{Timeout: influxDBTimeout},
+	}
+	e.batcher = newBatcher("check results", "InfluxDB", config.BatchSize, influxDBMaxBatches*config.BatchSize, config.FlushInterval, e.write)
+	return e
+}
+
+// Export queues a check result for the next write
+func (e *influxDBExporter) Export(endpoint Endpoint, record *HealthCheckRecord) {
+	e.add(influxLine(e.config.Measurement, endpoint.Name, record))
+}
+
+// write sends one batch of lines to InfluxDB
+func (e *influxDBExporter) write(lines []string) error {
+	body := strings.Join(lines, "\n") + "\n"
+	req, err := http.NewRequest(http.MethodPost, e.writeURL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.config.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.config.Token)
+	} else if e.config.Username != "" {
+		req.SetBasicAuth(e.config.Username, e.config.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("InfluxDB returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// influxLine formats a check result as a line protocol point
+func influxLine(measurement, endpoint string, record *HealthCheckRecord) string {
+	responseMs := float64(record.ResponseTime.Microseconds()) / 1000.0
+	return fmt.Sprintf("%s,endpoint=%s,status=%s response_time_ms=%s,status_code=%di %d",
+		influxMeasurementEscaper.Replace(measurement),
+		influxTagEscaper.Replace(endpoint),
+		influxTagEscaper.Replace(record.Status),
+		strconv.FormatFloat(responseMs, 'f', -1, 64),
+		record.StatusCode,
+		record.Timestamp.UnixMilli())
+}
+
+// Line protocol escaping: measurements escape commas and spaces, tag values
+// also escape equals signs. Newlines can't be escaped, so they become spaces.
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, " ", `\ `, "\n", `\ `)
+	influxTagEscaper         = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `)
+)
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is a log file that is rotated once it would grow past
+// maxSize bytes. The current file is renamed to path.1, older ones shift to
+// path.2 and so on, and only maxBackups of them are kept. At least one backup
+// is always kept; server.log_max_backups of 0 means the default.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// openRotatingFile opens path for appending, creating it if needed
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, maxBackups: max(maxBackups, 1)}
+	file, size, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	f.file, f.size = file, size
+	return f, nil
+}
+
+// open opens the current log file and returns it with its size
+func (f *rotatingFile) open() (*os.File, int64, error) {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// Write appends p to the log file, rotating it first if p would take it past
+// the size limit. A single write larger than the limit still goes into one
+// file.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the current file rather than losing messages
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file %s: %v\n", f.path, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups along, dropping the oldest, and starts a new
+// file. The old file stays open until the new one is, so if anything fails
+// logging carries on where it was. Caller must hold f.mu.
+func (f *rotatingFile) rotate() error {
+	os.Remove(f.backupPath(f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		os.Rename(f.backupPath(i), f.backupPath(i+1))
+	}
+	if err := os.Rename(f.path, f.backupPath(1)); err != nil {
+		return err
+	}
+
+	file, size, err := f.open()
+	if err != nil {
+		return err
+	}
+	f.file.Close()
+	f.file, f.size = file, size
+	return nil
+}
+
+// backupPath returns the name of the nth most recent backup
+func (f *rotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
+
+// Close closes the log file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -11,26 +11,83 @@ import (
 
 func main() {
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
-	dbPath := flag.String("db", "cronzee.db", "Path to database file")
+	dbPath := flag.String("db", "cronzee.db", "Path to database file (\":memory:\" for an in-memory store)")
+	dbDriver := flag.String("db-driver", "bolt", "Database driver: bolt, sqlite or memory")
+	watch := flag.Bool("watch", false, "Watch the configuration file and reload it on change")
+	checkURL := flag.String("check-url", "", "Check this URL once, print the result and exit (exit status 1 if the check fails)")
+	checkType := flag.String("check-type", CheckTypeHTTP, "Check type for -check-url: http, graphql, websocket, dns or ping")
+	method := flag.String("method", "GET", "HTTP method for -check-url")
+	timeout := flag.Duration("timeout", 10*time.Second, "Timeout for -check-url")
+	expectedStatus := flag.Int("expected-status", 200, "Expected HTTP status for -check-url (-1 accepts any)")
+	dbTimeout := flag.Duration("db-timeout", DefaultDBOpenTimeout, "How long to wait for the database file if another process has it locked")
+	recoverDB := flag.Bool("recover", false, "If the BoltDB database file is corrupt, move it aside and start with an empty database")
+	followDefaults := flag.Bool("follow-defaults", false, "Clear the 30s check interval and 10s timeout earlier versions stored on every endpoint, so those endpoints follow check_interval and default_timeout")
 	flag.Parse()
 
+	if *checkURL != "" {
+		os.Exit(runCheckOnce(*configFile, checkOnceOptions{
+			URL:            *checkURL,
+			Type:           *checkType,
+			Method:         *method,
+			Timeout:        *timeout,
+			ExpectedStatus: *expectedStatus,
+		}, os.Stdout))
+	}
+
 	// Load configuration
 	config, err := LoadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if config.Server.LogFile != "" {
+		logFile, err := openRotatingFile(config.Server.LogFile, int64(config.Server.LogMaxSizeMB)<<20, config.Server.LogMaxBackups)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		defer logFile.Close()
+		log.SetOutput(logFile)
+	}
+
 	// Initialize database
-	db, err := NewDatabase(*dbPath)
+	if *dbTimeout <= 0 {
+		log.Fatalf("-db-timeout must be positive")
+	}
+	config.Storage.OpenTimeout = *dbTimeout
+	config.Storage.VerifyOnOpen = *recoverDB
+	db, err := OpenStore(*dbDriver, *dbPath, &config.Storage)
+	if err != nil && isCorruptDatabase(err) {
+		if !*recoverDB {
+			log.Fatalf("Failed to initialize database: %v (the file is corrupt; restart with -recover to move it aside and start with an empty database)", err)
+		}
+		db, err = recoverCorruptDatabase(*dbPath, &config.Storage, err)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
+	if *followDefaults {
+		if err := migrateDefaultTimeouts(db); err != nil {
+			log.Fatalf("Failed to migrate endpoint timeouts: %v", err)
+		}
+		if err := migrateDefaultCheckIntervals(db); err != nil {
+			log.Fatalf("Failed to migrate endpoint check intervals: %v", err)
+		}
+	}
+
+	if config.Storage.BackupDir != "" {
+		if store, ok := db.(BackupStore); ok {
+			go startBackupRoutine(store, config.Storage)
+		} else {
+			log.Printf("Scheduled backups are not supported by the %s database driver", *dbDriver)
+		}
+	}
+
 	// Note: Endpoints are loaded only from database, not from config.yaml
 	// Use the web UI to add/remove endpoints
 
-	log.Printf("Starting Site Watch...")
+	log.Printf("Starting Site Watch %s...", version)
 
 	// Initialize monitor with database
 	monitor := NewMonitor(config, db)
@@ -41,13 +98,20 @@ func main() {
 
 	// Start web server if enabled
 	if config.Server.Enabled {
-		server := NewServer(monitor, db, config.Server.Port)
+		server := NewServer(monitor, db, &config.Server)
 		server.Start()
 	}
 
 	// Start monitoring
 	monitor.Start()
 
+	// Reload configuration on file changes if requested
+	if *watch {
+		if err := WatchConfig(*configFile, monitor); err != nil {
+			log.Printf("Config watcher disabled: %v", err)
+		}
+	}
+
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in process memory. It is meant for
+// tests, demos and ephemeral runs; all data is lost when the process exits.
+type MemoryStore struct {
+	endpoints map[string][]byte               // endpoint ID -> JSON, mirroring the Bolt bucket
+	history   map[string][]*HealthCheckRecord // endpoint ID -> records, oldest first
+	settings  map[string][]byte
+	config    *StorageConfig
+	mu        sync.RWMutex
+	cleanups  cleanupTracker
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore(config *StorageConfig) *MemoryStore {
+	store := &MemoryStore{
+		endpoints: make(map[string][]byte),
+		history:   make(map[string][]*HealthCheckRecord),
+		settings:  make(map[string][]byte),
+		config:    config,
+	}
+
+	// Start cleanup goroutine
+	go startCleanupRoutine(store, config)
+
+	return store
+}
+
+// Close is a no-op for the in-memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// Ping always succeeds for the in-memory store
+func (m *MemoryStore) Ping() error {
+	return nil
+}
+
+// DBStats reports record counts; nothing is stored on disk
+func (m *MemoryStore) DBStats() (*DBStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &DBStats{Driver: "memory", Endpoints: len(m.endpoints), LastCleanup: m.cleanups.stats()}
+	for _, records := range m.history {
+		stats.HistoryRecords += len(records)
+	}
+	return stats, nil
+}
+
+// SaveEndpoint saves or updates an endpoint
+func (m *MemoryStore) SaveEndpoint(endpoint *StoredEndpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	endpoint.applyDefaults()
+
+	data, err := json.Marshal(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal endpoint: %w", err)
+	}
+	m.endpoints[endpoint.ID] = data
+	return nil
+}
+
+// GetEndpoint retrieves an endpoint by ID
+func (m *MemoryStore) GetEndpoint(id string) (*StoredEndpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.endpoints[id]
+	if !ok {
+		return nil, fmt.Errorf("endpoint not found: %s", id)
+	}
+	var endpoint StoredEndpoint
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// GetAllEndpoints retrieves all endpoints, ordered by ID like the Bolt bucket
+func (m *MemoryStore) GetAllEndpoints() ([]*StoredEndpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.endpoints))
+	for id := range m.endpoints {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var endpoints []*StoredEndpoint
+	for _, id := range ids {
+		var endpoint StoredEndpoint
+		if err := json.Unmarshal(m.endpoints[id], &endpoint); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+	return endpoints, nil
+}
+
+// GetEnabledEndpoints retrieves only enabled endpoints
+func (m *MemoryStore) GetEnabledEndpoints() ([]*StoredEndpoint, error) {
+	all, err := m.GetAllEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var enabled []*StoredEndpoint
+	for _, ep := range all {
+		if ep.Enabled {
+			enabled = append(enabled, ep)
+		}
+	}
+	return enabled, nil
+}
+
+// FindEndpointByURL returns the endpoint monitoring url, or nil if there is none
+func (m *MemoryStore) FindEndpointByURL(url string) (*StoredEndpoint, error) {
+	all, err := m.GetAllEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range all {
+		if ep.URL == url {
+			return ep, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteEndpoint removes an endpoint
+func (m *MemoryStore) DeleteEndpoint(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.endpoints, id)
+	return nil
+}
+
+// updateEndpoint loads an endpoint, applies fn and saves it back
+func (m *MemoryStore) updateEndpoint(id string, fn func(*StoredEndpoint)) error {
+	endpoint, err := m.GetEndpoint(id)
+	if err != nil {
+		return err
+	}
+	fn(endpoint)
+	return m.SaveEndpoint(endpoint)
+}
+
+// EnableEndpoint enables an endpoint
+func (m *MemoryStore) EnableEndpoint(id string) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) { e.Enabled = true })
+}
+
+// DisableEndpoint disables an endpoint
+func (m *MemoryStore) DisableEndpoint(id string) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) { e.Enabled = false })
+}
+
+// SuppressAlerts suppresses alerts for an endpoint
+func (m *MemoryStore) SuppressAlerts(id string) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) { e.AlertsSuppressed = true })
+}
+
+// UnsuppressAlerts enables alerts for an endpoint
+func (m *MemoryStore) UnsuppressAlerts(id string) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) { e.AlertsSuppressed = false })
+}
+
+// SnoozeAlerts silences alerts for an endpoint until the given time
+func (m *MemoryStore) SnoozeAlerts(id string, until time.Time) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) { e.SnoozeUntil = until })
+}
+
+// AcknowledgeIncident marks the current incident for an endpoint as acknowledged
+func (m *MemoryStore) AcknowledgeIncident(id string, at time.Time) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) {
+		e.Acknowledged = true
+		e.AcknowledgedAt = at
+	})
+}
+
+// ClearAcknowledgement clears the incident acknowledgement for an endpoint
+func (m *MemoryStore) ClearAcknowledgement(id string) error {
+	return m.updateEndpoint(id, func(e *StoredEndpoint) {
+		e.Acknowledged = false
+		e.AcknowledgedAt = time.Time{}
+	})
+}
+
+// SaveHealthCheckRecord saves a health check result to history
+func (m *MemoryStore) SaveHealthCheckRecord(record *HealthCheckRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.saveRecord(record)
+	return nil
+}
+
+// SaveHealthCheckRecords saves several health check results at once
+func (m *MemoryStore) SaveHealthCheckRecords(records []*HealthCheckRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range records {
+		m.saveRecord(record)
+	}
+	return nil
+}
+
+// saveRecord inserts a copy of record into its endpoint's history. Caller
+// must hold m.mu.
+func (m *MemoryStore) saveRecord(record *HealthCheckRecord) {
+	copied := *record
+	records := m.history[record.EndpointID]
+
+	// Keep records sorted by timestamp; a record with the same timestamp replaces
+	// the existing one, matching the Bolt key scheme
+	i := sort.Search(len(records), func(i int) bool {
+		return !records[i].Timestamp.Before(copied.Timestamp)
+	})
+	if i < len(records) && records[i].Timestamp.Equal(copied.Timestamp) {
+		records[i] = &copied
+		return
+	}
+	records = append(records, nil)
+	copy(records[i+1:], records[i:])
+	records[i] = &copied
+	m.history[record.EndpointID] = records
+}
+
+// GetHealthHistory retrieves health check history for an endpoint, newest first,
+// along with the total number of records matching the time range
+func (m *MemoryStore) GetHealthHistory(endpointID string, query HistoryQuery) ([]*HealthCheckRecord, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var records []*HealthCheckRecord
+	total := 0
+
+	stored := m.history[endpointID]
+	for i := len(stored) - 1; i >= 0; i-- {
+		record := stored[i]
+		if !query.To.IsZero() && record.Timestamp.After(query.To) {
+			continue
+		}
+		if !query.From.IsZero() && record.Timestamp.Before(query.From) {
+			break
+		}
+		if !query.matches(record) {
+			continue
+		}
+
+		total++
+		if total <= query.Offset || (query.Limit > 0 && len(records) >= query.Limit) {
+			continue
+		}
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	return records, total, nil
+}
+
+// StreamHealthHistory calls fn for each of an endpoint's history records
+// between from and to (zero for unbounded), oldest first, stopping at the
+// first error fn returns. fn is called without the store lock held.
+func (m *MemoryStore) StreamHealthHistory(endpointID string, from, to time.Time, fn func(*HealthCheckRecord) error) error {
+	after := from.Add(-1)
+	for {
+		records := m.historyChunk(endpointID, after, to)
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if len(records) < historyStreamChunk {
+			return nil
+		}
+		after = records[len(records)-1].Timestamp
+	}
+}
+
+// historyChunk copies up to historyStreamChunk of an endpoint's records
+// timestamped after after and not after to
+func (m *MemoryStore) historyChunk(endpointID string, after, to time.Time) []*HealthCheckRecord {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored := m.history[endpointID]
+	i := sort.Search(len(stored), func(i int) bool { return stored[i].Timestamp.After(after) })
+
+	var records []*HealthCheckRecord
+	for ; i < len(stored) && len(records) < historyStreamChunk; i++ {
+		if !to.IsZero() && stored[i].Timestamp.After(to) {
+			break
+		}
+		copied := *stored[i]
+		records = append(records, &copied)
+	}
+	return records
+}
+
+// GetHistoryStats aggregates all history records, counting those at or after since in the window
+func (m *MemoryStore) GetHistoryStats(since time.Time) (*HistoryStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &HistoryStats{WindowByEndpoint: make(map[string]*WindowCounts)}
+	for _, records := range m.history {
+		for _, record := range records {
+			stats.TotalChecks++
+			if !record.Timestamp.Before(since) {
+				stats.addWindowCheck(record.EndpointID, record.Status == string(StatusHealthy), record.ResponseTime)
+			}
+		}
+	}
+	return stats, nil
+}
+
+// ClearHistory deletes all health check records for an endpoint
+func (m *MemoryStore) ClearHistory(endpointID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deletedCount := len(m.history[endpointID])
+	delete(m.history, endpointID)
+	log.Printf("Cleared %d health check records for endpoint: %s", deletedCount, endpointID)
+	return nil
+}
+
+// GetSetting returns the value stored under key, or nil if unset
+func (m *MemoryStore) GetSetting(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.settings[key], nil
+}
+
+// SaveSetting stores value under key
+func (m *MemoryStore) SaveSetting(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settings[key] = value
+	return nil
+}
+
+// CleanupOldData removes data older than the retention period and enforces
+// the per-endpoint cap, returning how many records it deleted
+func (m *MemoryStore) CleanupOldData() (deletedCount int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Records are kept decoded, so none can be skipped
+	defer func() { m.cleanups.record(deletedCount, 0, err) }()
+
+	cutoff := time.Now().AddDate(0, 0, -DataRetentionDays)
+
+	for endpointID, records := range m.history {
+		keep := sort.Search(len(records), func(i int) bool {
+			return !records[i].Timestamp.Before(cutoff)
+		})
+		if limit := m.config.MaxRecordsPerEndpoint; limit > 0 && len(records)-keep > limit {
+			keep = len(records) - limit
+		}
+		if keep == 0 {
+			continue
+		}
+		if dir := m.config.ArchiveDir; dir != "" {
+			if err := archiveRecords(dir, records[:keep]); err != nil {
+				return deletedCount, err
+			}
+		}
+		deletedCount += keep
+		if keep == len(records) {
+			delete(m.history, endpointID)
+			continue
+		}
+		m.history[endpointID] = append([]*HealthCheckRecord(nil), records[keep:]...)
+	}
+
+	if deletedCount > 0 {
+		log.Printf("Cleaned up %d old health check records (older than %d days or over per-endpoint cap)", deletedCount, DataRetentionDays)
+	}
+	return deletedCount, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkDurationBuckets are the upper bounds, in seconds, of the
+// cronzee_check_duration_seconds histogram buckets
+var checkDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// durationHistogram counts one endpoint's check durations into
+// checkDurationBuckets
+type durationHistogram struct {
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64 // seconds
+}
+
+// observe adds a check duration to the histogram
+func (h *durationHistogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts == nil {
+		h.counts = make([]uint64, len(checkDurationBuckets))
+	}
+	if i := sort.SearchFloat64s(checkDurationBuckets, seconds); i < len(checkDurationBuckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// observeCheckDuration records how long a check of an endpoint took, across
+// all of its URLs
+func (m *Monitor) observeCheckDuration(id string, d time.Duration) {
+	v, _ := m.durations.LoadOrStore(id, &durationHistogram{})
+	v.(*durationHistogram).observe(d)
+}
+
+// writeMetrics writes the monitor's metrics in the Prometheus text format.
+// Series are labelled with the endpoint's ID and current name, and only
+// endpoints that still exist are included.
+func (m *Monitor) writeMetrics(w io.Writer) {
+	type series struct {
+		id, name string
+		h        *durationHistogram
+	}
+	var all []series
+	for id, state := range m.GetStatus() {
+		v, ok := m.durations.Load(id)
+		if !ok {
+			continue
+		}
+		state.mu.RLock()
+		name := state.Endpoint.Name
+		state.mu.RUnlock()
+		all = append(all, series{id: id, name: name, h: v.(*durationHistogram)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].id < all[j].id })
+
+	fmt.Fprintln(w, "# HELP cronzee_check_duration_seconds Time taken by endpoint checks, including failover URLs.")
+	fmt.Fprintln(w, "# TYPE cronzee_check_duration_seconds histogram")
+	for _, s := range all {
+		labels := fmt.Sprintf(`endpoint_id="%s",endpoint="%s"`, escapeLabelValue(s.id), escapeLabelValue(s.name))
+		s.h.mu.Lock()
+		var cumulative uint64
+		for i, bound := range checkDurationBuckets {
+			cumulative += s.h.counts[i]
+			fmt.Fprintf(w, "cronzee_check_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "cronzee_check_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.h.count)
+		fmt.Fprintf(w, "cronzee_check_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(s.h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "cronzee_check_duration_seconds_count{%s} %d\n", labels, s.h.count)
+		s.h.mu.Unlock()
+	}
+}
+
+// labelValueEscaper escapes a Prometheus label value
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a string for use as a Prometheus label value
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// handleMetrics serves the monitor's metrics for Prometheus to scrape
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.monitor.writeMetrics(w)
+}
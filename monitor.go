@@ -1,14 +1,29 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// schedulerTick is how often the monitor loop looks for endpoints that are due
+const schedulerTick = 5 * time.Second
+
 // HealthStatus represents the health status of an endpoint
 type HealthStatus string
 
@@ -30,27 +45,59 @@ type EndpointState struct {
 	LastError          string
 	Enabled            bool
 	AlertsSuppressed   bool
+	ActiveURL          string // which of the endpoint's URLs answered the latest check
+	RemoteAddr         string // IP address the latest check reached
+	StatusCode         int    // HTTP status code of the latest check, 0 if it got none
+	SnoozeUntil        time.Time
+	Acknowledged       bool
+	AcknowledgedAt     time.Time
+	Flapping           bool
+	Degraded           bool            // responding, but much slower than its baseline
+	recentTimes        []time.Duration // response times of the last passed checks
+	transitions        []time.Time // recent status changes, for flap detection
+	firstFailureTime   time.Time   // start of the current run of failed checks
+	lastPing           time.Time   // latest heartbeat, for heartbeat endpoints
 	ID                 string
 	CheckInterval      time.Duration
 	NextCheck          time.Time
 	mu                 sync.RWMutex
 }
 
+// statusSnapshot is a lock-free copy of an endpoint's name and status, used to
+// check dependency health while another endpoint's state is locked
+type statusSnapshot struct {
+	Name   string
+	Status HealthStatus
+}
+
 // Monitor manages health checks for multiple endpoints
 type Monitor struct {
-	config    *Config
-	states    map[string]*EndpointState
-	alerter   *Alerter
-	db        *Database
-	ticker    *time.Ticker
-	ctx       context.Context
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
-	mu        sync.RWMutex
+	config     *Config
+	states     map[string]*EndpointState
+	statuses   sync.Map // endpoint ID -> statusSnapshot, readable without state locks
+	transports sync.Map // transportKey -> *http.Transport, shared so connections are reused
+	uptime     sync.Map // endpoint ID -> float64 uptime percent over uptimeWindow
+	compliance sync.Map // endpoint ID -> float64 SLO compliance percent over uptimeWindow
+	baselines  sync.Map // endpoint ID -> time.Duration baseline response time
+	durations  sync.Map // endpoint ID -> *durationHistogram of check durations
+	alerter    *Alerter
+	db         Store
+	records    *recordWriter
+	exporters  []Exporter
+	tracer     *tracer
+	ticker     *time.Ticker
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+
+	running      atomic.Bool
+	lastTick     atomic.Int64 // unix nanoseconds of the last scheduler pass
+	activeChecks atomic.Int32
 }
 
 // NewMonitor creates a new health monitor
-func NewMonitor(config *Config, db *Database) *Monitor {
+func NewMonitor(config *Config, db Store) *Monitor {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	monitor := &Monitor{
@@ -61,6 +108,13 @@ func NewMonitor(config *Config, db *Database) *Monitor {
 		ctx:     ctx,
 		cancel:  cancel,
 	}
+	if db != nil {
+		monitor.records = newRecordWriter(db)
+	}
+	monitor.exporters = newExporters(config.Exporters)
+	monitor.tracer = newTracer(config.Exporters.OTLP)
+
+	monitor.alerter.dependencyDown = monitor.unhealthyDependency
 
 	// Initialize endpoint states from database
 	monitor.loadEndpointsFromDB()
@@ -80,9 +134,14 @@ func (m *Monitor) loadEndpointsFromDB() {
 	}
 
 	for _, stored := range endpoints {
-		checkInterval := stored.CheckInterval
-		if checkInterval == 0 {
-			checkInterval = m.config.CheckInterval
+		// Heartbeat endpoints saved before pings needed a token get one now
+		if stored.Type == CheckTypeHeartbeat && stored.HeartbeatToken == "" {
+			if err := m.db.SaveEndpoint(stored); err != nil {
+				log.Printf("Error saving heartbeat token for %s: %v", stored.Name, err)
+			} else {
+				log.Printf("Generated a heartbeat token for %s; its job must now ping %s%s/%s",
+					stored.Name, heartbeatPath, stored.ID, stored.HeartbeatToken)
+			}
 		}
 		m.states[stored.ID] = &EndpointState{
 			ID:               stored.ID,
@@ -91,7 +150,10 @@ func (m *Monitor) loadEndpointsFromDB() {
 			LastCheck:        time.Now(),
 			Enabled:          stored.Enabled,
 			AlertsSuppressed: stored.AlertsSuppressed,
-			CheckInterval:    checkInterval,
+			SnoozeUntil:      stored.SnoozeUntil,
+			Acknowledged:     stored.Acknowledged,
+			AcknowledgedAt:   stored.AcknowledgedAt,
+			CheckInterval:    m.config.checkInterval(stored),
 			NextCheck:        time.Now(),
 		}
 	}
@@ -103,17 +165,168 @@ func (m *Monitor) ReloadEndpoints() {
 	log.Printf("Reloaded %d endpoints from database", len(m.states))
 }
 
+// ReloadConfig applies a freshly loaded configuration. Alerting and the default
+// check interval take effect immediately; server and storage settings need a restart.
+func (m *Monitor) ReloadConfig(config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	old := m.config
+	m.config = config
+	m.mu.Unlock()
+
+	m.alerter.UpdateConfig(&config.Alerting)
+
+	if !reflect.DeepEqual(old.Server, config.Server) {
+		log.Printf("Server settings changed; restart required to apply them")
+	}
+	if old.Storage != config.Storage {
+		log.Printf("Storage settings changed; restart required to apply them")
+	}
+	if config.CheckInterval != old.CheckInterval {
+		m.applyCheckInterval()
+	}
+
+	log.Printf("Configuration reloaded (check interval: %s)", config.CheckInterval)
+	return nil
+}
+
+// applyCheckInterval moves the endpoints without an interval of their own onto
+// the current check_interval, bringing forward any check now due sooner
+func (m *Monitor) applyCheckInterval() {
+	endpoints, err := m.db.GetAllEndpoints()
+	if err != nil {
+		log.Printf("Error applying check_interval: %v", err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, stored := range endpoints {
+		state, ok := m.states[stored.ID]
+		if !ok || stored.CheckInterval > 0 {
+			continue
+		}
+		state.mu.Lock()
+		state.CheckInterval = m.config.CheckInterval
+		if next := state.LastCheck.Add(state.CheckInterval); next.Before(state.NextCheck) {
+			state.NextCheck = next
+		}
+		state.mu.Unlock()
+	}
+}
+
+// currentConfig returns the active configuration, which ReloadConfig may swap
+func (m *Monitor) currentConfig() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
+}
+
+// userAgent returns the User-Agent for HTTP checks of an endpoint: its own
+// setting, then the global default, then Cronzee/<version>
+func (m *Monitor) userAgent(endpoint Endpoint) string {
+	if endpoint.UserAgent != "" {
+		return endpoint.UserAgent
+	}
+	if ua := m.currentConfig().UserAgent; ua != "" {
+		return ua
+	}
+	return "Cronzee/" + version
+}
+
+// requestHeaders returns the custom headers for HTTP checks of an endpoint:
+// defaults.headers merged with its own, which win on conflict
+func (m *Monitor) requestHeaders(endpoint Endpoint) map[string]string {
+	defaults := m.currentConfig().Defaults.Headers
+	if len(defaults) == 0 {
+		return endpoint.Headers
+	}
+	headers := make(map[string]string, len(defaults)+len(endpoint.Headers))
+	for key, value := range defaults {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	for key, value := range endpoint.Headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	return headers
+}
+
+// transportKey identifies the transport settings an HTTP check needs
+type transportKey struct {
+	proxy             string
+	connectTimeout    time.Duration
+	addressFamily     string
+	resolveOverride   string
+	disableKeepAlives bool
+}
+
+// transport returns the round tripper for HTTP checks of an endpoint, routed
+// through its proxy or the global one, dialing with its connect timeout, only
+// its address family and its resolve override, and optionally without
+// keep-alives. Endpoints with the same settings share a transport. Without a proxy, HTTP_PROXY, HTTPS_PROXY and NO_PROXY apply.
+func (m *Monitor) transport(endpoint Endpoint) (http.RoundTripper, error) {
+	key := transportKey{
+		proxy:             endpoint.ProxyURL,
+		connectTimeout:    endpoint.ConnectTimeout,
+		addressFamily:     endpoint.AddressFamily,
+		resolveOverride:   endpoint.ResolveOverride,
+		disableKeepAlives: endpoint.DisableKeepAlives,
+	}
+	// An override says where to connect, so it goes direct, ignoring the
+	// global proxy and HTTP_PROXY and HTTPS_PROXY
+	if key.proxy == "" && key.resolveOverride == "" {
+		key.proxy = m.currentConfig().ProxyURL
+	}
+	if key == (transportKey{}) {
+		return http.DefaultTransport, nil
+	}
+	if t, ok := m.transports.Load(key); ok {
+		return t.(*http.Transport), nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if key.proxy != "" {
+		proxy, err := parseProxyURL(key.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	if key.resolveOverride != "" {
+		t.Proxy = nil
+	}
+	if key.connectTimeout > 0 || key.addressFamily != "" || key.resolveOverride != "" {
+		dialer := &net.Dialer{Timeout: key.connectTimeout, KeepAlive: 30 * time.Second}
+		if key.connectTimeout == 0 {
+			dialer.Timeout = 30 * time.Second
+		}
+		overrideHost, overrideIP, err := parseResolveOverride(key.resolveOverride)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resolve_override: %w", err)
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			// Only the dialed address changes; the request keeps its Host
+			// header and TLS server name
+			if host, port, err := net.SplitHostPort(addr); err == nil && overrideHost != "" && strings.EqualFold(host, overrideHost) {
+				addr = net.JoinHostPort(overrideIP, port)
+			}
+			return dialer.DialContext(ctx, familyNetwork(network, key.addressFamily), addr)
+		}
+	}
+	t.DisableKeepAlives = key.disableKeepAlives
+	actual, _ := m.transports.LoadOrStore(key, t)
+	return actual.(*http.Transport), nil
+}
+
 // AddEndpoint adds a new endpoint to monitoring
 func (m *Monitor) AddEndpoint(stored *StoredEndpoint) error {
 	if err := m.db.SaveEndpoint(stored); err != nil {
 		return err
 	}
 
-	checkInterval := stored.CheckInterval
-	if checkInterval == 0 {
-		checkInterval = m.config.CheckInterval
-	}
-
 	m.mu.Lock()
 	m.states[stored.ID] = &EndpointState{
 		ID:               stored.ID,
@@ -122,7 +335,8 @@ func (m *Monitor) AddEndpoint(stored *StoredEndpoint) error {
 		LastCheck:        time.Now(),
 		Enabled:          stored.Enabled,
 		AlertsSuppressed: stored.AlertsSuppressed,
-		CheckInterval:    checkInterval,
+		SnoozeUntil:      stored.SnoozeUntil,
+		CheckInterval:    m.config.checkInterval(stored),
 		NextCheck:        time.Now(),
 	}
 	m.mu.Unlock()
@@ -133,31 +347,16 @@ func (m *Monitor) AddEndpoint(stored *StoredEndpoint) error {
 
 // RemoveEndpoint removes an endpoint from monitoring
 func (m *Monitor) RemoveEndpoint(id string) error {
-	log.Printf("RemoveEndpoint called with id: %s", id)
-	
-	// Log current states before deletion
-	m.mu.RLock()
-	log.Printf("Current states keys: %v", func() []string {
-		keys := make([]string, 0, len(m.states))
-		for k := range m.states {
-			keys = append(keys, k)
-		}
-		return keys
-	}())
-	_, exists := m.states[id]
-	log.Printf("Endpoint %s exists in states: %v", id, exists)
-	m.mu.RUnlock()
-	
 	if err := m.db.DeleteEndpoint(id); err != nil {
-		log.Printf("Error deleting from DB: %v", err)
 		return err
 	}
-	log.Printf("Deleted from DB: %s", id)
 
 	m.mu.Lock()
 	delete(m.states, id)
-	log.Printf("Deleted from states map: %s, remaining count: %d", id, len(m.states))
+	m.statuses.Delete(id)
+	m.durations.Delete(id)
 	m.mu.Unlock()
+	m.alerter.resolveAlertmanagerAlert(id)
 
 	log.Printf("Removed endpoint: %s", id)
 	return nil
@@ -194,6 +393,9 @@ func (m *Monitor) DisableEndpoint(id string) error {
 		state.mu.Unlock()
 	}
 	m.mu.Unlock()
+	// A disabled parent no longer holds back its dependents' alerts
+	m.statuses.Delete(id)
+	m.alerter.resolveAlertmanagerAlert(id)
 
 	log.Printf("Disabled endpoint: %s", id)
 	return nil
@@ -217,6 +419,201 @@ func (m *Monitor) SuppressAlerts(id string) error {
 	return nil
 }
 
+// SnoozeAlerts silences alerts for an endpoint for the given duration, after
+// which they resume on their own. A zero duration ends an active snooze.
+func (m *Monitor) SnoozeAlerts(id string, d time.Duration) (time.Time, error) {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	if err := m.db.SnoozeAlerts(id, until); err != nil {
+		return time.Time{}, err
+	}
+
+	m.mu.Lock()
+	if state, ok := m.states[id]; ok {
+		state.mu.Lock()
+		state.SnoozeUntil = until
+		state.mu.Unlock()
+	}
+	m.mu.Unlock()
+
+	if until.IsZero() {
+		log.Printf("Ended alert snooze for endpoint: %s", id)
+	} else {
+		log.Printf("Snoozed alerts for endpoint %s until %s", id, until.Format(time.RFC3339))
+	}
+	return until, nil
+}
+
+// AcknowledgeEndpoint acknowledges the active incident for an unhealthy endpoint,
+// silencing repeat alerts until it recovers
+func (m *Monitor) AcknowledgeEndpoint(id string) error {
+	m.mu.RLock()
+	state, ok := m.states[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("endpoint not found: %s", id)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.Status != StatusUnhealthy {
+		return fmt.Errorf("endpoint %s has no active incident", id)
+	}
+
+	now := time.Now()
+	if err := m.db.AcknowledgeIncident(id, now); err != nil {
+		return err
+	}
+	state.Acknowledged = true
+	state.AcknowledgedAt = now
+
+	log.Printf("Acknowledged incident for endpoint: %s", id)
+	return nil
+}
+
+// errHeartbeatNotFound is returned for a ping that doesn't match a heartbeat
+// endpoint's ID and token
+var errHeartbeatNotFound = errors.New("heartbeat endpoint not found or wrong token")
+
+// RecordHeartbeat records a ping from a heartbeat endpoint's job as a passed
+// check, which also pushes its deadline a full check interval out. The ping
+// must carry the endpoint's heartbeat token; errHeartbeatNotFound covers an
+// unknown ID, a non-heartbeat endpoint and a wrong token alike.
+func (m *Monitor) RecordHeartbeat(id, token string) error {
+	m.mu.RLock()
+	state, ok := m.states[id]
+	m.mu.RUnlock()
+	if !ok {
+		return errHeartbeatNotFound
+	}
+
+	state.mu.Lock()
+	valid := state.Endpoint.Type == CheckTypeHeartbeat && state.Endpoint.HeartbeatToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(state.Endpoint.HeartbeatToken)) == 1
+	enabled := state.Enabled
+	if valid {
+		state.lastPing = time.Now()
+	}
+	state.mu.Unlock()
+
+	if !valid {
+		return errHeartbeatNotFound
+	}
+	if enabled {
+		m.handleCheckSuccess(state, 0)
+	}
+	return nil
+}
+
+// CheckEndpoints checks the given endpoints right away, in parallel, and returns
+// once every check has finished. Disabled endpoints are skipped.
+func (m *Monitor) CheckEndpoints(ids []string) error {
+	states := make([]*EndpointState, 0, len(ids))
+	m.mu.RLock()
+	for _, id := range ids {
+		state, ok := m.states[id]
+		if !ok {
+			m.mu.RUnlock()
+			return fmt.Errorf("endpoint not found: %s", id)
+		}
+		states = append(states, state)
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, state := range states {
+		state.mu.RLock()
+		enabled := state.Enabled
+		state.mu.RUnlock()
+		if !enabled {
+			continue
+		}
+
+		wg.Add(1)
+		go func(s *EndpointState) {
+			defer wg.Done()
+			m.checkEndpoint(s)
+		}(state)
+	}
+	wg.Wait()
+	return nil
+}
+
+// ResetEndpoint clears an endpoint's consecutive counters and returns it to unknown status
+func (m *Monitor) ResetEndpoint(id string) error {
+	m.mu.RLock()
+	state, ok := m.states[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("endpoint not found: %s", id)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if err := m.resetState(state); err != nil {
+		return err
+	}
+	log.Printf("Reset endpoint: %s", id)
+	return nil
+}
+
+// ResolveIncident closes the active incident of an unhealthy endpoint, e.g.
+// from an external incident tool. Like a reset, it clears the counters and
+// acknowledgement, so an endpoint that is still failing opens a new incident
+// and alerts again once it reaches its failure threshold.
+func (m *Monitor) ResolveIncident(id string) error {
+	m.mu.RLock()
+	state, ok := m.states[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("endpoint not found: %s", id)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.Status != StatusUnhealthy {
+		return fmt.Errorf("endpoint %s has no active incident", id)
+	}
+	if err := m.resetState(state); err != nil {
+		return err
+	}
+	log.Printf("Resolved incident for endpoint: %s", id)
+	return nil
+}
+
+// resetState clears an endpoint's counters, status and acknowledgement.
+// Caller must hold state.mu.
+func (m *Monitor) resetState(state *EndpointState) error {
+	id := state.ID
+	if state.Acknowledged {
+		if err := m.db.ClearAcknowledgement(id); err != nil {
+			return err
+		}
+		state.Acknowledged = false
+		state.AcknowledgedAt = time.Time{}
+	}
+
+	state.ConsecutiveFailures = 0
+	state.ConsecutiveSuccesses = 0
+	state.Status = StatusUnknown
+	state.LastError = ""
+	state.LastStatusChange = time.Now()
+	state.Flapping = false
+	state.transitions = nil
+	state.Degraded = false
+	state.recentTimes = nil
+	m.statuses.Delete(id)
+	m.alerter.clearSent(id)
+	m.alerter.releaseHeld(id)
+	m.alerter.resolveAlertmanagerAlert(id)
+	return nil
+}
+
 // UpdateEndpointSettings updates endpoint settings in the monitor state
 func (m *Monitor) UpdateEndpointSettings(id string, stored *StoredEndpoint) {
 	m.mu.Lock()
@@ -224,10 +621,13 @@ func (m *Monitor) UpdateEndpointSettings(id string, stored *StoredEndpoint) {
 
 	if state, ok := m.states[id]; ok {
 		state.mu.Lock()
-		state.Endpoint.Timeout = stored.Timeout
-		state.Endpoint.FailureThreshold = stored.FailureThreshold
-		state.Endpoint.SuccessThreshold = stored.SuccessThreshold
-		state.CheckInterval = stored.CheckInterval
+		state.Endpoint = stored.ToEndpoint()
+		state.ActiveURL = ""
+		state.RemoteAddr = ""
+		state.StatusCode = 0
+		state.Enabled = stored.Enabled
+		state.AlertsSuppressed = stored.AlertsSuppressed
+		state.CheckInterval = m.config.checkInterval(stored)
 		state.mu.Unlock()
 		log.Printf("Updated endpoint settings: %s", id)
 	}
@@ -254,20 +654,59 @@ func (m *Monitor) UnsuppressAlerts(id string) error {
 // Start begins monitoring all endpoints
 func (m *Monitor) Start() {
 	// Use a faster ticker (5 seconds) to check if any endpoint needs checking
-	m.ticker = time.NewTicker(5 * time.Second)
+	m.ticker = time.NewTicker(schedulerTick)
 	
 	// Perform initial check
+	m.running.Store(true)
+	m.lastTick.Store(time.Now().UnixNano())
 	m.checkAllEndpoints()
 
+	// Write health check records in batches
+	if m.records != nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.records.run(m.ctx)
+		}()
+	}
+	for _, exporter := range m.exporters {
+		m.wg.Add(1)
+		go func(exporter Exporter) {
+			defer m.wg.Done()
+			exporter.run(m.ctx)
+		}(exporter)
+	}
+	if m.tracer != nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.tracer.run(m.ctx)
+		}()
+	}
+
+	// Send scheduled uptime reports
+	m.wg.Add(1)
+	go m.runSummaryReports()
+
+	// Keep the rolling uptime figures for the status API current
+	m.wg.Add(1)
+	go m.runUptimeRefresh()
+
+	// Track response time baselines for regression detection
+	m.wg.Add(1)
+	go m.runBaselineRefresh()
+
 	// Start periodic checks
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
+		defer m.running.Store(false)
 		for {
 			select {
 			case <-m.ctx.Done():
 				return
 			case <-m.ticker.C:
+				m.lastTick.Store(time.Now().UnixNano())
 				m.checkDueEndpoints()
 			}
 		}
@@ -281,6 +720,17 @@ func (m *Monitor) Stop() {
 	}
 	m.cancel()
 	m.wg.Wait()
+
+	// Write whatever the last checks recorded before the store is closed
+	if m.records != nil {
+		m.records.Flush()
+	}
+	for _, exporter := range m.exporters {
+		exporter.Flush()
+	}
+	if m.tracer != nil {
+		m.tracer.Flush()
+	}
 }
 
 // checkAllEndpoints checks all configured endpoints (used for initial check)
@@ -335,49 +785,287 @@ func (m *Monitor) checkDueEndpoints() {
 	wg.Wait()
 }
 
-// checkEndpoint performs a health check on a single endpoint
+// checkResult is the outcome of checking one address of an endpoint
+type checkResult struct {
+	responseTime time.Duration
+	err          string // empty if the check passed
+	body         []byte // response body, kept for a failure snapshot
+	remoteAddr   string // IP address the check reached, if it got that far
+	statusCode   int    // HTTP status code, if the check got a response
+}
+
+// checkEndpoint performs a health check on a single endpoint using its check
+// type. With several URLs they are tried in order and the first one that
+// passes makes the endpoint healthy; the check fails only if all of them do.
 func (m *Monitor) checkEndpoint(state *EndpointState) {
+	m.activeChecks.Add(1)
+	defer m.activeChecks.Add(-1)
+
+	if state.Endpoint.Type == CheckTypeHeartbeat {
+		m.checkHeartbeat(state)
+		return
+	}
+
+	probe := m.prober(state.Endpoint.Type)
+	targets := state.Endpoint.Targets()
+	var result checkResult
+	var failures []string
+	var body []byte // from the last URL that responded, for the failure snapshot
+	start := time.Now()
+	defer func() { m.observeCheckDuration(state.ID, time.Since(start)) }()
+	span := m.tracer.start(state.ID, state.Endpoint)
+	endpoint := span.propagate(state.Endpoint)
+	endpoint.Timeout = m.currentConfig().checkTimeout(endpoint)
+	for _, target := range targets {
+		result = probe(endpoint, target)
+		if result.err == "" {
+			span.end(target, result, "")
+			m.setCheckedAddress(state, targets, target, result)
+			m.handleCheckSuccess(state, result.responseTime)
+			return
+		}
+		// A check cut short by Stop says nothing about the endpoint
+		if m.ctx.Err() != nil {
+			span.end(target, result, "check cancelled by shutdown")
+			return
+		}
+		if len(targets) > 1 {
+			result.err = target + ": " + result.err
+		}
+		failures = append(failures, result.err)
+		if result.body != nil {
+			body = result.body
+		}
+	}
+
+	cfg := m.currentConfig()
+	errorMsg := redactSecrets(strings.Join(failures, "; "), m.requestHeaders(state.Endpoint), cfg.RedactHeaders, cfg.redactPattern)
+	span.end(targets[len(targets)-1], result, errorMsg)
+	m.setCheckedAddress(state, targets, "", result)
+	m.handleCheckFailure(state, errorMsg, result.responseTime, body)
+}
+
+// prober returns the function that checks a single URL for checkType.
+// Heartbeat endpoints have none; they are checked by checkHeartbeat.
+func (m *Monitor) prober(checkType string) func(Endpoint, string) checkResult {
+	switch checkType {
+	case CheckTypeDNS:
+		return m.probeDNS
+	case CheckTypePing:
+		return m.probePing
+	case CheckTypeWebSocket:
+		return m.probeWebSocket
+	}
+	return m.probeHTTP
+}
+
+// setCheckedAddress records the IP address the latest check reached, the
+// status code it got, and which of an endpoint's URLs answered it. The URL is
+// left empty for endpoints with a single URL.
+func (m *Monitor) setCheckedAddress(state *EndpointState, targets []string, target string, result checkResult) {
+	state.mu.Lock()
+	if len(targets) > 1 {
+		state.ActiveURL = target
+	}
+	state.RemoteAddr = result.remoteAddr
+	state.StatusCode = result.statusCode
+	state.mu.Unlock()
+}
+
+// probeHTTP performs an HTTP request against target
+func (m *Monitor) probeHTTP(endpoint Endpoint, target string) (result checkResult) {
 	start := time.Now()
 	
-	ctx, cancel := context.WithTimeout(m.ctx, state.Endpoint.Timeout)
+	ctx, cancel := context.WithTimeout(m.ctx, endpoint.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, state.Endpoint.Method, state.Endpoint.URL, nil)
+	// GraphQL queries always go out as a JSON POST
+	method, reqBody := strings.ToUpper(endpoint.Method), io.Reader(nil)
+	if endpoint.Body != "" {
+		reqBody = strings.NewReader(endpoint.Body)
+	}
+	if endpoint.Type == CheckTypeGraphQL {
+		query := endpoint.GraphQLQuery
+		if query == "" {
+			query = defaultGraphQLQuery
+		}
+		payload, _ := json.Marshal(map[string]string{"query": query})
+		method, reqBody = http.MethodPost, bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
 	if err != nil {
-		m.handleCheckFailure(state, fmt.Sprintf("failed to create request: %v", err), 0)
-		return
+		return checkResult{err: fmt.Sprintf("failed to create request: %v", err)}
+	}
+
+	// Follow the request so a timeout can be attributed to a phase, and note
+	// which address it went to
+	var trace requestTrace
+	var statusCode int
+	req = trace.trace(req)
+	defer func() {
+		result.remoteAddr = trace.address()
+		result.statusCode = statusCode
+	}()
+
+	// A User-Agent in the custom headers still takes precedence
+	req.Header.Set("User-Agent", m.userAgent(endpoint))
+	if endpoint.Type == CheckTypeGraphQL {
+		req.Header.Set("Content-Type", "application/json")
+	} else if endpoint.Body != "" {
+		// A Content-Type in the custom headers still takes precedence
+		if json.Valid([]byte(endpoint.Body)) {
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		}
 	}
 
 	// Add custom headers
-	for key, value := range state.Endpoint.Headers {
+	for key, value := range m.requestHeaders(endpoint) {
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{
-		Timeout: state.Endpoint.Timeout,
+	transport, err := m.transport(endpoint)
+	if err != nil {
+		return checkResult{err: err.Error()}
 	}
 
+	// The request's context carries the timeout, so Stop cancelling m.ctx
+	// aborts the check at once; a client timeout would only duplicate it
+	client := &http.Client{Transport: transport, CheckRedirect: redirectPolicy(endpoint.MaxRedirects)}
+
 	resp, err := client.Do(req)
 	responseTime := time.Since(start)
 
 	if err != nil {
-		m.handleCheckFailure(state, fmt.Sprintf("request failed: %v", err), responseTime)
-		return
+		return checkResult{responseTime: responseTime, err: describeRequestError(err, endpoint, &trace)}
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
-	if resp.StatusCode != state.Endpoint.ExpectedStatus {
-		m.handleCheckFailure(state, 
-			fmt.Sprintf("unexpected status code: got %d, expected %d", resp.StatusCode, state.Endpoint.ExpectedStatus),
-			responseTime)
-		return
+	// Always read (and so drain) the body, up to the configured cap, so the
+	// connection can be reused and a huge or endless response can't exhaust
+	// memory. Body size assertions beyond the cap are counted, not kept.
+	countTo := bodyCountLimit(endpoint)
+	body, size, err := readBodyCounting(resp, m.currentConfig().MaxBodyBytes, countTo)
+	if err != nil {
+		return checkResult{responseTime: responseTime, err: fmt.Sprintf("failed to read response body: %v", err)}
+	}
+
+	if endpoint.ExpectedStatus != ExpectedStatusAny && resp.StatusCode != endpoint.ExpectedStatus {
+		return checkResult{
+			responseTime: responseTime,
+			err:          fmt.Sprintf("unexpected status code: got %d, expected %d", resp.StatusCode, endpoint.ExpectedStatus),
+			body:         body,
+		}
+	}
+
+	if errMsg := checkExpectedHeaders(resp.Header, endpoint.ExpectedHeaders, m.currentConfig().RedactHeaders); errMsg != "" {
+		return checkResult{responseTime: responseTime, err: errMsg, body: body}
+	}
+
+	if errMsg := checkBodySize(size, countTo, endpoint.MinBodyBytes, endpoint.MaxBodyBytes); errMsg != "" {
+		return checkResult{responseTime: responseTime, err: errMsg, body: body}
+	}
+
+	// Error pages served with a success status, e.g. from a cache
+	if endpoint.BodyMustNotContain != "" && bytes.Contains(body, []byte(endpoint.BodyMustNotContain)) {
+		return checkResult{
+			responseTime: responseTime,
+			err:          fmt.Sprintf("response body contains forbidden text %q", endpoint.BodyMustNotContain),
+			body:         body,
+		}
+	}
+
+	if endpoint.Type == CheckTypeGraphQL {
+		if errMsg := checkGraphQLResponse(body, endpoint.GraphQLDataPath); errMsg != "" {
+			return checkResult{responseTime: responseTime, err: errMsg, body: body}
+		}
+	}
+
+	if endpoint.JSONPath != "" {
+		if errMsg := checkJSONPath(body, endpoint.JSONPath, endpoint.JSONPathExpected); errMsg != "" {
+			return checkResult{responseTime: responseTime, err: errMsg, body: body}
+		}
 	}
 
-	m.handleCheckSuccess(state, responseTime)
+	return checkResult{responseTime: responseTime}
+}
+
+// checkExpectedHeaders verifies response headers against the expected values.
+// A value prefixed with "~" matches as a substring, an empty value only requires
+// the header to be present, and anything else must match one of the header's
+// values exactly. Every mismatched header is reported with all of its values,
+// except that values of the redact headers are left out of the error.
+func checkExpectedHeaders(header http.Header, expected map[string]string, redact []string) string {
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		want := expected[name]
+		values, present := header[http.CanonicalHeaderKey(name)]
+		if !present {
+			problems = append(problems, fmt.Sprintf("missing expected header: %s", name))
+			continue
+		}
+		if want == "" {
+			continue
+		}
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = strconv.Quote(v)
+		}
+		got := strings.Join(quoted, ", ")
+		if strings.HasPrefix(want, "~") {
+			if !strings.Contains(strings.Join(values, ", "), want[1:]) {
+				if sensitiveHeader(name, redact) {
+					problems = append(problems, fmt.Sprintf("header %s mismatch: value does not contain the expected text", name))
+				} else {
+					problems = append(problems, fmt.Sprintf("header %s mismatch: %s does not contain %q", name, got, want[1:]))
+				}
+			}
+			continue
+		}
+		if !slices.Contains(values, want) {
+			if sensitiveHeader(name, redact) {
+				problems = append(problems, fmt.Sprintf("header %s mismatch: value differs from the expected one", name))
+			} else {
+				problems = append(problems, fmt.Sprintf("header %s mismatch: got %s, expected %q", name, got, want))
+			}
+		}
+	}
+	return strings.Join(problems, "; ")
 }
 
 // handleCheckSuccess handles a successful health check
 func (m *Monitor) handleCheckSuccess(state *EndpointState, responseTime time.Duration) {
+	if state.Endpoint.Invert {
+		m.recordFailure(state, "check succeeded but was expected to fail", responseTime, "")
+		return
+	}
+	m.recordSuccess(state, responseTime)
+}
+
+// handleCheckFailure handles a failed health check. If it failed on the content
+// of a response, the start of the body is kept with the history record for debugging.
+func (m *Monitor) handleCheckFailure(state *EndpointState, errorMsg string, responseTime time.Duration, body []byte) {
+	if state.Endpoint.Invert {
+		log.Printf("[%s] Check failed as expected: %s", state.Endpoint.Name, errorMsg)
+		m.recordSuccess(state, responseTime)
+		return
+	}
+	cfg := m.currentConfig()
+	snapshot := redactSecrets(bodySnapshot(body, cfg.FailureSnapshotBytes), m.requestHeaders(state.Endpoint), cfg.RedactHeaders, cfg.redactPattern)
+	m.recordFailure(state, errorMsg, responseTime, snapshot)
+}
+
+// recordSuccess updates the endpoint state for a healthy check result
+func (m *Monitor) recordSuccess(state *EndpointState, responseTime time.Duration) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
@@ -387,6 +1075,7 @@ func (m *Monitor) handleCheckSuccess(state *EndpointState, responseTime time.Dur
 	state.ConsecutiveFailures = 0
 	state.ConsecutiveSuccesses++
 	state.LastError = ""
+	state.firstFailureTime = time.Time{}
 
 	previousStatus := state.Status
 
@@ -398,69 +1087,231 @@ func (m *Monitor) handleCheckSuccess(state *EndpointState, responseTime time.Dur
 	log.Printf("[%s] ✓ Health check passed (status: %s, response time: %v)", 
 		state.Endpoint.Name, state.Status, responseTime)
 
+	m.statuses.Store(state.ID, statusSnapshot{Name: state.Endpoint.Name, Status: state.Status})
+	m.updateFlapping(state, previousStatus == StatusUnhealthy && state.Status == StatusHealthy)
+	m.updateDegraded(state, responseTime)
+
 	// Send recovery alert if endpoint recovered
 	if previousStatus == StatusUnhealthy && state.Status == StatusHealthy {
 		state.LastStatusChange = time.Now()
-		if !state.AlertsSuppressed {
+		if !state.AlertsSuppressed && !state.Flapping {
 			m.alerter.SendRecoveryAlert(state.Endpoint, state)
 		}
+		if state.Acknowledged {
+			state.Acknowledged = false
+			state.AcknowledgedAt = time.Time{}
+			if err := m.db.ClearAcknowledgement(state.ID); err != nil {
+				log.Printf("Error clearing acknowledgement for %s: %v", state.ID, err)
+			}
+		}
 	}
+	m.alerter.SyncAlertmanagerAlert(state.Endpoint, state)
 
 	// Save health check record to database
-	m.saveHealthRecord(state, "")
+	m.saveHealthRecord(state, "", "")
 }
 
-// handleCheckFailure handles a failed health check
-func (m *Monitor) handleCheckFailure(state *EndpointState, errorMsg string, responseTime time.Duration) {
+// recordFailure updates the endpoint state for an unhealthy check result
+func (m *Monitor) recordFailure(state *EndpointState, errorMsg string, responseTime time.Duration, snapshot string) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
 	state.LastCheck = time.Now()
-	state.NextCheck = time.Now().Add(state.CheckInterval)
 	state.ResponseTime = responseTime
 	state.ConsecutiveSuccesses = 0
 	state.ConsecutiveFailures++
 	state.LastError = errorMsg
+	if state.ConsecutiveFailures == 1 {
+		state.firstFailureTime = state.LastCheck
+	}
+	state.NextCheck = time.Now().Add(failureInterval(state))
 
 	previousStatus := state.Status
 
-	// Update status if threshold is met
-	if state.ConsecutiveFailures >= state.Endpoint.FailureThreshold {
+	// Update status once failures have lasted long enough, or if threshold is met.
+	// A missed heartbeat is already a whole interval late, so it counts at once.
+	if state.Endpoint.Type == CheckTypeHeartbeat {
+		state.Status = StatusUnhealthy
+	} else if state.Endpoint.FailureDuration > 0 {
+		if state.LastCheck.Sub(state.firstFailureTime) >= state.Endpoint.FailureDuration {
+			state.Status = StatusUnhealthy
+		}
+	} else if state.ConsecutiveFailures >= state.Endpoint.FailureThreshold {
 		state.Status = StatusUnhealthy
 	}
 
 	log.Printf("[%s] ✗ Health check failed (status: %s, error: %s)", 
 		state.Endpoint.Name, state.Status, errorMsg)
 
+	m.statuses.Store(state.ID, statusSnapshot{Name: state.Endpoint.Name, Status: state.Status})
+	m.updateFlapping(state, previousStatus == StatusHealthy && state.Status == StatusUnhealthy)
+
 	// Send alert if endpoint became unhealthy
 	if previousStatus != StatusUnhealthy && state.Status == StatusUnhealthy {
 		state.LastStatusChange = time.Now()
-		if !state.AlertsSuppressed {
+		if !state.AlertsSuppressed && !state.Flapping {
 			m.alerter.SendFailureAlert(state.Endpoint, state)
 		}
+	} else if state.Status == StatusUnhealthy && !state.AlertsSuppressed && !state.Acknowledged && !state.Flapping {
+		m.alerter.SendRepeatAlert(state.Endpoint, state)
+	} else if state.ConsecutiveFailures == 1 && state.Endpoint.AlertOnFirstFailure && previousStatus == StatusHealthy &&
+		!state.AlertsSuppressed && !state.Flapping {
+		// Don't wait for the failure threshold; the status still changes only once it is reached
+		m.alerter.SendFirstFailureAlert(state.Endpoint, state)
 	}
+	m.alerter.SyncAlertmanagerAlert(state.Endpoint, state)
 
 	// Save health check record to database
-	m.saveHealthRecord(state, errorMsg)
+	m.saveHealthRecord(state, errorMsg, snapshot)
+}
+
+// defaultBackoffMax caps the backed-off check interval when backoff_max is unset
+const defaultBackoffMax = 30 * time.Minute
+
+// failureInterval returns the delay before the next check after a failure.
+// With backoff enabled, the interval doubles for each failure beyond the
+// failure threshold, so detection is unaffected but a long outage is polled
+// less and less often, up to BackoffMax. A success resets it to CheckInterval.
+func failureInterval(state *EndpointState) time.Duration {
+	interval := state.CheckInterval
+	if !state.Endpoint.Backoff {
+		return interval
+	}
+	limit := state.Endpoint.BackoffMax
+	if limit <= 0 {
+		limit = defaultBackoffMax
+	}
+	for n := state.ConsecutiveFailures - state.Endpoint.FailureThreshold; n > 0 && interval < limit; n-- {
+		interval *= 2
+	}
+	return max(min(interval, limit), state.CheckInterval)
 }
 
-// saveHealthRecord saves a health check result to the database
-func (m *Monitor) saveHealthRecord(state *EndpointState, errorMsg string) {
-	if m.db == nil {
+// unhealthyDependency returns the name of the first endpoint in DependsOn that is
+// currently unhealthy, or "" if all of them are up or unknown
+func (m *Monitor) unhealthyDependency(endpoint Endpoint) string {
+	for _, id := range endpoint.DependsOn {
+		if v, ok := m.statuses.Load(id); ok {
+			if snap := v.(statusSnapshot); snap.Status == StatusUnhealthy {
+				return snap.Name
+			}
+		}
+	}
+	return ""
+}
+
+// updateFlapping records a status change and flags the endpoint as flapping when
+// it changes status more than flap_threshold times within flap_window. Flapping
+// ends once the changes in the window drop to half the threshold. Caller must
+// hold state.mu.
+func (m *Monitor) updateFlapping(state *EndpointState, changed bool) {
+	cfg := m.alerter.cfg()
+	if cfg.FlapThreshold <= 0 {
+		state.Flapping = false
+		state.transitions = nil
 		return
 	}
 
+	now := time.Now()
+	if changed {
+		state.transitions = append(state.transitions, now)
+	}
+	cutoff := now.Add(-cfg.FlapWindow)
+	i := 0
+	for i < len(state.transitions) && state.transitions[i].Before(cutoff) {
+		i++
+	}
+	state.transitions = state.transitions[i:]
+
+	switch {
+	case !state.Flapping && len(state.transitions) > cfg.FlapThreshold:
+		state.Flapping = true
+		log.Printf("[%s] Endpoint is flapping (%d status changes in %v); suppressing alerts",
+			state.Endpoint.Name, len(state.transitions), cfg.FlapWindow)
+		if !state.AlertsSuppressed {
+			m.alerter.SendFlappingAlert(state.Endpoint, state, len(state.transitions))
+		}
+	case state.Flapping && len(state.transitions) <= cfg.FlapThreshold/2:
+		state.Flapping = false
+		log.Printf("[%s] Endpoint stopped flapping (status: %s)", state.Endpoint.Name, state.Status)
+		// Alerts were held back while flapping; report a settled outage now
+		if state.Status == StatusUnhealthy && !state.AlertsSuppressed && !state.Acknowledged {
+			m.alerter.SendFailureAlert(state.Endpoint, state)
+		}
+	}
+}
+
+// saveHealthRecord queues a health check result for the next batched write
+// and hands it to the exporters
+func (m *Monitor) saveHealthRecord(state *EndpointState, errorMsg, snapshot string) {
 	record := &HealthCheckRecord{
 		EndpointID:   state.ID,
 		Timestamp:    state.LastCheck,
 		Status:       string(state.Status),
 		ResponseTime: state.ResponseTime,
+		StatusCode:   state.StatusCode,
 		Error:        errorMsg,
+		BodySnapshot: snapshot,
+		URL:          state.ActiveURL,
+		RemoteAddr:   state.RemoteAddr,
+		Degraded:     state.Degraded,
+		SLOBreach:    state.Endpoint.ResponseTimeSLO > 0 && state.ResponseTime > state.Endpoint.ResponseTimeSLO,
+	}
+
+	if m.records != nil {
+		m.records.Add(record)
+	}
+	for _, exporter := range m.exporters {
+		exporter.Export(state.Endpoint, record)
 	}
+}
+
+// MonitorHealth describes whether the monitor itself is working
+type MonitorHealth struct {
+	Running      bool      `json:"running"`
+	LastTick     time.Time `json:"last_tick"`
+	Stalled      bool      `json:"stalled"`
+	ActiveChecks int       `json:"active_checks"`
+	Endpoints    int       `json:"endpoints"`
+
+	// Health check records waiting to be written, including ones whose
+	// write failed, and records dropped because too many were waiting
+	PendingRecords int   `json:"pending_records"`
+	DroppedRecords int64 `json:"dropped_records"`
+}
 
-	if err := m.db.SaveHealthCheckRecord(record); err != nil {
-		log.Printf("Error saving health check record: %v", err)
+// Health reports on the monitor loop. The loop counts as stalled when no
+// scheduler pass has started within a few ticks plus the longest check timeout,
+// since a pass waits for all of its checks to finish.
+func (m *Monitor) Health() MonitorHealth {
+	cfg := m.currentConfig()
+	m.mu.RLock()
+	endpoints := len(m.states)
+	longest := time.Duration(0)
+	for _, state := range m.states {
+		state.mu.RLock()
+		// Failover URLs are tried one after another, each with the full timeout
+		if t := cfg.checkTimeout(state.Endpoint) * time.Duration(len(state.Endpoint.Targets())); t > longest {
+			longest = t
+		}
+		state.mu.RUnlock()
+	}
+	m.mu.RUnlock()
+
+	h := MonitorHealth{
+		Running:      m.running.Load(),
+		ActiveChecks: int(m.activeChecks.Load()),
+		Endpoints:    endpoints,
+	}
+	if m.records != nil {
+		h.PendingRecords = m.records.Pending()
+		h.DroppedRecords = m.records.Dropped()
+	}
+	if tick := m.lastTick.Load(); tick != 0 {
+		h.LastTick = time.Unix(0, tick)
+		h.Stalled = time.Since(h.LastTick) > 3*schedulerTick+longest
 	}
+	return h
 }
 
 // GetStatus returns the current status of all endpoints
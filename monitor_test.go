@@ -0,0 +1,165 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestMonitor returns a monitor over an empty in-memory store whose
+// alerting is enabled without any channels, so alerts are tracked but not sent
+func newTestMonitor(t *testing.T) *Monitor {
+	t.Helper()
+	return NewMonitor(&Config{Alerting: Alerting{Enabled: true}}, NewMemoryStore(&StorageConfig{}))
+}
+
+// newTestState returns the state of a healthy endpoint with the given settings
+func newTestState(endpoint Endpoint) *EndpointState {
+	endpoint.Name = "api"
+	return &EndpointState{
+		ID:            "api",
+		Endpoint:      endpoint,
+		Status:        StatusHealthy,
+		Enabled:       true,
+		CheckInterval: time.Minute,
+	}
+}
+
+// alerted reports whether a failure alert is outstanding for the endpoint
+func alerted(m *Monitor, id string) bool {
+	m.alerter.mu.Lock()
+	defer m.alerter.mu.Unlock()
+	_, ok := m.alerter.lastSent[id]
+	return ok
+}
+
+func TestRecordFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   Endpoint
+		failures   int
+		wantStatus HealthStatus
+	}{
+		{name: "below the threshold", endpoint: Endpoint{FailureThreshold: 3}, failures: 2, wantStatus: StatusHealthy},
+		{name: "at the threshold", endpoint: Endpoint{FailureThreshold: 3}, failures: 3, wantStatus: StatusUnhealthy},
+		{name: "past the threshold", endpoint: Endpoint{FailureThreshold: 3}, failures: 5, wantStatus: StatusUnhealthy},
+		{name: "missed heartbeat", endpoint: Endpoint{Type: CheckTypeHeartbeat, FailureThreshold: 3}, failures: 1, wantStatus: StatusUnhealthy},
+		{
+			name:       "failure duration not reached",
+			endpoint:   Endpoint{FailureThreshold: 1, FailureDuration: time.Hour},
+			failures:   5,
+			wantStatus: StatusHealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMonitor(t)
+			state := newTestState(tt.endpoint)
+			for i := 0; i < tt.failures; i++ {
+				m.recordFailure(state, "connection refused", 10*time.Millisecond, "")
+			}
+
+			if state.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", state.Status, tt.wantStatus)
+			}
+			if state.ConsecutiveFailures != tt.failures || state.ConsecutiveSuccesses != 0 {
+				t.Errorf("consecutive failures/successes = %d/%d, want %d/0",
+					state.ConsecutiveFailures, state.ConsecutiveSuccesses, tt.failures)
+			}
+			if state.LastError != "connection refused" {
+				t.Errorf("last error = %q", state.LastError)
+			}
+			down := tt.wantStatus == StatusUnhealthy
+			if alerted(m, state.ID) != down {
+				t.Errorf("failure alert sent = %v, want %v", !down, down)
+			}
+			if state.LastStatusChange.IsZero() == down {
+				t.Errorf("last status change = %v, want it set only when the status changed", state.LastStatusChange)
+			}
+		})
+	}
+}
+
+func TestRecordFailureDuration(t *testing.T) {
+	m := newTestMonitor(t)
+	state := newTestState(Endpoint{FailureThreshold: 1, FailureDuration: time.Minute})
+
+	m.recordFailure(state, "timeout", 0, "")
+	if state.Status != StatusHealthy {
+		t.Fatalf("status after the first failure = %s, want healthy", state.Status)
+	}
+
+	// Failures that started long enough ago mark the endpoint down
+	state.firstFailureTime = time.Now().Add(-2 * time.Minute)
+	m.recordFailure(state, "timeout", 0, "")
+	if state.Status != StatusUnhealthy {
+		t.Errorf("status once failures lasted past failure_duration = %s, want unhealthy", state.Status)
+	}
+}
+
+func TestRecordSuccess(t *testing.T) {
+	m := newTestMonitor(t)
+	state := newTestState(Endpoint{FailureThreshold: 2, SuccessThreshold: 2})
+
+	m.recordFailure(state, "503 Service Unavailable", 0, "")
+	m.recordFailure(state, "503 Service Unavailable", 0, "")
+	if state.Status != StatusUnhealthy || !alerted(m, state.ID) {
+		t.Fatalf("status = %s, alerted = %v; want unhealthy and alerted", state.Status, alerted(m, state.ID))
+	}
+	state.Acknowledged = true
+	state.AcknowledgedAt = time.Now()
+	wentDown := state.LastStatusChange
+
+	// One success clears the failure but is below the success threshold
+	m.recordSuccess(state, 20*time.Millisecond)
+	if state.Status != StatusUnhealthy {
+		t.Errorf("status after one success = %s, want unhealthy", state.Status)
+	}
+	if state.ConsecutiveFailures != 0 || state.ConsecutiveSuccesses != 1 || state.LastError != "" {
+		t.Errorf("after one success: failures %d, successes %d, last error %q",
+			state.ConsecutiveFailures, state.ConsecutiveSuccesses, state.LastError)
+	}
+	if !state.Acknowledged {
+		t.Error("acknowledgement cleared before the endpoint recovered")
+	}
+
+	// The second one recovers it
+	m.recordSuccess(state, 20*time.Millisecond)
+	if state.Status != StatusHealthy {
+		t.Errorf("status after two successes = %s, want healthy", state.Status)
+	}
+	if alerted(m, state.ID) {
+		t.Error("failure alert still outstanding after recovery")
+	}
+	if state.Acknowledged || !state.AcknowledgedAt.IsZero() {
+		t.Error("acknowledgement kept after recovery")
+	}
+	if !state.LastStatusChange.After(wentDown) {
+		t.Errorf("last status change = %v, want after %v", state.LastStatusChange, wentDown)
+	}
+	if wait := state.NextCheck.Sub(state.LastCheck); wait < time.Minute || wait > time.Minute+time.Second {
+		t.Errorf("next check %v after the last one, want the check interval", wait)
+	}
+
+	// A failure after recovering starts counting from scratch
+	m.recordFailure(state, "timeout", 0, "")
+	if state.Status != StatusHealthy || state.ConsecutiveFailures != 1 || state.ConsecutiveSuccesses != 0 {
+		t.Errorf("after a new failure: status %s, failures %d, successes %d",
+			state.Status, state.ConsecutiveFailures, state.ConsecutiveSuccesses)
+	}
+}
+
+func TestInvertedCheck(t *testing.T) {
+	m := newTestMonitor(t)
+	state := newTestState(Endpoint{FailureThreshold: 1, SuccessThreshold: 1, Invert: true})
+
+	m.handleCheckSuccess(state, 0)
+	if state.Status != StatusUnhealthy || state.ConsecutiveFailures != 1 {
+		t.Errorf("passing inverted check: status %s, failures %d; want unhealthy, 1", state.Status, state.ConsecutiveFailures)
+	}
+
+	m.handleCheckFailure(state, "connection refused", 0, nil)
+	if state.Status != StatusHealthy || state.ConsecutiveSuccesses != 1 {
+		t.Errorf("failing inverted check: status %s, successes %d; want healthy, 1", state.Status, state.ConsecutiveSuccesses)
+	}
+}
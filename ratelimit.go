@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimiterIdle is how long a client's limiter is kept after its last
+// request. By then its bucket has refilled, so forgetting it changes nothing.
+const rateLimiterIdle = 10 * time.Minute
+
+// apiRateLimiter hands out a token bucket per client IP, or one shared by all
+// clients when global is set
+type apiRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	global  *rate.Limiter
+	clients map[string]*clientLimiter
+	swept   time.Time
+}
+
+// clientLimiter is one client's token bucket and when it was last used
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newAPIRateLimiter builds the limiter for server.rate_limit
+func newAPIRateLimiter(cfg *ServerConfig) *apiRateLimiter {
+	l := &apiRateLimiter{
+		limit:   rate.Limit(cfg.RateLimit),
+		burst:   cfg.RateLimitBurst,
+		clients: make(map[string]*clientLimiter),
+		swept:   time.Now(),
+	}
+	if cfg.RateLimitGlobal {
+		l.global = rate.NewLimiter(l.limit, l.burst)
+	}
+	return l
+}
+
+// limiterFor returns the bucket a request from addr draws from
+func (l *apiRateLimiter) limiterFor(addr string) *rate.Limiter {
+	if l.global != nil {
+		return l.global
+	}
+	ip := addr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		ip = host
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.swept) > rateLimiterIdle {
+		for key, client := range l.clients {
+			if now.Sub(client.lastSeen) > rateLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.swept = now
+	}
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+	return client.limiter
+}
+
+// withRateLimit answers 429 to /api requests over server.rate_limit, so a
+// misbehaving client can't overwhelm the monitor. /api/health stays exempt
+// for load balancers and uptime probes.
+func (s *Server) withRateLimit(next http.Handler) http.Handler {
+	if s.config.RateLimit <= 0 {
+		return next
+	}
+	limiter := newAPIRateLimiter(s.config)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reservation := limiter.limiterFor(r.RemoteAddr).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health check records are buffered and written to the store in one
+// transaction every recordFlushInterval, or as soon as recordBufferSize new
+// ones are waiting, instead of one write per check. Records from a failed
+// write stay buffered for the next flush, up to recordRetryLimit in total;
+// beyond that the oldest are dropped.
+const (
+	recordFlushInterval = time.Second
+	recordBufferSize    = 500
+	recordRetryLimit    = 10000
+)
+
+// recordWriter batches health check records on their way to the store
+type recordWriter struct {
+	store   Store
+	mu      sync.Mutex
+	pending []*HealthCheckRecord
+	added   int // records queued since the last flush
+	full    chan struct{}
+	dropped atomic.Int64
+}
+
+// newRecordWriter creates a record writer for store
+func newRecordWriter(store Store) *recordWriter {
+	return &recordWriter{
+		store: store,
+		full:  make(chan struct{}, 1),
+	}
+}
+
+// Add queues a record for the next flush
+func (w *recordWriter) Add(record *HealthCheckRecord) {
+	w.mu.Lock()
+	w.pending = append(w.pending, record)
+	w.added++
+	full := w.added >= recordBufferSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes all queued records to the store in one batch. If the write
+// fails, the records are kept for the next flush.
+func (w *recordWriter) Flush() error {
+	w.mu.Lock()
+	records := w.pending
+	w.pending = nil
+	w.added = 0
+	w.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+	err := w.store.SaveHealthCheckRecords(records)
+	if err == nil {
+		return nil
+	}
+
+	log.Printf("Error saving %d health check records, will retry: %v", len(records), err)
+	w.mu.Lock()
+	w.pending = append(records, w.pending...)
+	w.trim()
+	w.mu.Unlock()
+	return err
+}
+
+// trim drops the oldest queued records beyond recordRetryLimit. Caller must
+// hold w.mu.
+func (w *recordWriter) trim() {
+	over := len(w.pending) - recordRetryLimit
+	if over <= 0 {
+		return
+	}
+	w.pending = append(w.pending[:0:0], w.pending[over:]...)
+	total := w.dropped.Add(int64(over))
+	log.Printf("Health check record buffer full, dropped %d records (%d dropped in total)", over, total)
+}
+
+// Pending returns the number of records waiting to be written
+func (w *recordWriter) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// Dropped returns the number of records dropped because the buffer was full
+func (w *recordWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// run flushes queued records every recordFlushInterval, or sooner when the
+// buffer fills, until ctx is cancelled
+func (w *recordWriter) run(ctx context.Context) {
+	ticker := time.NewTicker(recordFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.full:
+		}
+		w.Flush()
+	}
+}
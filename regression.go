@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+)
+
+// Response time baselines are recomputed every baselineRefreshInterval and kept
+// in the store's settings under baselineSettingKey, so they survive restarts.
+// An endpoint needs minBaselineSamples healthy checks before it gets one.
+const (
+	baselineSettingKey      = "response_baselines"
+	baselineRefreshInterval = time.Hour
+	minBaselineSamples      = 20
+)
+
+// runBaselineRefresh restores the saved baselines and then recomputes them
+// every baselineRefreshInterval while regression detection is enabled
+func (m *Monitor) runBaselineRefresh() {
+	defer m.wg.Done()
+
+	m.loadBaselines()
+
+	ticker := time.NewTicker(baselineRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if m.currentConfig().Regression.Multiplier > 0 {
+			m.refreshBaselines()
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadBaselines reads the baselines saved by a previous run
+func (m *Monitor) loadBaselines() {
+	data, err := m.db.GetSetting(baselineSettingKey)
+	if err != nil {
+		log.Printf("Error loading response time baselines: %v", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+
+	var saved map[string]time.Duration
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Printf("Error loading response time baselines: %v", err)
+		return
+	}
+	for id, baseline := range saved {
+		m.baselines.Store(id, baseline)
+	}
+}
+
+// refreshBaselines sets each endpoint's baseline to the median response time
+// of its healthy checks within the baseline window and saves them
+func (m *Monitor) refreshBaselines() {
+	endpoints, err := m.db.GetAllEndpoints()
+	if err != nil {
+		log.Printf("Error refreshing response time baselines: %v", err)
+		return
+	}
+
+	since := time.Now().Add(-m.currentConfig().Regression.BaselineWindow)
+	baselines := make(map[string]time.Duration)
+	for _, ep := range endpoints {
+		records, _, err := m.db.GetHealthHistory(ep.ID, HistoryQuery{From: since})
+		if err != nil {
+			log.Printf("Error reading history for %s: %v", ep.Name, err)
+			continue
+		}
+		var times []time.Duration
+		for _, r := range records {
+			if r.Status == string(StatusHealthy) && r.ResponseTime > 0 {
+				times = append(times, r.ResponseTime)
+			}
+		}
+		if len(times) >= minBaselineSamples {
+			baselines[ep.ID] = medianDuration(times)
+		}
+	}
+
+	m.baselines.Range(func(k, _ interface{}) bool {
+		if _, ok := baselines[k.(string)]; !ok {
+			m.baselines.Delete(k)
+		}
+		return true
+	})
+	for id, baseline := range baselines {
+		m.baselines.Store(id, baseline)
+	}
+
+	data, err := json.Marshal(baselines)
+	if err == nil {
+		err = m.db.SaveSetting(baselineSettingKey, data)
+	}
+	if err != nil {
+		log.Printf("Error saving response time baselines: %v", err)
+	}
+}
+
+// Baseline returns an endpoint's baseline response time, and false if it
+// doesn't have enough history for one yet
+func (m *Monitor) Baseline(id string) (time.Duration, bool) {
+	v, ok := m.baselines.Load(id)
+	if !ok {
+		return 0, false
+	}
+	return v.(time.Duration), true
+}
+
+// updateDegraded records the response time of a passed check and marks the
+// endpoint degraded while its recent median is over the regression threshold.
+// Caller must hold state.mu.
+func (m *Monitor) updateDegraded(state *EndpointState, responseTime time.Duration) {
+	cfg := m.currentConfig().Regression
+	if cfg.Multiplier <= 0 {
+		state.Degraded = false
+		state.recentTimes = nil
+		return
+	}
+
+	state.recentTimes = append(state.recentTimes, responseTime)
+	if n := len(state.recentTimes) - max(cfg.RecentChecks, 1); n > 0 {
+		state.recentTimes = state.recentTimes[n:]
+	}
+
+	baseline, ok := m.Baseline(state.ID)
+	if !ok {
+		state.Degraded = false
+		return
+	}
+	if len(state.recentTimes) < cfg.RecentChecks {
+		return
+	}
+
+	recent := medianDuration(state.recentTimes)
+	degraded := float64(recent) > float64(baseline)*cfg.Multiplier
+	if degraded == state.Degraded {
+		return
+	}
+	state.Degraded = degraded
+
+	if degraded {
+		log.Printf("[%s] Response time regressed: median %v over the last %d checks, baseline %v",
+			state.Endpoint.Name, recent, len(state.recentTimes), baseline)
+	} else {
+		log.Printf("[%s] Response time back to normal: median %v, baseline %v",
+			state.Endpoint.Name, recent, baseline)
+	}
+	if cfg.Alert && !state.AlertsSuppressed && !state.Flapping {
+		m.alerter.SendDegradedAlert(state.Endpoint, state, degraded, baseline, recent)
+	}
+}
+
+// medianDuration returns the median of ds, which must not be empty
+func medianDuration(ds []time.Duration) time.Duration {
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary schedules accepted by alerting.summary_schedule
+const (
+	SummaryDaily  = "daily"
+	SummaryWeekly = "weekly"
+)
+
+// EndpointSummary is one endpoint's line in a scheduled uptime report
+type EndpointSummary struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	Checks            int     `json:"checks"`
+	UptimePercent     float64 `json:"uptime_percent"`
+	Incidents         int     `json:"incidents"`
+	AvgResponseTimeMs float64 `json:"avg_response_time_ms"`
+
+	// Only set for endpoints with a response_time_slo: the checks slower
+	// than it, and the percentage of checks that passed within it
+	SLOBreaches          int      `json:"slo_breaches,omitempty"`
+	SLOCompliancePercent *float64 `json:"slo_compliance_percent,omitempty"`
+}
+
+// uptimeWindow is the rolling period covered by the uptime in the status API,
+// and uptimeRefreshInterval is how often it is recomputed from history
+const (
+	uptimeWindow          = 24 * time.Hour
+	uptimeRefreshInterval = 5 * time.Minute
+)
+
+// summaryPeriodStart returns the start of the daily or weekly report period
+// containing t: local midnight, or local midnight on Monday
+func summaryPeriodStart(t time.Time, schedule string) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if schedule == SummaryWeekly {
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	}
+	return start
+}
+
+// runSummaryReports sends the scheduled uptime report each time a daily or
+// weekly period ends. The schedule is read on every pass, so a config reload
+// takes effect without a restart; no report is sent for the period in
+// progress when the monitor starts.
+func (m *Monitor) runSummaryReports() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var current time.Time
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			schedule := m.alerter.cfg().SummarySchedule
+			if schedule == "" {
+				current = time.Time{}
+				continue
+			}
+			start := summaryPeriodStart(now, schedule)
+			if !current.IsZero() && start.After(current) {
+				m.sendSummaryReport(schedule, summaryPeriodStart(start.Add(-time.Nanosecond), schedule), start)
+			}
+			current = start
+		}
+	}
+}
+
+// runUptimeRefresh recomputes the rolling uptime of every endpoint now and
+// then every uptimeRefreshInterval, so status requests never scan history
+func (m *Monitor) runUptimeRefresh() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(uptimeRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		m.refreshUptime()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshUptime replaces the cached uptime and SLO compliance figures with
+// ones computed over the last uptimeWindow. Endpoints without checks in the
+// window, or without a response_time_slo for compliance, are dropped.
+func (m *Monitor) refreshUptime() {
+	now := time.Now()
+	summaries, err := buildSummary(m.db, now.Add(-uptimeWindow), now)
+	if err != nil {
+		log.Printf("Error refreshing uptime: %v", err)
+		return
+	}
+
+	current := make(map[string]bool, len(summaries))
+	withSLO := make(map[string]bool, len(summaries))
+	for _, s := range summaries {
+		if s.Checks > 0 {
+			m.uptime.Store(s.ID, s.UptimePercent)
+			current[s.ID] = true
+		}
+		if s.SLOCompliancePercent != nil {
+			m.compliance.Store(s.ID, *s.SLOCompliancePercent)
+			withSLO[s.ID] = true
+		}
+	}
+	m.uptime.Range(func(k, _ interface{}) bool {
+		if !current[k.(string)] {
+			m.uptime.Delete(k)
+		}
+		return true
+	})
+	m.compliance.Range(func(k, _ interface{}) bool {
+		if !withSLO[k.(string)] {
+			m.compliance.Delete(k)
+		}
+		return true
+	})
+}
+
+// Uptime returns the cached uptime percentage of an endpoint over the last
+// uptimeWindow, and false if it has not been computed yet
+func (m *Monitor) Uptime(id string) (float64, bool) {
+	v, ok := m.uptime.Load(id)
+	if !ok {
+		return 0, false
+	}
+	return v.(float64), true
+}
+
+// SLOCompliance returns the cached percentage of an endpoint's checks that
+// passed within its response_time_slo over the last uptimeWindow, and false
+// if it has no SLO or the figure has not been computed yet
+func (m *Monitor) SLOCompliance(id string) (float64, bool) {
+	v, ok := m.compliance.Load(id)
+	if !ok {
+		return 0, false
+	}
+	return v.(float64), true
+}
+
+// sendSummaryReport builds the uptime report for [from, to) and sends it.
+// History is only kept for DataRetentionDays, so a weekly report starts where
+// the stored history does and says which days it covers.
+func (m *Monitor) sendSummaryReport(schedule string, from, to time.Time) {
+	if oldest := time.Now().AddDate(0, 0, -DataRetentionDays); from.Before(oldest) {
+		from = oldest
+	}
+	summaries, err := buildSummary(m.db, from, to)
+	if err != nil {
+		log.Printf("Error building %s summary report: %v", schedule, err)
+		return
+	}
+	m.alerter.SendSummaryReport(schedule, from, to, summaries)
+}
+
+// buildSummary computes per-endpoint uptime, incident count, average
+// response time and SLO compliance from the history recorded between from
+// and to
+func buildSummary(store Store, from, to time.Time) ([]EndpointSummary, error) {
+	endpoints, err := store.GetAllEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]EndpointSummary, 0, len(endpoints))
+	for _, ep := range endpoints {
+		records, _, err := store.GetHealthHistory(ep.ID, HistoryQuery{From: from, To: to.Add(-time.Nanosecond)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history for %s: %w", ep.Name, err)
+		}
+
+		summary := EndpointSummary{ID: ep.ID, Name: ep.Name, Checks: len(records)}
+		var healthy, withinSLO, samples int
+		var total time.Duration
+		wasUnhealthy := false
+		// Records are newest first; walk them oldest first to count new outages
+		for i := len(records) - 1; i >= 0; i-- {
+			r := records[i]
+			unhealthy := r.Status == string(StatusUnhealthy)
+			if unhealthy && !wasUnhealthy {
+				summary.Incidents++
+			}
+			wasUnhealthy = unhealthy
+			if r.Status == string(StatusHealthy) {
+				healthy++
+				if !r.SLOBreach {
+					withinSLO++
+				}
+			}
+			if r.SLOBreach {
+				summary.SLOBreaches++
+			}
+			if r.ResponseTime > 0 {
+				total += r.ResponseTime
+				samples++
+			}
+		}
+		if summary.Checks > 0 {
+			summary.UptimePercent = float64(healthy) / float64(summary.Checks) * 100
+			if ep.ResponseTimeSLO > 0 {
+				compliance := float64(withinSLO) / float64(summary.Checks) * 100
+				summary.SLOCompliancePercent = &compliance
+			}
+		}
+		if samples > 0 {
+			summary.AvgResponseTimeMs = float64(total/time.Duration(samples)) / float64(time.Millisecond)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, nil
+}
+
+// formatSummary renders a report as plain text, one line per endpoint
+func formatSummary(schedule string, from, to time.Time, summaries []EndpointSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 %s uptime report: %s – %s\n",
+		strings.ToUpper(schedule[:1])+schedule[1:], from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+	if from.After(summaryPeriodStart(to.Add(-time.Nanosecond), schedule)) {
+		fmt.Fprintf(&b, "Covers the last %.0f days of the period only; history is kept for %d days.\n",
+			to.Sub(from).Hours()/24, DataRetentionDays)
+	}
+	b.WriteString("\n")
+	if len(summaries) == 0 {
+		b.WriteString("No endpoints are configured.\n")
+		return b.String()
+	}
+	for _, s := range summaries {
+		if s.Checks == 0 {
+			fmt.Fprintf(&b, "• %s: no checks\n", s.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "• %s: %.2f%% uptime, %d incident(s), avg %.0fms over %d checks",
+			s.Name, s.UptimePercent, s.Incidents, s.AvgResponseTimeMs, s.Checks)
+		if s.SLOCompliancePercent != nil {
+			fmt.Fprintf(&b, ", %.2f%% within SLO (%d breach(es))", *s.SLOCompliancePercent, s.SLOBreaches)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
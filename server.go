@@ -1,991 +1,608 @@
 package main
 
 import (
+	"compress/gzip"
+	"crypto/subtle"
+	"embed"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// webAssets holds the dashboard HTML, CSS and JS
+//
+//go:embed web
+var webAssets embed.FS
+
+// openAPISpec describes the HTTP API; update it alongside the handlers
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// dashboardTemplate renders the dashboard page
+var dashboardTemplate = template.Must(template.ParseFS(webAssets, "web/index.html"))
+
 // Server provides HTTP endpoints for monitoring status
 type Server struct {
 	monitor *Monitor
-	db      *Database
-	port    int
+	db      Store
+	config  *ServerConfig
 }
 
 // NewServer creates a new HTTP server
-func NewServer(monitor *Monitor, db *Database, port int) *Server {
+func NewServer(monitor *Monitor, db Store, config *ServerConfig) *Server {
 	return &Server{
 		monitor: monitor,
 		db:      db,
-		port:    port,
+		config:  config,
 	}
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() {
-	http.HandleFunc("/", s.handleDashboard)
-	http.HandleFunc("/api/status", s.handleAPIStatus)
-	http.HandleFunc("/api/health", s.handleHealth)
-	http.HandleFunc("/api/endpoints", s.handleEndpoints)
-	http.HandleFunc("/api/endpoints/add", s.handleAddEndpoint)
-	http.HandleFunc("/api/endpoints/delete", s.handleDeleteEndpoint)
-	http.HandleFunc("/api/endpoints/enable", s.handleEnableEndpoint)
-	http.HandleFunc("/api/endpoints/disable", s.handleDisableEndpoint)
-	http.HandleFunc("/api/endpoints/suppress", s.handleSuppressAlerts)
-	http.HandleFunc("/api/endpoints/unsuppress", s.handleUnsuppressAlerts)
-	http.HandleFunc("/api/history", s.handleHistory)
-	http.HandleFunc("/api/endpoints/update", s.handleUpdateEndpoint)
-
-	addr := fmt.Sprintf(":%d", s.port)
-	log.Printf("Starting web dashboard on http://localhost%s", addr)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleDashboard)
+	mux.Handle("/static/", s.handleStatic())
+	mux.HandleFunc("/favicon.ico", s.handleFavicon)
+	mux.HandleFunc(endpointPagePath, s.handleEndpointPage)
+	mux.HandleFunc("/api/status", s.handleAPIStatus)
+	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/healthz", s.handleSelfHealth)
+	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/dbstats", s.handleDBStats)
+	mux.HandleFunc("/api/groups", s.handleGroups)
+	mux.HandleFunc("/api/version", s.handleVersion)
+	mux.HandleFunc("/api/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/api/endpoints", s.handleEndpoints)
+	mux.HandleFunc("/api/endpoints/add", s.mutating(s.handleAddEndpoint))
+	mux.HandleFunc("/api/endpoints/clone", s.mutating(s.handleCloneEndpoint))
+	mux.HandleFunc("/api/endpoints/delete", s.mutating(s.handleDeleteEndpoint))
+	mux.HandleFunc("/api/endpoints/enable", s.mutating(s.handleEnableEndpoint))
+	mux.HandleFunc("/api/endpoints/disable", s.mutating(s.handleDisableEndpoint))
+	mux.HandleFunc("/api/endpoints/suppress", s.mutating(s.handleSuppressAlerts))
+	mux.HandleFunc("/api/endpoints/unsuppress", s.mutating(s.handleUnsuppressAlerts))
+	mux.HandleFunc("/api/endpoints/snooze", s.mutating(s.handleSnoozeAlerts))
+	mux.HandleFunc("/api/endpoints/acknowledge", s.mutating(s.handleAcknowledge))
+	mux.HandleFunc("/api/endpoints/reset", s.mutating(s.handleResetEndpoint))
+	mux.HandleFunc("/api/endpoints/check", s.mutating(s.handleCheckEndpoints))
+	mux.HandleFunc("/api/incidents/ack", s.handleIncidentAck)
+	mux.HandleFunc("/api/incidents/resolve", s.handleIncidentResolve)
+	mux.HandleFunc("/api/history", s.handleHistory)
+	mux.HandleFunc("/api/history/stream", s.handleHistoryStream)
+	mux.HandleFunc("/api/history/recent", s.handleRecentHistory)
+	// Pings are monitoring input and need the endpoint's token, so they
+	// work in read-only mode
+	mux.HandleFunc(heartbeatPath, s.handlePing)
+	mux.HandleFunc("/api/timeline", s.handleTimeline)
+	mux.HandleFunc("/api/history/clear", s.mutating(s.handleClearHistory))
+	mux.HandleFunc("/api/backup", s.handleBackup)
+	mux.HandleFunc("/api/compact", s.handleCompact)
+	mux.HandleFunc("/api/cleanup", s.handleCleanup)
+	mux.HandleFunc("/api/endpoints/update", s.mutating(s.handleUpdateEndpoint))
+	mux.HandleFunc("/api/endpoints/export", s.handleExportEndpoints)
+	mux.HandleFunc("/api/endpoints/import", s.mutating(s.handleImportEndpoints))
+
+	addr := net.JoinHostPort(s.config.BindAddress, strconv.Itoa(s.config.Port))
+	host := s.config.BindAddress
+	if host == "" {
+		host = "localhost"
+	}
+	log.Printf("Starting web dashboard on http://%s", net.JoinHostPort(host, strconv.Itoa(s.config.Port)))
 	
 	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil {
+		if err := http.ListenAndServe(addr, s.withCORS(s.withRateLimit(withGzip(mux)))); err != nil {
 			log.Printf("HTTP server error: %v", err)
 		}
 	}()
 }
 
+// mutating guards a handler that changes state, rejecting it in read-only mode
+func (s *Server) mutating(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.ReadOnly {
+			http.Error(w, "Server is in read-only mode", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// withCORS adds CORS headers for allowed origins and answers preflight requests
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	if len(s.config.AllowedOrigins) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && s.originAllowed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withGzip compresses /api/ responses for clients that send Accept-Encoding: gzip
+func withGzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the body written through it. Compression
+// starts with the header, so responses without a body stay uncompressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+// WriteHeader switches on compression unless the status has no body
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	if code != http.StatusNoContent && code != http.StatusNotModified {
+		g.Header().Set("Content-Encoding", "gzip")
+		g.Header().Del("Content-Length")
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(code)
+}
+
+// Write compresses b into the response body
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gz == nil {
+		return g.ResponseWriter.Write(b)
+	}
+	return g.gz.Write(b)
+}
+
+// Flush sends any buffered compressed data to the client
+func (g *gzipResponseWriter) Flush() {
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the gzip stream
+func (g *gzipResponseWriter) Close() error {
+	if g.gz == nil {
+		return nil
+	}
+	return g.gz.Close()
+}
+
+// originAllowed reports whether origin matches the configured allow list ("*" allows any)
+func (s *Server) originAllowed(origin string) bool {
+	for _, allowed := range s.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleDashboard serves the main dashboard HTML
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
-	tmpl := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Site Watch</title>
-    <style>
-        * { margin: 0; padding: 0; box-sizing: border-box; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, Cantarell, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            min-height: 100vh;
-            padding: 20px;
-        }
-        .container { max-width: 1200px; margin: 0 auto; }
-        .header {
-            background: white;
-            border-radius: 10px;
-            padding: 30px;
-            margin-bottom: 20px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-            display: flex;
-            justify-content: space-between;
-            align-items: center;
-        }
-        .header h1 { color: #333; font-size: 2em; margin-bottom: 5px; }
-        .header p { color: #666; font-size: 1em; }
-        .btn {
-            padding: 10px 20px;
-            border: none;
-            border-radius: 8px;
-            cursor: pointer;
-            font-size: 0.9em;
-            font-weight: 600;
-            transition: all 0.2s;
-        }
-        .btn-primary { background: #6366f1; color: white; }
-        .btn-primary:hover { background: #4f46e5; }
-        .btn-success { background: #10b981; color: white; }
-        .btn-success:hover { background: #059669; }
-        .btn-warning { background: #f59e0b; color: white; }
-        .btn-warning:hover { background: #d97706; }
-        .btn-danger { background: #ef4444; color: white; }
-        .btn-danger:hover { background: #dc2626; }
-        .btn-secondary { background: #6b7280; color: white; }
-        .btn-secondary:hover { background: #4b5563; }
-        .btn-sm { padding: 6px 12px; font-size: 0.8em; }
-        .stats {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
-            gap: 15px;
-            margin-bottom: 20px;
-        }
-        .stat-card {
-            background: white;
-            border-radius: 10px;
-            padding: 15px;
-            box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);
-        }
-        .stat-card h3 { color: #666; font-size: 0.8em; text-transform: uppercase; margin-bottom: 5px; }
-        .stat-card .value { font-size: 1.8em; font-weight: bold; color: #333; }
-        .stat-card.healthy .value { color: #10b981; }
-        .stat-card.unhealthy .value { color: #ef4444; }
-        .endpoints { display: flex; flex-direction: column; gap: 6px; }
-        .endpoint-row {
-            background: white;
-            border-radius: 6px;
-            padding: 8px 12px;
-            box-shadow: 0 1px 3px rgba(0, 0, 0, 0.1);
-            display: flex;
-            align-items: center;
-            gap: 12px;
-            font-size: 0.85em;
-        }
-        .endpoint-row.disabled { opacity: 0.6; background: #f3f4f6; }
-        .endpoint-row.unhealthy { border-left: 3px solid #ef4444; }
-        .endpoint-row.healthy { border-left: 3px solid #10b981; }
-        .endpoint-status { width: 8px; height: 8px; border-radius: 50%; flex-shrink: 0; }
-        .endpoint-status.healthy { background: #10b981; }
-        .endpoint-status.unhealthy { background: #ef4444; }
-        .endpoint-status.unknown { background: #9ca3af; }
-        .endpoint-name { font-weight: 600; color: #333; min-width: 120px; max-width: 150px; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
-        .endpoint-url { color: #6366f1; font-family: monospace; font-size: 0.8em; flex: 1; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; min-width: 150px; }
-        .endpoint-stats { display: flex; gap: 12px; align-items: center; color: #6b7280; font-size: 0.8em; }
-        .endpoint-stats span { white-space: nowrap; }
-        .stat-success { color: #10b981; }
-        .stat-fail { color: #ef4444; }
-        .stat-avg { color: #6366f1; }
-        .endpoint-actions { display: flex; gap: 4px; align-items: center; flex-shrink: 0; }
-        .icon-btn {
-            width: 28px; height: 28px;
-            border: none; border-radius: 6px;
-            cursor: pointer; display: flex;
-            align-items: center; justify-content: center;
-            transition: all 0.2s; font-size: 14px;
-        }
-        .icon-btn:hover { transform: scale(1.1); }
-        .icon-btn.edit { background: #e0e7ff; color: #4f46e5; }
-        .icon-btn.toggle-on { background: #fef3c7; color: #d97706; }
-        .icon-btn.toggle-off { background: #d1fae5; color: #059669; }
-        .icon-btn.alert-on { background: #d1fae5; color: #059669; }
-        .icon-btn.alert-off { background: #fef3c7; color: #d97706; }
-        .icon-btn.delete { background: #fee2e2; color: #dc2626; }
-        .icon-btn.delete:hover { background: #fecaca; }
-        .history-mini { display: flex; gap: 1px; align-items: flex-end; height: 16px; }
-        .history-mini .bar { width: 3px; border-radius: 1px; }
-        .history-mini .bar.success { background: #10b981; height: 100%; }
-        .history-mini .bar.failure { background: #ef4444; height: 100%; }
-        .history-mini .bar.unknown { background: #9ca3af; height: 50%; }
-        .error-message {
-            background: #fef2f2;
-            border-left: 4px solid #ef4444;
-            padding: 10px;
-            margin-top: 10px;
-            border-radius: 4px;
-            color: #991b1b;
-            font-size: 0.85em;
-        }
-        .refresh-info { text-align: center; color: white; margin-top: 20px; font-size: 0.9em; }
-        .loading { text-align: center; padding: 40px; color: white; font-size: 1.2em; }
-        @keyframes pulse { 0%, 100% { opacity: 1; } 50% { opacity: 0.5; } }
-        .pulse { animation: pulse 2s cubic-bezier(0.4, 0, 0.6, 1) infinite; }
-        
-        /* Modal styles */
-        .modal { display: none; position: fixed; z-index: 1000; left: 0; top: 0; width: 100%; height: 100%; background: rgba(0,0,0,0.5); }
-        .modal.active { display: flex; align-items: center; justify-content: center; }
-        .modal-content {
-            background: white;
-            padding: 30px;
-            border-radius: 12px;
-            width: 90%;
-            max-width: 500px;
-            max-height: 90vh;
-            overflow-y: auto;
-        }
-        .modal-header { display: flex; justify-content: space-between; align-items: center; margin-bottom: 20px; }
-        .modal-header h2 { color: #333; font-size: 1.5em; }
-        .modal-close { background: none; border: none; font-size: 1.5em; cursor: pointer; color: #666; }
-        .form-group { margin-bottom: 15px; }
-        .form-group label { display: block; margin-bottom: 5px; color: #374151; font-weight: 500; }
-        .form-group input, .form-group select {
-            width: 100%;
-            padding: 10px;
-            border: 1px solid #d1d5db;
-            border-radius: 6px;
-            font-size: 1em;
-        }
-        .form-group input:focus, .form-group select:focus { outline: none; border-color: #6366f1; }
-        .form-actions { display: flex; gap: 10px; justify-content: flex-end; margin-top: 20px; }
-        .toast {
-            position: fixed;
-            bottom: 20px;
-            right: 20px;
-            padding: 15px 25px;
-            border-radius: 8px;
-            color: white;
-            font-weight: 500;
-            z-index: 2000;
-            animation: slideIn 0.3s ease;
-        }
-        .toast.success { background: #10b981; }
-        .toast.error { background: #ef4444; }
-        @keyframes slideIn { from { transform: translateX(100%); opacity: 0; } to { transform: translateX(0); opacity: 1; } }
-        
-        /* History chart styles */
-        .history-chart {
-            height: 24px;
-            display: flex;
-            align-items: flex-end;
-            gap: 1px;
-            padding: 4px;
-            margin: 4px 0;
-            background: #f9fafb;
-            border-radius: 4px;
-            overflow: hidden;
-        }
-        .history-bar {
-            flex: 1;
-            min-width: 2px;
-            max-width: 4px;
-            border-radius: 1px 1px 0 0;
-        }
-        .history-bar.success { background: #10b981; }
-        .history-bar.failure { background: #ef4444; }
-        .history-bar.unknown { background: #9ca3af; }
-        .success-count .detail-value { color: #10b981; }
-        .failure-count .detail-value { color: #ef4444; }
-        .avg-response { color: #6366f1; }
-        .editable { cursor: pointer; border-bottom: 1px dashed #6366f1; }
-        .editable:hover { background: #eef2ff; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <div>
-                <h1>Site Watch</h1>
-                <p>Real-time application health monitoring</p>
-            </div>
-            <button class="btn btn-primary" onclick="openAddModal()">+ Add Endpoint</button>
-        </div>
-        
-        <div class="stats" id="stats">
-            <div class="stat-card"><h3>Total</h3><div class="value" id="total-endpoints">-</div></div>
-            <div class="stat-card healthy"><h3>Healthy</h3><div class="value" id="healthy-count">-</div></div>
-            <div class="stat-card unhealthy"><h3>Unhealthy</h3><div class="value" id="unhealthy-count">-</div></div>
-            <div class="stat-card"><h3>Disabled</h3><div class="value" id="disabled-count">-</div></div>
-        </div>
-        
-        <div class="endpoints" id="endpoints">
-            <div class="loading pulse">Loading endpoint status...</div>
-        </div>
-        
-        <div class="refresh-info">Auto-refreshing every 30 seconds • Last updated: <span id="last-update">-</span></div>
-    </div>
-
-    <!-- Add Endpoint Modal -->
-    <div class="modal" id="addModal">
-        <div class="modal-content">
-            <div class="modal-header">
-                <h2>Add New Endpoint</h2>
-                <button class="modal-close" onclick="closeAddModal()">&times;</button>
-            </div>
-            <form id="addForm" onsubmit="addEndpoint(event)">
-                <div class="form-group">
-                    <label>Name *</label>
-                    <input type="text" id="ep-name" required placeholder="My API">
-                </div>
-                <div class="form-group">
-                    <label>URL *</label>
-                    <input type="url" id="ep-url" required placeholder="https://api.example.com/health">
-                </div>
-                <div class="form-group">
-                    <label>Method</label>
-                    <select id="ep-method">
-                        <option value="GET">GET</option>
-                        <option value="POST">POST</option>
-                        <option value="HEAD">HEAD</option>
-                    </select>
-                </div>
-                <div class="form-group">
-                    <label>Check Interval</label>
-                    <input type="text" id="ep-interval" placeholder="30s" value="30s">
-                </div>
-                <div class="form-group">
-                    <label>Timeout</label>
-                    <input type="text" id="ep-timeout" placeholder="10s" value="10s">
-                </div>
-                <div class="form-group">
-                    <label>Expected Status Code</label>
-                    <input type="number" id="ep-status" placeholder="200" value="200">
-                </div>
-                <div class="form-group">
-                    <label>Failure Threshold</label>
-                    <input type="number" id="ep-failure" placeholder="3" value="3">
-                </div>
-                <div class="form-group">
-                    <label>Success Threshold</label>
-                    <input type="number" id="ep-success" placeholder="2" value="2">
-                </div>
-                <div class="form-actions">
-                    <button type="button" class="btn btn-secondary" onclick="closeAddModal()">Cancel</button>
-                    <button type="submit" class="btn btn-primary">Add Endpoint</button>
-                </div>
-            </form>
-        </div>
-    </div>
-
-    <!-- Edit Endpoint Modal -->
-    <div class="modal" id="editModal">
-        <div class="modal-content">
-            <div class="modal-header">
-                <h2>Edit: <span id="edit-name"></span></h2>
-                <button class="modal-close" onclick="closeEditModal()">&times;</button>
-            </div>
-            <form id="editForm" onsubmit="updateEndpoint(event)">
-                <input type="hidden" id="edit-id">
-                <div class="form-group">
-                    <label>Check Interval</label>
-                    <input type="text" id="edit-interval" placeholder="30s">
-                </div>
-                <div class="form-group">
-                    <label>Timeout</label>
-                    <input type="text" id="edit-timeout" placeholder="10s">
-                </div>
-                <div class="form-group">
-                    <label>Failure Threshold</label>
-                    <input type="number" id="edit-failure" placeholder="3">
-                </div>
-                <div class="form-group">
-                    <label>Success Threshold</label>
-                    <input type="number" id="edit-success" placeholder="2">
-                </div>
-                <div class="form-actions">
-                    <button type="button" class="btn btn-secondary" onclick="closeEditModal()">Cancel</button>
-                    <button type="submit" class="btn btn-primary">Save</button>
-                </div>
-            </form>
-        </div>
-    </div>
-
-    <!-- History Modal -->
-    <div class="modal" id="historyModal">
-        <div class="modal-content" style="max-width: 900px;">
-            <div class="modal-header">
-                <h2>History: <span id="history-name"></span></h2>
-                <button class="modal-close" onclick="closeHistoryModal()">&times;</button>
-            </div>
-            <div id="history-stats" style="display:flex;gap:20px;margin-bottom:15px;padding:10px;background:#f9fafb;border-radius:6px;flex-wrap:wrap;">
-                <div><strong>Total Checks:</strong> <span id="hist-total">-</span></div>
-                <div><strong>Healthy:</strong> <span id="hist-healthy" style="color:#10b981;">-</span></div>
-                <div><strong>Unhealthy:</strong> <span id="hist-unhealthy" style="color:#ef4444;">-</span></div>
-                <div><strong>Uptime:</strong> <span id="hist-uptime" style="color:#6366f1;">-</span></div>
-                <div><strong>Avg Response:</strong> <span id="hist-avg">-</span></div>
-            </div>
-            <div style="margin-bottom:10px;font-weight:600;color:#374151;">Status Timeline (last 2000 checks)</div>
-            <div id="history-chart-large" style="height:80px;display:flex;align-items:flex-end;gap:1px;background:#f9fafb;border-radius:6px;padding:8px;margin-bottom:5px;"></div>
-            <div id="timeline-x-axis" style="display:flex;justify-content:space-between;font-size:10px;color:#6b7280;padding:0 8px;margin-bottom:20px;"></div>
-            <div style="margin-bottom:10px;font-weight:600;color:#374151;">Response Time Chart (ms)</div>
-            <div style="position:relative;height:180px;background:#f9fafb;border-radius:6px;padding:10px;margin-bottom:10px;">
-                <canvas id="response-chart" style="width:100%;height:100%;"></canvas>
-            </div>
-            <div id="chart-tooltip" style="display:none;position:absolute;background:#1f2937;color:white;padding:6px 10px;border-radius:4px;font-size:12px;pointer-events:none;z-index:100;"></div>
-        </div>
-    </div>
-
-    <script>
-        let endpointsData = {};
-
-        function formatDuration(ms) {
-            if (ms < 1000) return ms.toFixed(0) + 'ms';
-            return (ms / 1000).toFixed(2) + 's';
-        }
-
-        function formatTime(timestamp) {
-            return new Date(timestamp).toLocaleTimeString();
-        }
-
-        function formatInterval(ns) {
-            if (!ns) return '30s';
-            const seconds = ns / 1000000000;
-            if (seconds >= 60) return Math.round(seconds / 60) + 'm';
-            return Math.round(seconds) + 's';
-        }
-
-        async function loadHistoryChart(endpointId) {
-            try {
-                const resp = await fetch('/api/history?id=' + endpointId);
-                if (!resp.ok) return;
-                const data = await resp.json();
-                const chart = document.getElementById('chart-' + endpointId);
-                if (!chart) return;
-                
-                chart.innerHTML = '';
-                const records = (data.records || []).slice(0, 50).reverse();
-                
-                if (records.length === 0) {
-                    chart.innerHTML = '<span style="color:#9ca3af;font-size:0.7em;margin:auto;">No history</span>';
-                    return;
-                }
-                
-                records.slice(0, 20).forEach(record => {
-                    const bar = document.createElement('div');
-                    bar.className = 'bar';
-                    if (record.status === 'healthy') {
-                        bar.classList.add('success');
-                    } else if (record.status === 'unhealthy') {
-                        bar.classList.add('failure');
-                    } else {
-                        bar.classList.add('unknown');
-                    }
-                    const respTime = record.response_time ? formatDuration(record.response_time / 1000000) : '-';
-                    bar.title = record.status + ' | ' + respTime + ' | ' + new Date(record.timestamp).toLocaleString();
-                    chart.appendChild(bar);
-                });
-                
-                // Update average response time
-                const avgEl = document.getElementById('avg-' + endpointId);
-                if (avgEl && data.avg_response_time_ms) {
-                    avgEl.textContent = formatDuration(data.avg_response_time_ms);
-                }
-            } catch (err) {
-                console.error('Error loading history:', err);
-            }
-        }
-
-        function showToast(message, type = 'success') {
-            const toast = document.createElement('div');
-            toast.className = 'toast ' + type;
-            toast.textContent = message;
-            document.body.appendChild(toast);
-            setTimeout(() => toast.remove(), 3000);
-        }
-
-        function openAddModal() {
-            document.getElementById('addModal').classList.add('active');
-        }
-
-        function closeAddModal() {
-            document.getElementById('addModal').classList.remove('active');
-            document.getElementById('addForm').reset();
-        }
-
-        async function addEndpoint(e) {
-            e.preventDefault();
-            const data = {
-                name: document.getElementById('ep-name').value,
-                url: document.getElementById('ep-url').value,
-                method: document.getElementById('ep-method').value,
-                check_interval: document.getElementById('ep-interval').value,
-                timeout: document.getElementById('ep-timeout').value,
-                expected_status: parseInt(document.getElementById('ep-status').value) || 200,
-                failure_threshold: parseInt(document.getElementById('ep-failure').value) || 3,
-                success_threshold: parseInt(document.getElementById('ep-success').value) || 2
-            };
-            try {
-                const resp = await fetch('/api/endpoints/add', {
-                    method: 'POST',
-                    headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify(data)
-                });
-                if (resp.ok) {
-                    showToast('Endpoint added successfully');
-                    closeAddModal();
-                    updateDashboard();
-                } else {
-                    const err = await resp.text();
-                    showToast(err, 'error');
-                }
-            } catch (err) {
-                showToast('Failed to add endpoint', 'error');
-            }
-        }
-
-        async function deleteEndpoint(id, name) {
-            console.log('Delete endpoint called with id:', id, 'name:', name);
-            if (!confirm('Delete endpoint "' + name + '"?')) return;
-            try {
-                console.log('Sending delete request for id:', id);
-                const resp = await fetch('/api/endpoints/delete', {
-                    method: 'POST',
-                    headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify({id: id})
-                });
-                console.log('Delete response status:', resp.status);
-                const text = await resp.text();
-                console.log('Delete response body:', text);
-                if (resp.ok) {
-                    showToast('Endpoint deleted');
-                    updateDashboard();
-                } else {
-                    showToast('Failed to delete endpoint: ' + text, 'error');
-                }
-            } catch (err) {
-                console.error('Delete error:', err);
-                showToast('Failed to delete endpoint', 'error');
-            }
-        }
-
-        async function toggleEndpoint(id, enable) {
-            const action = enable ? 'enable' : 'disable';
-            try {
-                const resp = await fetch('/api/endpoints/' + action, {
-                    method: 'POST',
-                    headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify({id: id})
-                });
-                if (resp.ok) {
-                    showToast('Endpoint ' + action + 'd');
-                    updateDashboard();
-                } else {
-                    showToast('Failed to ' + action + ' endpoint', 'error');
-                }
-            } catch (err) {
-                showToast('Failed to ' + action + ' endpoint', 'error');
-            }
-        }
-
-        async function toggleAlerts(id, suppress) {
-            const action = suppress ? 'suppress' : 'unsuppress';
-            try {
-                const resp = await fetch('/api/endpoints/' + action, {
-                    method: 'POST',
-                    headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify({id: id})
-                });
-                if (resp.ok) {
-                    showToast(suppress ? 'Alerts suppressed' : 'Alerts enabled');
-                    updateDashboard();
-                } else {
-                    showToast('Failed to update alerts', 'error');
-                }
-            } catch (err) {
-                showToast('Failed to update alerts', 'error');
-            }
-        }
-
-        async function updateDashboard() {
-            try {
-                const [statusResp, endpointsResp] = await Promise.all([
-                    fetch('/api/status'),
-                    fetch('/api/endpoints')
-                ]);
-                const statusData = await statusResp.json();
-                const endpointsDbData = await endpointsResp.json();
-                
-                // Create a map of endpoint settings from DB
-                const dbEndpoints = {};
-                (endpointsDbData.endpoints || []).forEach(ep => {
-                    dbEndpoints[ep.id] = ep;
-                });
-
-                let healthy = 0, unhealthy = 0, disabled = 0, total = 0;
-                
-                const endpointsContainer = document.getElementById('endpoints');
-                endpointsContainer.innerHTML = '';
-
-                // Combine status data with DB settings
-                const allEndpoints = [];
-                Object.entries(statusData.endpoints || {}).forEach(([name, endpoint]) => {
-                    const dbEp = Object.values(dbEndpoints).find(e => e.name === endpoint.name) || {};
-                    allEndpoints.push({...endpoint, ...dbEp, id: endpoint.id || dbEp.id || name});
-                });
-
-                // Also add any DB endpoints not in status
-                Object.values(dbEndpoints).forEach(dbEp => {
-                    if (!allEndpoints.find(e => e.id === dbEp.id)) {
-                        allEndpoints.push({...dbEp, status: 'unknown'});
-                    }
-                });
-
-                allEndpoints.forEach(endpoint => {
-                    total++;
-                    const isEnabled = endpoint.enabled !== false;
-                    const isSuppressed = endpoint.alerts_suppressed === true;
-                    
-                    if (!isEnabled) disabled++;
-                    else if (endpoint.status === 'healthy') healthy++;
-                    else if (endpoint.status === 'unhealthy') unhealthy++;
-
-                    const row = document.createElement('div');
-                    row.className = 'endpoint-row ' + endpoint.status + (isEnabled ? '' : ' disabled');
-                    
-                    row.innerHTML = ` + "`" + `
-                        <div class="endpoint-status ${endpoint.status}"></div>
-                        <div class="endpoint-name" title="${endpoint.name}">${endpoint.name}</div>
-                        <div class="endpoint-url" title="${endpoint.url}">${endpoint.url}</div>
-                        <div class="history-mini" id="chart-${endpoint.id}"></div>
-                        <div class="endpoint-stats">
-                            <span title="Response Time">${formatDuration(endpoint.response_time_ms || 0)}</span>
-                            <span class="stat-avg" title="Avg Response" id="avg-${endpoint.id}">-</span>
-                            <span title="Interval">${formatInterval(endpoint.check_interval)}</span>
-                            <span class="stat-success" title="Consecutive Successes">✓${endpoint.consecutive_successes || 0}</span>
-                            <span class="stat-fail" title="Consecutive Failures">✗${endpoint.consecutive_failures || 0}</span>
-                        </div>
-                        <div class="endpoint-actions" data-endpoint-id="${endpoint.id}" data-endpoint-name="${endpoint.name}" 
-                             data-interval="${formatInterval(endpoint.check_interval)}" data-timeout="${formatInterval(endpoint.timeout)}"
-                             data-failure="${endpoint.failure_threshold || 3}" data-success="${endpoint.success_threshold || 2}">
-                            <button class="icon-btn edit" data-action="history" title="View History">📊</button>
-                            <button class="icon-btn edit" data-action="edit" title="Edit">✏️</button>
-                            <button class="icon-btn ${isEnabled ? 'toggle-on' : 'toggle-off'}" data-action="${isEnabled ? 'disable' : 'enable'}" title="${isEnabled ? 'Disable' : 'Enable'}">${isEnabled ? '⏸️' : '▶️'}</button>
-                            <button class="icon-btn ${isSuppressed ? 'alert-on' : 'alert-off'}" data-action="${isSuppressed ? 'unsuppress' : 'suppress'}" title="${isSuppressed ? 'Enable Alerts' : 'Suppress Alerts'}">${isSuppressed ? '🔔' : '🔕'}</button>
-                            <button class="icon-btn delete" data-action="delete" title="Delete">🗑️</button>
-                        </div>
-                    ` + "`" + `;
-                    
-                    endpointsContainer.appendChild(row);
-                    
-                    // Load history chart for this endpoint
-                    loadHistoryChart(endpoint.id);
-                });
-
-                document.getElementById('total-endpoints').textContent = total;
-                document.getElementById('healthy-count').textContent = healthy;
-                document.getElementById('unhealthy-count').textContent = unhealthy;
-                document.getElementById('disabled-count').textContent = disabled;
-                document.getElementById('last-update').textContent = new Date().toLocaleTimeString();
-            } catch (error) {
-                console.error('Error fetching status:', error);
-            }
-        }
-
-        // Event delegation for action buttons
-        document.addEventListener('click', async function(e) {
-            const btn = e.target.closest('[data-action]');
-            if (!btn) return;
-            
-            const action = btn.dataset.action;
-            const actionsDiv = btn.closest('.endpoint-actions');
-            const id = actionsDiv ? actionsDiv.dataset.endpointId : '';
-            const name = actionsDiv ? actionsDiv.dataset.endpointName : id;
-            
-            console.log('Button clicked:', action, id, name);
-            
-            if (action === 'delete') {
-                if (!confirm('Delete endpoint "' + name + '"?')) return;
-                try {
-                    const resp = await fetch('/api/endpoints/delete', {
-                        method: 'POST',
-                        headers: {'Content-Type': 'application/json'},
-                        body: JSON.stringify({id: id})
-                    });
-                    if (resp.ok) {
-                        showToast('Endpoint deleted');
-                        updateDashboard();
-                    } else {
-                        const text = await resp.text();
-                        showToast('Failed: ' + text, 'error');
-                    }
-                } catch (err) {
-                    showToast('Failed to delete', 'error');
-                }
-            } else if (action === 'enable' || action === 'disable') {
-                try {
-                    const resp = await fetch('/api/endpoints/' + action, {
-                        method: 'POST',
-                        headers: {'Content-Type': 'application/json'},
-                        body: JSON.stringify({id: id})
-                    });
-                    if (resp.ok) {
-                        showToast('Endpoint ' + action + 'd');
-                        updateDashboard();
-                    } else {
-                        showToast('Failed to ' + action, 'error');
-                    }
-                } catch (err) {
-                    showToast('Failed to ' + action, 'error');
-                }
-            } else if (action === 'suppress' || action === 'unsuppress') {
-                try {
-                    const resp = await fetch('/api/endpoints/' + action, {
-                        method: 'POST',
-                        headers: {'Content-Type': 'application/json'},
-                        body: JSON.stringify({id: id})
-                    });
-                    if (resp.ok) {
-                        showToast(action === 'suppress' ? 'Alerts suppressed' : 'Alerts enabled');
-                        updateDashboard();
-                    } else {
-                        showToast('Failed to update alerts', 'error');
-                    }
-                } catch (err) {
-                    showToast('Failed to update alerts', 'error');
-                }
-            } else if (action === 'edit') {
-                openEditModal(id, name, actionsDiv.dataset.interval, actionsDiv.dataset.timeout, 
-                              actionsDiv.dataset.failure, actionsDiv.dataset.success);
-            } else if (action === 'history') {
-                openHistoryModal(id, name);
-            }
-        });
-
-        function openEditModal(id, name, interval, timeout, failure, success) {
-            document.getElementById('edit-id').value = id;
-            document.getElementById('edit-name').textContent = name;
-            document.getElementById('edit-interval').value = interval || '30s';
-            document.getElementById('edit-timeout').value = timeout || '10s';
-            document.getElementById('edit-failure').value = failure || 3;
-            document.getElementById('edit-success').value = success || 2;
-            document.getElementById('editModal').classList.add('active');
-        }
-
-        function closeEditModal() {
-            document.getElementById('editModal').classList.remove('active');
-        }
-
-        async function updateEndpoint(e) {
-            e.preventDefault();
-            const data = {
-                id: document.getElementById('edit-id').value,
-                check_interval: document.getElementById('edit-interval').value,
-                timeout: document.getElementById('edit-timeout').value,
-                failure_threshold: parseInt(document.getElementById('edit-failure').value) || 3,
-                success_threshold: parseInt(document.getElementById('edit-success').value) || 2
-            };
-            try {
-                const resp = await fetch('/api/endpoints/update', {
-                    method: 'POST',
-                    headers: {'Content-Type': 'application/json'},
-                    body: JSON.stringify(data)
-                });
-                if (resp.ok) {
-                    showToast('Endpoint updated');
-                    closeEditModal();
-                    updateDashboard();
-                } else {
-                    const err = await resp.text();
-                    showToast(err, 'error');
-                }
-            } catch (err) {
-                showToast('Failed to update', 'error');
-            }
-        }
-
-        async function openHistoryModal(id, name) {
-            document.getElementById('history-name').textContent = name;
-            document.getElementById('historyModal').classList.add('active');
-            
-            try {
-                const resp = await fetch('/api/history?id=' + id);
-                if (!resp.ok) return;
-                const data = await resp.json();
-                const records = data.records || [];
-                
-                // Calculate stats
-                let healthy = 0, unhealthy = 0;
-                records.forEach(r => {
-                    if (r.status === 'healthy') healthy++;
-                    else if (r.status === 'unhealthy') unhealthy++;
-                });
-                const total = records.length;
-                const uptime = total > 0 ? ((healthy / total) * 100).toFixed(1) : 0;
-                
-                document.getElementById('hist-total').textContent = total;
-                document.getElementById('hist-healthy').textContent = healthy;
-                document.getElementById('hist-unhealthy').textContent = unhealthy;
-                document.getElementById('hist-uptime').textContent = uptime + '%';
-                document.getElementById('hist-avg').textContent = data.avg_response_time_ms ? formatDuration(data.avg_response_time_ms) : '-';
-                
-                // Status timeline chart
-                const chartEl = document.getElementById('history-chart-large');
-                chartEl.innerHTML = '';
-                const displayRecords = records.slice(0, 2000).reverse();
-                const tooltip = document.getElementById('chart-tooltip');
-                displayRecords.forEach(r => {
-                    const bar = document.createElement('div');
-                    bar.style.cssText = 'flex:1;min-width:1px;max-width:3px;border-radius:1px 1px 0 0;cursor:pointer;';
-                    bar.style.background = r.status === 'healthy' ? '#10b981' : r.status === 'unhealthy' ? '#ef4444' : '#9ca3af';
-                    bar.style.height = '100%';
-                    const respTime = r.response_time ? formatDuration(r.response_time / 1000000) : '-';
-                    bar.onmouseenter = function(e) {
-                        tooltip.innerHTML = '<strong>' + r.status + '</strong><br>' + respTime + '<br>' + new Date(r.timestamp).toLocaleString();
-                        tooltip.style.display = 'block';
-                        tooltip.style.left = (e.clientX + 10) + 'px';
-                        tooltip.style.top = (e.clientY - 60) + 'px';
-                    };
-                    bar.onmousemove = function(e) {
-                        tooltip.style.left = (e.clientX + 10) + 'px';
-                        tooltip.style.top = (e.clientY - 60) + 'px';
-                    };
-                    bar.onmouseleave = function() {
-                        tooltip.style.display = 'none';
-                    };
-                    chartEl.appendChild(bar);
-                });
-                
-                // Add X-axis labels for Status Timeline
-                const timelineXAxis = document.getElementById('timeline-x-axis');
-                timelineXAxis.innerHTML = '';
-                if (displayRecords.length > 0) {
-                    const numLabels = 5;
-                    for (let i = 0; i < numLabels; i++) {
-                        const idx = Math.floor(i * (displayRecords.length - 1) / (numLabels - 1));
-                        const record = displayRecords[idx];
-                        const label = document.createElement('span');
-                        label.textContent = new Date(record.timestamp).toLocaleTimeString([], {hour: '2-digit', minute: '2-digit'});
-                        timelineXAxis.appendChild(label);
-                    }
-                }
-                
-                // Response time line chart
-                const canvas = document.getElementById('response-chart');
-                const ctx = canvas.getContext('2d');
-                const rect = canvas.parentElement.getBoundingClientRect();
-                canvas.width = rect.width - 20;
-                canvas.height = rect.height - 20;
-                
-                const responseTimes = displayRecords.map(r => r.response_time ? r.response_time / 1000000 : 0);
-                const maxTime = Math.max(...responseTimes, 1);
-                const padding = 40;
-                const chartWidth = canvas.width - padding * 2;
-                const chartHeight = canvas.height - 30;
-                
-                // Draw grid lines
-                ctx.strokeStyle = '#e5e7eb';
-                ctx.lineWidth = 1;
-                for (let i = 0; i <= 4; i++) {
-                    const y = 10 + (chartHeight / 4) * i;
-                    ctx.beginPath();
-                    ctx.moveTo(padding, y);
-                    ctx.lineTo(canvas.width - 10, y);
-                    ctx.stroke();
-                    
-                    // Y-axis labels
-                    ctx.fillStyle = '#6b7280';
-                    ctx.font = '10px sans-serif';
-                    ctx.textAlign = 'right';
-                    const val = Math.round(maxTime - (maxTime / 4) * i);
-                    ctx.fillText(val + 'ms', padding - 5, y + 3);
-                }
-                
-                // Draw X-axis labels for Response Time chart
-                ctx.fillStyle = '#6b7280';
-                ctx.font = '10px sans-serif';
-                ctx.textAlign = 'center';
-                if (displayRecords.length > 0) {
-                    const numXLabels = 5;
-                    for (let i = 0; i < numXLabels; i++) {
-                        const idx = Math.floor(i * (displayRecords.length - 1) / (numXLabels - 1));
-                        const record = displayRecords[idx];
-                        const x = padding + (idx / (displayRecords.length - 1)) * chartWidth;
-                        const timeStr = new Date(record.timestamp).toLocaleTimeString([], {hour: '2-digit', minute: '2-digit'});
-                        ctx.fillText(timeStr, x, chartHeight + 25);
-                    }
-                }
-                
-                // Draw line chart
-                if (responseTimes.length > 1) {
-                    ctx.beginPath();
-                    ctx.strokeStyle = '#6366f1';
-                    ctx.lineWidth = 2;
-                    
-                    responseTimes.forEach((time, i) => {
-                        const x = padding + (i / (responseTimes.length - 1)) * chartWidth;
-                        const y = 10 + chartHeight - (time / maxTime) * chartHeight;
-                        if (i === 0) ctx.moveTo(x, y);
-                        else ctx.lineTo(x, y);
-                    });
-                    ctx.stroke();
-                    
-                    // Draw area fill
-                    ctx.lineTo(padding + chartWidth, 10 + chartHeight);
-                    ctx.lineTo(padding, 10 + chartHeight);
-                    ctx.closePath();
-                    ctx.fillStyle = 'rgba(99, 102, 241, 0.1)';
-                    ctx.fill();
-                    
-                    // Draw dots for unhealthy points
-                    displayRecords.forEach((r, i) => {
-                        if (r.status === 'unhealthy') {
-                            const x = padding + (i / (responseTimes.length - 1)) * chartWidth;
-                            const time = r.response_time ? r.response_time / 1000000 : 0;
-                            const y = 10 + chartHeight - (time / maxTime) * chartHeight;
-                            ctx.beginPath();
-                            ctx.arc(x, y, 4, 0, Math.PI * 2);
-                            ctx.fillStyle = '#ef4444';
-                            ctx.fill();
-                        }
-                    });
-                    
-                    // Add hover tooltip for response time chart
-                    const tooltip = document.getElementById('chart-tooltip');
-                    canvas.onmousemove = function(e) {
-                        const canvasRect = canvas.getBoundingClientRect();
-                        const mouseX = e.clientX - canvasRect.left;
-                        const idx = Math.round(((mouseX - padding) / chartWidth) * (displayRecords.length - 1));
-                        if (idx >= 0 && idx < displayRecords.length) {
-                            const r = displayRecords[idx];
-                            const respTime = r.response_time ? formatDuration(r.response_time / 1000000) : '-';
-                            tooltip.innerHTML = '<strong>' + r.status + '</strong><br>' + respTime + '<br>' + new Date(r.timestamp).toLocaleString();
-                            tooltip.style.display = 'block';
-                            tooltip.style.left = (e.clientX + 10) + 'px';
-                            tooltip.style.top = (e.clientY - 60) + 'px';
-                        }
-                    };
-                    canvas.onmouseleave = function() {
-                        tooltip.style.display = 'none';
-                    };
-                }
-            } catch (err) {
-                console.error('Error loading history:', err);
-            }
-        }
-
-        function closeHistoryModal() {
-            document.getElementById('historyModal').classList.remove('active');
-        }
-
-        updateDashboard();
-        setInterval(updateDashboard, 30000);
-    </script>
-</body>
-</html>`
-
-	t, err := template.New("dashboard").Parse(tmpl)
-	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
 		return
 	}
 
+	refresh := s.config.RefreshInterval
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	chartPoints := s.config.ChartPoints
+	if chartPoints <= 0 {
+		chartPoints = DefaultChartPoints
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	t.Execute(w, nil)
+	data := struct {
+		pageBranding
+		ReadOnly        bool
+		Version         string
+		RefreshInterval time.Duration
+		RefreshMillis   int64
+		ChartPoints     int
+	}{
+		pageBranding:    s.branding(),
+		ReadOnly:        s.config.ReadOnly,
+		Version:         version,
+		RefreshInterval: refresh,
+		RefreshMillis:   refresh.Milliseconds(),
+		ChartPoints:     chartPoints,
+	}
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		log.Printf("Dashboard template error: %v", err)
+	}
+}
+
+// pageBranding is the title and accent color the dashboard pages show
+type pageBranding struct {
+	Title       string
+	AccentColor string
+}
+
+// branding returns the dashboard branding from server.title and
+// server.accent_color
+func (s *Server) branding() pageBranding {
+	title := s.config.Title
+	if title == "" {
+		title = DefaultTitle
+	}
+	return pageBranding{Title: title, AccentColor: s.config.AccentColor}
+}
+
+// checkBackupToken guards database-level operations, which are only available
+// when server.backup_token is set and require it as a bearer token. It writes
+// the error response, using disabledMsg when no token is set, and reports
+// whether the request may proceed.
+func (s *Server) checkBackupToken(w http.ResponseWriter, r *http.Request, disabledMsg string) bool {
+	return checkBearerToken(w, r, s.config.BackupToken, disabledMsg)
+}
+
+// checkBearerToken requires want as a bearer token, writing the error
+// response and reporting whether the request may proceed. An empty want
+// means the feature is disabled and answers 404 with disabledMsg.
+func checkBearerToken(w http.ResponseWriter, r *http.Request, want, disabledMsg string) bool {
+	if want == "" {
+		http.Error(w, disabledMsg, http.StatusNotFound)
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="cronzee"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// handleBackup streams a point-in-time copy of the database. It is only
+// available when server.backup_token is set, and requires that token as a
+// bearer token since the backup includes endpoint headers and credentials.
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if !s.checkBackupToken(w, r, "Backups are disabled; set server.backup_token to enable them") {
+		return
+	}
+
+	store, ok := s.db.(BackupStore)
+	if !ok {
+		http.Error(w, "The configured database driver does not support backups", http.StatusNotImplemented)
+		return
+	}
+
+	filename := fmt.Sprintf("cronzee-%s.db", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if err := store.Backup(w); err != nil {
+		// Headers are already sent, so the client sees a truncated download
+		log.Printf("Error writing database backup: %v", err)
+		return
+	}
+	log.Printf("Database backup sent to %s", r.RemoteAddr)
+}
+
+// handleCompact shrinks the database file to reclaim space left by deleted
+// history. Like backups it requires server.backup_token.
+func (s *Server) handleCompact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.checkBackupToken(w, r, "Compaction is disabled; set server.backup_token to enable it") {
+		return
+	}
+
+	store, ok := s.db.(CompactStore)
+	if !ok {
+		http.Error(w, "The configured database driver does not support compaction", http.StatusNotImplemented)
+		return
+	}
+
+	start := time.Now()
+	before, after, err := store.Compact()
+	if err != nil {
+		log.Printf("Error compacting database: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Database compacted from %d to %d bytes in %v", before, after, time.Since(start).Round(time.Millisecond))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"size_before": before,
+		"size_after":  after,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+}
+
+// handleCleanup removes history past retention right away instead of at the
+// next scheduled cleanup, e.g. after lowering the retention. Like compaction
+// it requires server.backup_token.
+func (s *Server) handleCleanup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.checkBackupToken(w, r, "Cleanup is disabled; set server.backup_token to enable it") {
+		return
+	}
+
+	start := time.Now()
+	deleted, err := s.db.CleanupOldData()
+	if err != nil {
+		log.Printf("Error during cleanup: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Cleanup requested by %s deleted %d records", r.RemoteAddr, deleted)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"deleted":     deleted,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+}
+
+// handleVersion returns the version and build details of the running binary
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildInfo())
+}
+
+// handleOpenAPI serves the OpenAPI 3 document for the API
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// handleStatic serves the embedded dashboard CSS and JS
+func (s *Server) handleStatic() http.Handler {
+	static, err := fs.Sub(webAssets, "web")
+	if err != nil {
+		log.Fatalf("Failed to load embedded web assets: %v", err)
+	}
+	files := http.StripPrefix("/static/", http.FileServer(http.FS(static)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		files.ServeHTTP(w, r)
+	})
 }
 
 // StatusResponse represents the API response for endpoint status
 type StatusResponse struct {
 	Endpoints map[string]EndpointStatus `json:"endpoints"`
+	Order     []string                  `json:"order"` // endpoint IDs in the requested sort order
+	Counts    StatusCounts              `json:"counts"`
 	Timestamp time.Time                 `json:"timestamp"`
 }
 
+// StatusCounts counts every endpoint by status, whether or not it matched the
+// search, for the dashboard's summary and tab title
+type StatusCounts struct {
+	Total     int `json:"total"`
+	Healthy   int `json:"healthy"`
+	Unhealthy int `json:"unhealthy"`
+	Disabled  int `json:"disabled"`
+}
+
 // EndpointStatus represents the status of a single endpoint for API response
 type EndpointStatus struct {
-	ID                   string  `json:"id"`
-	Name                 string  `json:"name"`
-	URL                  string  `json:"url"`
-	Method               string  `json:"method"`
-	Status               string  `json:"status"`
-	LastCheck            string  `json:"last_check"`
-	LastError            string  `json:"last_error"`
-	ResponseTimeMs       float64 `json:"response_time_ms"`
-	ConsecutiveFailures  int     `json:"consecutive_failures"`
-	ConsecutiveSuccesses int     `json:"consecutive_successes"`
-}
-
-// handleAPIStatus returns JSON status of all endpoints
+	ID                   string        `json:"id"`
+	Name                 string        `json:"name"`
+	URL                  string        `json:"url"`
+	URLs                 []string      `json:"urls,omitempty"`
+	ActiveURL            string        `json:"active_url,omitempty"`
+	RemoteAddr           string        `json:"remote_addr,omitempty"`
+	Method               string        `json:"method"`
+	Status               string        `json:"status"`
+	LastCheck            string        `json:"last_check"`
+	NextCheck            string        `json:"next_check"`
+	LastStatusChange     string        `json:"last_status_change,omitempty"`
+	CheckInterval        string        `json:"check_interval"`
+	LastError            string        `json:"last_error"`
+	ResponseTimeMs       float64       `json:"response_time_ms"`
+	Uptime24h            *float64      `json:"uptime_24h,omitempty"`
+	ResponseTimeSLOMs    float64       `json:"response_time_slo_ms,omitempty"`
+	SLOBreach            bool          `json:"slo_breach"`
+	SLOCompliance24h     *float64      `json:"slo_compliance_24h,omitempty"`
+	ConsecutiveFailures  int           `json:"consecutive_failures"`
+	ConsecutiveSuccesses int           `json:"consecutive_successes"`
+	IncidentID           string        `json:"incident_id,omitempty"`
+	Acknowledged         bool          `json:"acknowledged"`
+	AcknowledgedAt       string        `json:"acknowledged_at,omitempty"`
+	SnoozeUntil          string        `json:"snooze_until,omitempty"`
+	Flapping             bool          `json:"flapping"`
+	Degraded             bool          `json:"degraded"`
+	BaselineMs           float64       `json:"baseline_response_time_ms,omitempty"`
+	DependsOn            []string      `json:"depends_on,omitempty"`
+	Tags                 []string      `json:"tags,omitempty"`
+}
+
+// endpointStatus builds the API view of an endpoint's state. Caller must hold
+// state.mu.
+func (s *Server) endpointStatus(state *EndpointState) EndpointStatus {
+	status := EndpointStatus{
+		ID:                   state.ID,
+		Name:                 state.Endpoint.Name,
+		URL:                  state.Endpoint.URL,
+		URLs:                 state.Endpoint.URLs,
+		ActiveURL:            state.ActiveURL,
+		RemoteAddr:           state.RemoteAddr,
+		Method:               state.Endpoint.Method,
+		Status:               string(state.Status),
+		LastCheck:            state.LastCheck.Format(time.RFC3339),
+		NextCheck:            state.NextCheck.Format(time.RFC3339),
+		CheckInterval:        state.CheckInterval.String(),
+		LastError:            state.LastError,
+		ResponseTimeMs:       float64(state.ResponseTime.Microseconds()) / 1000.0,
+		ConsecutiveFailures:  state.ConsecutiveFailures,
+		ConsecutiveSuccesses: state.ConsecutiveSuccesses,
+		Acknowledged:         state.Acknowledged,
+		Flapping:             state.Flapping,
+		Degraded:             state.Degraded,
+		DependsOn:            state.Endpoint.DependsOn,
+		Tags:                 state.Endpoint.Tags,
+	}
+	if state.Status == StatusUnhealthy {
+		status.IncidentID = incidentID(state)
+	}
+	if state.Acknowledged {
+		status.AcknowledgedAt = state.AcknowledgedAt.Format(time.RFC3339)
+	}
+	if baseline, ok := s.monitor.Baseline(state.ID); ok {
+		status.BaselineMs = float64(baseline.Microseconds()) / 1000.0
+	}
+	if uptime, ok := s.monitor.Uptime(state.ID); ok {
+		status.Uptime24h = &uptime
+	}
+	if slo := state.Endpoint.ResponseTimeSLO; slo > 0 {
+		status.ResponseTimeSLOMs = float64(slo.Microseconds()) / 1000.0
+		status.SLOBreach = state.ResponseTime > slo
+		if compliance, ok := s.monitor.SLOCompliance(state.ID); ok {
+			status.SLOCompliance24h = &compliance
+		}
+	}
+	if !state.LastStatusChange.IsZero() {
+		status.LastStatusChange = state.LastStatusChange.Format(time.RFC3339)
+	}
+	if snoozed(state) {
+		status.SnoozeUntil = state.SnoozeUntil.Format(time.RFC3339)
+	}
+	return status
+}
+
+// handleAPIStatus returns JSON status of all endpoints, filtered by ?search=.
+// Order lists them as sorted by ?sort=, since a JSON object has no order.
 func (s *Server) handleAPIStatus(w http.ResponseWriter, r *http.Request) {
+	query, err := parseEndpointListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	states := s.monitor.GetStatus()
 	
 	response := StatusResponse{
 		Endpoints: make(map[string]EndpointStatus),
+		Order:     []string{},
 		Timestamp: time.Now(),
 	}
 
-	for name, state := range states {
+	var statuses []EndpointStatus
+	keys := make(map[string]endpointSortKey, len(states))
+	for _, state := range states {
 		state.mu.RLock()
-		response.Endpoints[name] = EndpointStatus{
-			ID:                   state.ID,
-			Name:                 state.Endpoint.Name,
-			URL:                  state.Endpoint.URL,
-			Method:               state.Endpoint.Method,
-			Status:               string(state.Status),
-			LastCheck:            state.LastCheck.Format(time.RFC3339),
-			LastError:            state.LastError,
-			ResponseTimeMs:       float64(state.ResponseTime.Microseconds()) / 1000.0,
-			ConsecutiveFailures:  state.ConsecutiveFailures,
-			ConsecutiveSuccesses: state.ConsecutiveSuccesses,
+		status := s.endpointStatus(state)
+		keys[status.ID] = stateSortKey(state)
+		response.Counts.Total++
+		switch {
+		case !state.Enabled:
+			response.Counts.Disabled++
+		case state.Status == StatusHealthy:
+			response.Counts.Healthy++
+		case state.Status == StatusUnhealthy:
+			response.Counts.Unhealthy++
 		}
 		state.mu.RUnlock()
+		if query.matches(status.ID, status.Name, status.URL, status.Tags) {
+			statuses = append(statuses, status)
+		}
+	}
+	query.sortStatuses(statuses, keys)
+	for _, status := range statuses {
+		response.Endpoints[status.ID] = status
+		response.Order = append(response.Order, status.ID)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleSelfHealth reports whether Cronzee itself is working: the monitor loop
+// is running and not stalled, and the database answers a cheap read. It returns
+// 503 otherwise so a watchdog can restart the process.
+func (s *Server) handleSelfHealth(w http.ResponseWriter, r *http.Request) {
+	monitor := s.monitor.Health()
+
+	response := map[string]interface{}{
+		"monitor":   monitor,
+		"database":  "ok",
+		"version":   version,
+		"timestamp": time.Now(),
+	}
+	healthy := monitor.Running && !monitor.Stalled
+	if err := s.db.Ping(); err != nil {
+		response["database"] = err.Error()
+		healthy = false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if healthy {
+		response["status"] = "ok"
+	} else {
+		response["status"] = "unhealthy"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
 // handleHealth returns the overall health status
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	states := s.monitor.GetStatus()
@@ -1014,28 +631,252 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// StatsResponse represents the aggregate summary returned by /api/stats
+type StatsResponse struct {
+	TotalEndpoints    int       `json:"total_endpoints"`
+	Healthy           int       `json:"healthy"`
+	Unhealthy         int       `json:"unhealthy"`
+	Degraded          int       `json:"degraded"`
+	Unknown           int       `json:"unknown"`
+	Disabled          int       `json:"disabled"`
+	Uptime24hPercent  float64   `json:"uptime_24h_percent"`
+	Checks24h         int       `json:"checks_24h"`
+	TotalChecks       int       `json:"total_checks"`
+	AvgResponseTimeMs float64   `json:"avg_response_time_ms"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// GroupStatus is the rolled-up health of all endpoints sharing a tag
+type GroupStatus struct {
+	Name             string  `json:"name"`
+	Status           string  `json:"status"`
+	Summary          string  `json:"summary"`
+	Total            int     `json:"total"`
+	Healthy          int     `json:"healthy"`
+	Unhealthy        int     `json:"unhealthy"`
+	Unknown          int     `json:"unknown"`
+	Disabled         int     `json:"disabled"`
+	Uptime24hPercent float64 `json:"uptime_24h_percent"`
+	Checks24h        int     `json:"checks_24h"`
+}
+
+// handleGroups returns one rollup per endpoint tag: healthy if every enabled
+// endpoint is up, unhealthy if all are down, degraded if only some are down
+// and unknown while some haven't been checked yet but none are down
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	states := s.monitor.GetStatus()
+	stats, err := s.db.GetHistoryStats(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	groups := make(map[string]*GroupStatus)
+	healthyChecks := make(map[string]int)
+	for _, state := range states {
+		state.mu.RLock()
+		id := state.ID
+		tags := state.Endpoint.Tags
+		enabled := state.Enabled
+		status := state.Status
+		state.mu.RUnlock()
+
+		if len(tags) == 0 {
+			continue
+		}
+
+		window := stats.WindowByEndpoint[id]
+		if window == nil {
+			window = &WindowCounts{}
+		}
+
+		for _, tag := range tags {
+			g, ok := groups[tag]
+			if !ok {
+				g = &GroupStatus{Name: tag}
+				groups[tag] = g
+			}
+			g.Total++
+			switch {
+			case !enabled:
+				g.Disabled++
+			case status == StatusHealthy:
+				g.Healthy++
+			case status == StatusUnhealthy:
+				g.Unhealthy++
+			default:
+				g.Unknown++
+			}
+			g.Checks24h += window.Checks
+			healthyChecks[tag] += window.Healthy
+		}
+	}
+
+	result := make([]*GroupStatus, 0, len(groups))
+	for tag, g := range groups {
+		active := g.Total - g.Disabled
+		switch {
+		case g.Unhealthy > 0 && g.Unhealthy == active:
+			g.Status = string(StatusUnhealthy)
+		case g.Unhealthy > 0:
+			g.Status = "degraded"
+		case g.Healthy > 0 && g.Healthy == active:
+			g.Status = string(StatusHealthy)
+		default:
+			g.Status = string(StatusUnknown)
+		}
+		g.Summary = fmt.Sprintf("%d of %d unhealthy", g.Unhealthy, active)
+		if g.Unknown > 0 {
+			g.Summary += fmt.Sprintf(", %d unknown", g.Unknown)
+		}
+		if g.Checks24h > 0 {
+			g.Uptime24hPercent = float64(healthyChecks[tag]) / float64(g.Checks24h) * 100
+		}
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"groups":    result,
+		"timestamp": time.Now(),
+	})
+}
+
+// handleDBStats returns storage statistics: record counts, the database size
+// and, for BoltDB, its page and transaction statistics
+func (s *Server) handleDBStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.db.DBStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// handleStats returns an aggregate summary across all endpoints
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	states := s.monitor.GetStatus()
+
+	response := StatsResponse{
+		TotalEndpoints: len(states),
+		Timestamp:      time.Now(),
+	}
+
+	for _, state := range states {
+		state.mu.RLock()
+		switch {
+		case !state.Enabled:
+			response.Disabled++
+		case state.Status == StatusUnhealthy:
+			response.Unhealthy++
+		case state.ConsecutiveFailures > 0:
+			// Failing but not yet past the failure threshold
+			response.Degraded++
+		case state.Status == StatusHealthy:
+			response.Healthy++
+		default:
+			response.Unknown++
+		}
+		state.mu.RUnlock()
+	}
+
+	stats, err := s.db.GetHistoryStats(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response.TotalChecks = stats.TotalChecks
+	response.Checks24h = stats.WindowChecks
+	if stats.WindowChecks > 0 {
+		response.Uptime24hPercent = float64(stats.WindowHealthy) / float64(stats.WindowChecks) * 100
+	}
+	if stats.WindowRespSamples > 0 {
+		avg := stats.WindowRespTotal / time.Duration(stats.WindowRespSamples)
+		response.AvgResponseTimeMs = float64(avg.Microseconds()) / 1000.0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 // EndpointRequest represents a request to add/modify an endpoint
 type EndpointRequest struct {
-	ID               string            `json:"id"`
-	Name             string            `json:"name"`
-	URL              string            `json:"url"`
-	Method           string            `json:"method"`
-	Timeout          string            `json:"timeout"`
-	CheckInterval    string            `json:"check_interval"`
-	ExpectedStatus   int               `json:"expected_status"`
-	Headers          map[string]string `json:"headers"`
-	FailureThreshold int               `json:"failure_threshold"`
-	SuccessThreshold int               `json:"success_threshold"`
-}
-
-// handleEndpoints returns all endpoints from the database
+	ID                  string            `json:"id"`
+	Name                string            `json:"name"`
+	Type                string            `json:"type"`
+	URL                 string            `json:"url"`
+	URLs                []string          `json:"urls"`
+	Method              string            `json:"method"`
+	Body                string            `json:"body"`
+	Timeout             string            `json:"timeout"`
+	ConnectTimeout      string            `json:"connect_timeout"`
+	CheckInterval       string            `json:"check_interval"`
+	ExpectedStatus      int               `json:"expected_status"`
+	Headers             map[string]string `json:"headers"`
+	ExpectedHeaders     map[string]string `json:"expected_headers"`
+	ExpectedIP          string            `json:"expected_ip"`
+	GraphQLQuery        string            `json:"graphql_query"`
+	GraphQLDataPath     string            `json:"graphql_data_path"`
+	JSONPath            string            `json:"json_path"`
+	JSONPathExpected    string            `json:"json_path_expected"`
+	Invert              bool              `json:"invert"`
+	DependsOn           []string          `json:"depends_on"`
+	Tags                []string          `json:"tags"`
+	AlwaysAlert         bool              `json:"always_alert"`
+	AlertOnFirstFailure bool              `json:"alert_on_first_failure"`
+	SlackMention        string            `json:"slack_mention"`
+	AlertEmails         []string          `json:"alert_emails"`
+	UserAgent           string            `json:"user_agent"`
+	ProxyURL            string            `json:"proxy_url"`
+	AddressFamily       string            `json:"address_family"`
+	ResolveOverride     string            `json:"resolve_override"`
+	DisableKeepAlives   bool              `json:"disable_keep_alives"`
+	MaxRedirects        int               `json:"max_redirects"`
+	WebSocketPing       bool              `json:"websocket_ping"`
+	MinBodyBytes        int64             `json:"assert_min_body_bytes"`
+	MaxBodyBytes        int64             `json:"assert_max_body_bytes"`
+	BodyMustNotContain  string            `json:"body_must_not_contain"`
+	Backoff             bool              `json:"backoff"`
+	BackoffMax          string            `json:"backoff_max"`
+	FailureThreshold    int               `json:"failure_threshold"`
+	FailureDuration     string            `json:"failure_duration"`
+	SuccessThreshold    int               `json:"success_threshold"`
+	ResponseTimeSLO     string            `json:"response_time_slo"`
+}
+
+// handleEndpoints returns the endpoints from the database, filtered by
+// ?search= and ordered by ?sort=
 func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
-	endpoints, err := s.db.GetAllEndpoints()
+	query, err := parseEndpointListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	all, err := s.db.GetAllEndpoints()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	redact := s.monitor.currentConfig().RedactHeaders
+	endpoints := make([]*StoredEndpoint, 0, len(all))
+	for _, ep := range all {
+		if query.matches(ep.ID, ep.Name, ep.URL, ep.Tags) {
+			// Anyone who can read the dashboard could otherwise send heartbeats,
+			// use the proxy or replay the endpoint's credentials
+			ep.HeartbeatToken = ""
+			ep.ProxyURL = redactURLUserinfo(ep.ProxyURL)
+			ep.Headers = redactHeaderValues(ep.Headers, redact)
+			ep.ExpectedHeaders = redactHeaderValues(ep.ExpectedHeaders, redact)
+			endpoints = append(endpoints, ep)
+		}
+	}
+	query.sortEndpoints(endpoints, s.monitor.GetStatus())
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"endpoints": endpoints,
@@ -1056,14 +897,32 @@ func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Assign a stable random ID so name and URL can be edited later
+	id := newEndpointID()
+
+	// With only a urls list, the first entry doubles as the endpoint's URL
+	if req.URL == "" && len(req.URLs) > 0 {
+		req.URL = req.URLs[0]
+	}
+	// A heartbeat endpoint is never polled; its URL defaults to its ping path
+	if req.URL == "" && req.Type == CheckTypeHeartbeat {
+		req.URL = heartbeatPath + id
+	}
 	if req.Name == "" || req.URL == "" {
 		http.Error(w, "Name and URL are required", http.StatusBadRequest)
 		return
 	}
+	if !validCheckType(req.Type) {
+		http.Error(w, "Unknown check type: "+req.Type, http.StatusBadRequest)
+		return
+	}
+	target, targets, err := normalizeTargets(req.Type, req.URL, req.URLs)
+	if err != nil {
+		http.Error(w, "Invalid URL: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.URL, req.URLs = target, targets
 
-	// Generate ID from name+URL combination for unique history isolation
-	id := generateIDWithURL(req.Name, req.URL)
-	
 	// Check if endpoint with same name already exists
 	allEndpoints, _ := s.db.GetAllEndpoints()
 	for _, ep := range allEndpoints {
@@ -1071,23 +930,75 @@ func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Endpoint with this name already exists", http.StatusConflict)
 			return
 		}
-		if ep.URL == req.URL {
-			http.Error(w, "Endpoint with this URL already exists", http.StatusConflict)
-			return
-		}
+	}
+	if hasTarget(allEndpoints, req.URL, req.AddressFamily) {
+		http.Error(w, "Endpoint with this URL already exists", http.StatusConflict)
+		return
+	}
+	if err := checkDependencies(id, req.DependsOn, allEndpoints); err != nil {
+		http.Error(w, "Invalid depends_on: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := parseProxyURL(req.ProxyURL); err != nil {
+		http.Error(w, "Invalid proxy_url: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateAlertEmails(req.AlertEmails); err != nil {
+		http.Error(w, "Invalid alert_emails: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validAddressFamily(req.AddressFamily) {
+		http.Error(w, "Invalid address_family: use ip4, ip6 or leave empty", http.StatusBadRequest)
+		return
+	}
+	if err := validateMethod(req.Method, req.Body); err != nil {
+		http.Error(w, "Invalid method: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.MaxRedirects < MaxRedirectsNone {
+		http.Error(w, "Invalid max_redirects: use -1 to not follow redirects, or a number of redirects", http.StatusBadRequest)
+		return
+	}
+	if err := validateBodySize(req.MinBodyBytes, req.MaxBodyBytes); err != nil {
+		http.Error(w, "Invalid body size: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateResolveOverride(req.ResolveOverride, req.ProxyURL); err != nil {
+		http.Error(w, "Invalid resolve_override: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateExpectedIP(req.ExpectedIP); err != nil {
+		http.Error(w, "Invalid expected_ip: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validExpectedStatus(req.ExpectedStatus) {
+		http.Error(w, "Invalid expected_status", http.StatusBadRequest)
+		return
 	}
 
-	timeout := 10 * time.Second
+	// Without a timeout the endpoint follows default_timeout
+	var timeout time.Duration
 	if req.Timeout != "" {
 		var err error
 		timeout, err = time.ParseDuration(req.Timeout)
-		if err != nil {
-			http.Error(w, "Invalid timeout format: "+err.Error(), http.StatusBadRequest)
+		if err != nil || timeout < 0 {
+			http.Error(w, "Invalid timeout format: "+req.Timeout, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var connectTimeout time.Duration
+	if req.ConnectTimeout != "" {
+		var err error
+		connectTimeout, err = time.ParseDuration(req.ConnectTimeout)
+		if err != nil || connectTimeout < 0 {
+			http.Error(w, "Invalid connect_timeout: "+req.ConnectTimeout, http.StatusBadRequest)
 			return
 		}
 	}
 
-	checkInterval := 30 * time.Second
+	// Without one the endpoint follows check_interval
+	var checkInterval time.Duration
 	if req.CheckInterval != "" {
 		var err error
 		checkInterval, err = time.ParseDuration(req.CheckInterval)
@@ -1097,19 +1008,80 @@ func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var failureDuration time.Duration
+	if req.FailureDuration != "" {
+		var err error
+		failureDuration, err = time.ParseDuration(req.FailureDuration)
+		if err != nil || failureDuration < 0 {
+			http.Error(w, "Invalid failure_duration: "+req.FailureDuration, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var backoffMax time.Duration
+	if req.BackoffMax != "" {
+		var err error
+		backoffMax, err = time.ParseDuration(req.BackoffMax)
+		if err != nil || backoffMax < 0 {
+			http.Error(w, "Invalid backoff_max: "+req.BackoffMax, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var responseTimeSLO time.Duration
+	if req.ResponseTimeSLO != "" {
+		var err error
+		responseTimeSLO, err = time.ParseDuration(req.ResponseTimeSLO)
+		if err != nil || responseTimeSLO < 0 {
+			http.Error(w, "Invalid response_time_slo: "+req.ResponseTimeSLO, http.StatusBadRequest)
+			return
+		}
+	}
+
 	endpoint := &StoredEndpoint{
-		ID:               id,
-		Name:             req.Name,
-		URL:              req.URL,
-		Method:           req.Method,
-		Timeout:          timeout,
-		CheckInterval:    checkInterval,
-		ExpectedStatus:   req.ExpectedStatus,
-		Headers:          req.Headers,
-		FailureThreshold: req.FailureThreshold,
-		SuccessThreshold: req.SuccessThreshold,
-		Enabled:          true,
-		AlertsSuppressed: false,
+		ID:                  id,
+		Name:                req.Name,
+		Type:                req.Type,
+		URL:                 req.URL,
+		URLs:                req.URLs,
+		Method:              req.Method,
+		Body:                req.Body,
+		Timeout:             timeout,
+		ConnectTimeout:      connectTimeout,
+		CheckInterval:       checkInterval,
+		ExpectedStatus:      req.ExpectedStatus,
+		Headers:             req.Headers,
+		ExpectedHeaders:     req.ExpectedHeaders,
+		ExpectedIP:          req.ExpectedIP,
+		GraphQLQuery:        req.GraphQLQuery,
+		GraphQLDataPath:     req.GraphQLDataPath,
+		JSONPath:            req.JSONPath,
+		JSONPathExpected:    req.JSONPathExpected,
+		Invert:              req.Invert,
+		DependsOn:           req.DependsOn,
+		Tags:                req.Tags,
+		AlwaysAlert:         req.AlwaysAlert,
+		AlertOnFirstFailure: req.AlertOnFirstFailure,
+		SlackMention:        req.SlackMention,
+		AlertEmails:         req.AlertEmails,
+		UserAgent:           req.UserAgent,
+		ProxyURL:            req.ProxyURL,
+		AddressFamily:       req.AddressFamily,
+		ResolveOverride:     req.ResolveOverride,
+		DisableKeepAlives:   req.DisableKeepAlives,
+		MaxRedirects:        req.MaxRedirects,
+		WebSocketPing:       req.WebSocketPing,
+		MinBodyBytes:        req.MinBodyBytes,
+		MaxBodyBytes:        req.MaxBodyBytes,
+		BodyMustNotContain:  req.BodyMustNotContain,
+		Backoff:             req.Backoff,
+		BackoffMax:          backoffMax,
+		FailureThreshold:    req.FailureThreshold,
+		FailureDuration:     failureDuration,
+		SuccessThreshold:    req.SuccessThreshold,
+		ResponseTimeSLO:     responseTimeSLO,
+		Enabled:             true,
+		AlertsSuppressed:    false,
 	}
 
 	if err := s.monitor.AddEndpoint(endpoint); err != nil {
@@ -1124,70 +1096,523 @@ func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxCloneRequestSize caps the request body accepted by /api/endpoints/clone
+const maxCloneRequestSize = 1 << 20
+
+// handleCloneEndpoint creates an endpoint with the settings of an existing
+// one. The body names the source endpoint as id and gives the new name; any
+// other endpoint fields, in the add request's format, replace the copied
+// values. Status, history, snoozes and acknowledgements are not copied.
+func (s *Server) handleCloneEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCloneRequestSize))
+	if err != nil {
+		http.Error(w, "Failed to read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	json.Unmarshal(body, &req)
+	req.ID = s.resolveEndpointID(req.ID)
+	if req.ID == "" || req.Name == "" {
+		http.Error(w, "Source endpoint ID and new name are required", http.StatusBadRequest)
+		return
+	}
+
+	source, err := s.db.GetEndpoint(req.ID)
+	if err != nil {
+		http.Error(w, "Endpoint not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	clone := exportEndpoint(source)
+	// A heartbeat endpoint's default URL is its own ping path; the clone gets one for its new ID,
+	// and its own token
+	if clone.Type == CheckTypeHeartbeat && clone.URL == heartbeatPath+source.ID {
+		clone.URL = ""
+	}
+	clone.HeartbeatToken = ""
+	// Failover URLs go with the URL they back up
+	if _, ok := fields["url"]; ok {
+		clone.URLs = nil
+	}
+	// Decoding into a map adds keys rather than replacing it
+	if _, ok := fields["headers"]; ok {
+		clone.Headers = nil
+	}
+	if _, ok := fields["expected_headers"]; ok {
+		clone.ExpectedHeaders = nil
+	}
+	if err := json.Unmarshal(body, &clone); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	clone.ID = newEndpointID()
+	// The dashboard only sees the proxy's credentials and the redact headers
+	// masked
+	clone.ProxyURL = unredactURL(clone.ProxyURL, source.ProxyURL)
+	restoreHeaderValues(clone.Headers, source.Headers)
+	restoreHeaderValues(clone.ExpectedHeaders, source.ExpectedHeaders)
+
+	endpoint, err := clone.toStored()
+	if err != nil {
+		http.Error(w, "Invalid endpoint: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	allEndpoints, _ := s.db.GetAllEndpoints()
+	for _, ep := range allEndpoints {
+		if ep.Name == endpoint.Name {
+			http.Error(w, "Endpoint with this name already exists", http.StatusConflict)
+			return
+		}
+	}
+	if hasTarget(allEndpoints, endpoint.URL, endpoint.AddressFamily) {
+		http.Error(w, "Endpoint with this URL already exists", http.StatusConflict)
+		return
+	}
+	if err := checkDependencies(endpoint.ID, endpoint.DependsOn, allEndpoints); err != nil {
+		http.Error(w, "Invalid depends_on: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.monitor.AddEndpoint(endpoint); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The credentials came from the source endpoint, not the caller
+	response := *endpoint
+	response.ProxyURL = redactURLUserinfo(response.ProxyURL)
+	redact := s.monitor.currentConfig().RedactHeaders
+	response.Headers = redactHeaderValues(response.Headers, redact)
+	response.ExpectedHeaders = redactHeaderValues(response.ExpectedHeaders, redact)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"endpoint": &response,
+	})
+}
+
 // handleDeleteEndpoint deletes an endpoint
 func (s *Server) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Delete endpoint request: method=%s", r.Method)
-	
 	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
-		log.Printf("Delete endpoint: method not allowed")
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	id := r.URL.Query().Get("id")
-	log.Printf("Delete endpoint: query id=%s", id)
-	
 	if id == "" {
 		var req struct {
 			ID string `json:"id"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
-			id = req.ID
-			log.Printf("Delete endpoint: body id=%s", id)
-		} else {
-			log.Printf("Delete endpoint: body decode error=%v", err)
+		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+			id = req.ID
+		}
+	}
+	id = s.resolveEndpointID(id)
+
+	if id == "" {
+		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.monitor.RemoveEndpoint(id); err != nil {
+		log.Printf("Error deleting endpoint %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Endpoint deleted",
+	})
+}
+
+// maxImportSize caps the request body accepted by /api/endpoints/import
+const maxImportSize = 10 << 20
+
+// wantsYAML reports whether a request selects YAML via ?format=yaml or a YAML
+// Content-Type (for uploads) or Accept header (for downloads)
+func wantsYAML(r *http.Request, header string) bool {
+	switch r.URL.Query().Get("format") {
+	case "yaml", "yml":
+		return true
+	case "json":
+		return false
+	}
+	return strings.Contains(r.Header.Get(header), "yaml")
+}
+
+// handleExportEndpoints returns all endpoints as a JSON or YAML document that
+// /api/endpoints/import accepts. Without the backup token their secrets are
+// masked.
+func (s *Server) handleExportEndpoints(w http.ResponseWriter, r *http.Request) {
+	// Secrets are only included for the backup token, so the dashboard's
+	// readers can't collect credentials
+	full := false
+	if r.Header.Get("Authorization") != "" {
+		if !s.checkBackupToken(w, r, "Full exports are disabled; set server.backup_token to enable them") {
+			return
+		}
+		full = true
+	}
+
+	endpoints, err := s.db.GetAllEndpoints()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	redact := s.monitor.currentConfig().RedactHeaders
+	doc := EndpointExport{Endpoints: make([]ExportedEndpoint, 0, len(endpoints))}
+	for _, ep := range endpoints {
+		exported := exportEndpoint(ep)
+		if !full {
+			exported.redact(redact)
+		}
+		doc.Endpoints = append(doc.Endpoints, exported)
+	}
+
+	if wantsYAML(r, "Accept") {
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", `attachment; filename="cronzee-endpoints.yaml"`)
+		w.Write(data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="cronzee-endpoints.json"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(doc)
+}
+
+// handleImportEndpoints creates or updates endpoints from an export document.
+// Endpoints whose ID already exists are skipped unless ?overwrite=true. The
+// whole document is validated first, so an invalid file changes nothing.
+func (s *Server) handleImportEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	overwrite := r.URL.Query().Get("overwrite") == "true"
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		http.Error(w, "Failed to read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var doc EndpointExport
+	if wantsYAML(r, "Content-Type") {
+		err = yaml.Unmarshal(data, &doc)
+	} else {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		http.Error(w, "Invalid import document: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := s.db.GetAllEndpoints()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	byID := make(map[string]*StoredEndpoint, len(existing))
+	for _, ep := range existing {
+		byID[ep.ID] = ep
+	}
+
+	// Work out the resulting endpoint set before touching anything
+	var created, updated []*StoredEndpoint
+	skipped := 0
+	final := make(map[string]*StoredEndpoint, len(existing))
+	for id, ep := range byID {
+		final[id] = ep
+	}
+	for i, e := range doc.Endpoints {
+		stored, err := e.toStored()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Endpoint %d (%s): %v", i+1, e.Name, err), http.StatusBadRequest)
+			return
+		}
+		if stored.ID == "" {
+			stored.ID = newEndpointID()
+		}
+		if old, ok := byID[stored.ID]; ok {
+			if !overwrite {
+				skipped++
+				continue
+			}
+			stored.restoreSecrets(old)
+			stored.CreatedAt = old.CreatedAt
+			stored.Acknowledged = old.Acknowledged
+			stored.AcknowledgedAt = old.AcknowledgedAt
+			stored.SnoozeUntil = old.SnoozeUntil
+			updated = append(updated, stored)
+		} else if _, dup := final[stored.ID]; dup {
+			http.Error(w, fmt.Sprintf("Endpoint %d (%s): duplicate id %s", i+1, e.Name, stored.ID), http.StatusBadRequest)
+			return
+		} else {
+			created = append(created, stored)
+		}
+		final[stored.ID] = stored
+	}
+
+	names := make(map[string]string, len(final))
+	urls := make(map[string]string, len(final))
+	all := make([]*StoredEndpoint, 0, len(final))
+	for id, ep := range final {
+		if other, ok := names[ep.Name]; ok && other != id {
+			http.Error(w, "Duplicate endpoint name: "+ep.Name, http.StatusConflict)
+			return
+		}
+		// As in hasTarget, a URL may appear once per address family
+		target := targetKey(ep.URL) + " " + ep.AddressFamily
+		if other, ok := urls[target]; ok && other != id {
+			http.Error(w, "Duplicate endpoint URL: "+ep.URL, http.StatusConflict)
+			return
+		}
+		names[ep.Name] = id
+		urls[target] = id
+		all = append(all, ep)
+	}
+	for _, ep := range append(created, updated...) {
+		if err := checkDependencies(ep.ID, ep.DependsOn, all); err != nil {
+			http.Error(w, fmt.Sprintf("Endpoint %s: invalid depends_on: %v", ep.Name, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, ep := range created {
+		if err := s.monitor.AddEndpoint(ep); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, ep := range updated {
+		if err := s.db.SaveEndpoint(ep); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.monitor.UpdateEndpointSettings(ep.ID, ep)
+	}
+
+	log.Printf("Imported endpoints: %d created, %d updated, %d skipped", len(created), len(updated), skipped)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"created": len(created),
+		"updated": len(updated),
+		"skipped": skipped,
+	})
+}
+
+// handleEnableEndpoint enables an endpoint
+func (s *Server) handleEnableEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.EnableEndpoint, "enabled")
+}
+
+// handleDisableEndpoint disables an endpoint
+func (s *Server) handleDisableEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.DisableEndpoint, "disabled")
+}
+
+// handleSuppressAlerts suppresses alerts for an endpoint
+func (s *Server) handleSuppressAlerts(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.SuppressAlerts, "alerts suppressed")
+}
+
+// handleUnsuppressAlerts enables alerts for an endpoint
+func (s *Server) handleUnsuppressAlerts(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.UnsuppressAlerts, "alerts enabled")
+}
+
+// handleSnoozeAlerts silences alerts for an endpoint for a fixed duration.
+// A duration of 0s ends the snooze early.
+func (s *Server) handleSnoozeAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := struct {
+		ID       string `json:"id"`
+		Duration string `json:"duration"`
+	}{
+		ID:       r.URL.Query().Get("id"),
+		Duration: r.URL.Query().Get("duration"),
+	}
+	if req.ID == "" || req.Duration == "" {
+		var body struct {
+			ID       string `json:"id"`
+			Duration string `json:"duration"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+			if req.ID == "" {
+				req.ID = body.ID
+			}
+			if req.Duration == "" {
+				req.Duration = body.Duration
+			}
+		}
+	}
+	req.ID = s.resolveEndpointID(req.ID)
+
+	if req.ID == "" {
+		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
+		return
+	}
+	d, err := time.ParseDuration(req.Duration)
+	if err != nil || d < 0 {
+		http.Error(w, "Invalid duration: use a value like 30m or 2h, or 0s to end the snooze", http.StatusBadRequest)
+		return
+	}
+
+	until, err := s.monitor.SnoozeAlerts(req.ID, d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Endpoint alerts resumed",
+	}
+	if !until.IsZero() {
+		response["message"] = "Endpoint alerts snoozed"
+		response["snooze_until"] = until.Format(time.RFC3339)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CheckRunResult is the outcome of one endpoint in an on-demand check run
+type CheckRunResult struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Checked        bool    `json:"checked"`
+	Status         string  `json:"status"`
+	ResponseTimeMs float64 `json:"response_time_ms"`
+	LastError      string  `json:"last_error,omitempty"`
+}
+
+// handleCheckEndpoints checks the selected endpoints immediately, e.g. from a
+// deploy pipeline, and returns their results. Endpoints are chosen by ID
+// (repeat ?id= or send {"ids": [...]}) and/or by tag (?tag= or {"tag": ...}).
+func (s *Server) handleCheckEndpoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ids := r.URL.Query()["id"]
+	tag := r.URL.Query().Get("tag")
+	if len(ids) == 0 && tag == "" {
+		var body struct {
+			IDs []string `json:"ids"`
+			Tag string   `json:"tag"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+			ids = body.IDs
+			tag = body.Tag
+		}
+	}
+	if len(ids) == 0 && tag == "" {
+		http.Error(w, "Endpoint IDs or a tag are required", http.StatusBadRequest)
+		return
+	}
+
+	states := s.monitor.GetStatus()
+	selected := make(map[string]bool)
+	for _, id := range ids {
+		id = s.resolveEndpointID(id)
+		if _, ok := states[id]; !ok {
+			http.Error(w, "Endpoint not found: "+id, http.StatusNotFound)
+			return
+		}
+		selected[id] = true
+	}
+	if tag != "" {
+		for id, state := range states {
+			state.mu.RLock()
+			for _, t := range state.Endpoint.Tags {
+				if t == tag {
+					selected[id] = true
+				}
+			}
+			state.mu.RUnlock()
 		}
 	}
-
-	if id == "" {
-		log.Printf("Delete endpoint: ID is empty")
-		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
+	if len(selected) == 0 {
+		http.Error(w, "No endpoints have tag: "+tag, http.StatusNotFound)
 		return
 	}
 
-	log.Printf("Delete endpoint: attempting to remove id=%s", id)
-	if err := s.monitor.RemoveEndpoint(id); err != nil {
-		log.Printf("Delete endpoint: error=%v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	ids = ids[:0]
+	for id := range selected {
+		ids = append(ids, id)
+	}
+	if err := s.monitor.CheckEndpoints(ids); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	log.Printf("Delete endpoint: success id=%s", id)
+	results := make([]CheckRunResult, 0, len(ids))
+	healthy := true
+	for _, id := range ids {
+		state := states[id]
+		state.mu.RLock()
+		result := CheckRunResult{
+			ID:             id,
+			Name:           state.Endpoint.Name,
+			Checked:        state.Enabled,
+			Status:         string(state.Status),
+			ResponseTimeMs: float64(state.ResponseTime.Microseconds()) / 1000.0,
+			LastError:      state.LastError,
+		}
+		state.mu.RUnlock()
+		if result.Checked && result.Status != string(StatusHealthy) {
+			healthy = false
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"message": "Endpoint deleted",
+		"healthy": healthy,
+		"results": results,
 	})
 }
 
-// handleEnableEndpoint enables an endpoint
-func (s *Server) handleEnableEndpoint(w http.ResponseWriter, r *http.Request) {
-	s.handleEndpointAction(w, r, s.monitor.EnableEndpoint, "enabled")
-}
-
-// handleDisableEndpoint disables an endpoint
-func (s *Server) handleDisableEndpoint(w http.ResponseWriter, r *http.Request) {
-	s.handleEndpointAction(w, r, s.monitor.DisableEndpoint, "disabled")
-}
-
-// handleSuppressAlerts suppresses alerts for an endpoint
-func (s *Server) handleSuppressAlerts(w http.ResponseWriter, r *http.Request) {
-	s.handleEndpointAction(w, r, s.monitor.SuppressAlerts, "alerts suppressed")
+// handleAcknowledge acknowledges the active incident for an endpoint
+func (s *Server) handleAcknowledge(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.AcknowledgeEndpoint, "acknowledged")
 }
 
-// handleUnsuppressAlerts enables alerts for an endpoint
-func (s *Server) handleUnsuppressAlerts(w http.ResponseWriter, r *http.Request) {
-	s.handleEndpointAction(w, r, s.monitor.UnsuppressAlerts, "alerts enabled")
+// handleResetEndpoint resets an endpoint's consecutive counters and status
+func (s *Server) handleResetEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.monitor.ResetEndpoint, "reset")
 }
 
 // handleEndpointAction is a helper for endpoint actions
@@ -1206,6 +1631,7 @@ func (s *Server) handleEndpointAction(w http.ResponseWriter, r *http.Request, ac
 			id = req.ID
 		}
 	}
+	id = s.resolveEndpointID(id)
 
 	if id == "" {
 		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
@@ -1224,16 +1650,115 @@ func (s *Server) handleEndpointAction(w http.ResponseWriter, r *http.Request, ac
 	})
 }
 
+// resolveEndpointID maps an endpoint ID from before endpoints got random IDs to
+// the endpoint's current one, so old links, scripts and heartbeat URLs keep
+// working. Any other ID is returned unchanged.
+func (s *Server) resolveEndpointID(id string) string {
+	if id == "" || isGeneratedID(id) {
+		return id
+	}
+	if current, err := s.db.GetSetting(legacyIDSetting + id); err == nil && len(current) > 0 {
+		return string(current)
+	}
+	return id
+}
+
+// handlePing records a heartbeat for the endpoint and token that end the path,
+// e.g. /api/ping/<id>/<token>. GET, HEAD and POST all work, so a job can use
+// plain curl.
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, token, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, heartbeatPath), "/")
+	if !ok || id == "" || token == "" || strings.Contains(token, "/") {
+		http.Error(w, "Endpoint ID and heartbeat token are required", http.StatusBadRequest)
+		return
+	}
+	id = s.resolveEndpointID(id)
+
+	if err := s.monitor.RecordHeartbeat(id, token); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Heartbeat recorded",
+	})
+}
+
 // handleHistory returns health check history for an endpoint
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Query().Get("id")
+	id := s.resolveEndpointID(r.URL.Query().Get("id"))
 	if id == "" {
 		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
 		return
 	}
 
-	limit := 1000
-	records, err := s.db.GetHealthHistory(id, limit)
+	query := HistoryQuery{Limit: 1000}
+	params := r.URL.Query()
+
+	// buckets=N aggregates the whole matching range into N time buckets
+	// instead of returning raw records
+	buckets := 0
+	if v := params.Get("buckets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 10000 {
+			http.Error(w, "Invalid buckets: "+v, http.StatusBadRequest)
+			return
+		}
+		buckets = n
+	}
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit: "+v, http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+	if v := params.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset: "+v, http.StatusBadRequest)
+			return
+		}
+		query.Offset = offset
+	}
+	if v := params.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid from format: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.From = from
+	}
+	if v := params.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid to format: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.To = to
+	}
+	if v := params.Get("status"); v != "" {
+		if !validHistoryStatus(v) {
+			http.Error(w, "Invalid status: "+v, http.StatusBadRequest)
+			return
+		}
+		query.Status = v
+	}
+
+	if buckets > 0 {
+		s.writeHistoryBuckets(w, id, query, buckets)
+		return
+	}
+
+	records, total, err := s.db.GetHealthHistory(id, query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -1253,16 +1778,212 @@ func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 		avgResponseTimeMs = float64(totalResponseTime/int64(count)) / 1000000.0
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response := map[string]interface{}{
 		"endpoint_id":         id,
-		"records":             records,
 		"avg_response_time_ms": avgResponseTimeMs,
 		"record_count":        count,
+		"total":               total,
+		"limit":               query.Limit,
+		"offset":              query.Offset,
 		"timestamp":           time.Now().Format(time.RFC3339),
+		"records":             records,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeHistoryBuckets answers a ?buckets= history request. The range is
+// streamed rather than loaded, so only the bucket totals are held in memory.
+// limit and offset don't apply to buckets.
+func (s *Server) writeHistoryBuckets(w http.ResponseWriter, id string, query HistoryQuery, n int) {
+	var total, count int
+	var totalResponseTime time.Duration
+	points, err := streamHistoryBuckets(s.db, id, n, query.From, query.To, query.matches, func(rec *HealthCheckRecord) {
+		total++
+		if rec.ResponseTime > 0 {
+			totalResponseTime += rec.ResponseTime
+			count++
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var avgResponseTimeMs float64
+	if count > 0 {
+		avgResponseTimeMs = float64(int64(totalResponseTime)/int64(count)) / 1000000.0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"endpoint_id":          id,
+		"avg_response_time_ms": avgResponseTimeMs,
+		"record_count":         count,
+		"total":                total,
+		"limit":                0,
+		"offset":               0,
+		"timestamp":            time.Now().Format(time.RFC3339),
+		"buckets":              points,
+	})
+}
+
+// RecentHistory is an endpoint's latest checks in /api/history/recent
+type RecentHistory struct {
+	Records           []*HealthCheckRecord `json:"records"`
+	AvgResponseTimeMs float64              `json:"avg_response_time_ms"`
+}
+
+// handleRecentHistory returns the latest ?limit= checks (default
+// server.chart_points, at most 1000) of every endpoint, or of those named
+// by repeated ?id=, newest first. The dashboard draws its sparklines from it
+// with one request per refresh instead of one per endpoint.
+func (s *Server) handleRecentHistory(w http.ResponseWriter, r *http.Request) {
+	limit := s.config.ChartPoints
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			http.Error(w, "Invalid limit: "+v, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	ids := r.URL.Query()["id"]
+	if len(ids) == 0 {
+		stored, err := s.db.GetAllEndpoints()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, ep := range stored {
+			ids = append(ids, ep.ID)
+		}
+	}
+
+	endpoints := make(map[string]*RecentHistory, len(ids))
+	for _, id := range ids {
+		id = s.resolveEndpointID(id)
+		records, _, err := s.db.GetHealthHistory(id, HistoryQuery{Limit: limit})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		recent := &RecentHistory{Records: records}
+		var total time.Duration
+		var count int
+		for _, rec := range records {
+			if rec.ResponseTime > 0 {
+				total += rec.ResponseTime
+				count++
+			}
+		}
+		if count > 0 {
+			recent.AvgResponseTimeMs = float64((total / time.Duration(count)).Microseconds()) / 1000.0
+		}
+		endpoints[id] = recent
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"endpoints": endpoints,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleHistoryStream writes an endpoint's whole history, or the part between
+// ?from= and ?to=, oldest first as newline-delimited JSON. Records are read
+// from the store in chunks and written as they arrive, so large exports
+// don't have to fit in memory.
+func (s *Server) handleHistoryStream(w http.ResponseWriter, r *http.Request) {
+	id := s.resolveEndpointID(r.URL.Query().Get("id"))
+	if id == "" {
+		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid from format: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "Invalid to format: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	count := 0
+	err := s.db.StreamHealthHistory(id, from, to, func(record *HealthCheckRecord) error {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		count++
+		if count%historyStreamChunk == 0 && flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// The status line has most likely gone out already, so all we can do
+		// is cut the stream short
+		log.Printf("Error streaming history for %s after %d records: %v", id, count, err)
+	}
+}
+
+// handleTimeline returns an endpoint's history over the last ?days=N days
+// (default DataRetentionDays, all there is) as up/down intervals, for drawing
+// a status page bar
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	id := s.resolveEndpointID(r.URL.Query().Get("id"))
+	if id == "" {
+		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
+		return
+	}
+
+	days := DataRetentionDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 366 {
+			http.Error(w, "Invalid days: "+v, http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+	records, _, err := s.db.GetHealthHistory(id, HistoryQuery{From: from, To: to})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"endpoint_id": id,
+		"from":        from.Format(time.RFC3339),
+		"to":          to.Format(time.RFC3339),
+		"intervals":   buildTimeline(records),
 	})
 }
 
+// handleClearHistory deletes all health check history for an endpoint
+func (s *Server) handleClearHistory(w http.ResponseWriter, r *http.Request) {
+	s.handleEndpointAction(w, r, s.db.ClearHistory, "history cleared")
+}
+
 // handleUpdateEndpoint updates an endpoint's settings
 func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -1271,17 +1992,53 @@ func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		ID               string `json:"id"`
-		CheckInterval    string `json:"check_interval"`
-		Timeout          string `json:"timeout"`
-		FailureThreshold int    `json:"failure_threshold"`
-		SuccessThreshold int    `json:"success_threshold"`
+		ID                  string            `json:"id"`
+		Name                string            `json:"name"`
+		URL                 string            `json:"url"`
+		URLs                []string          `json:"urls"`
+		Method              *string           `json:"method"`
+		Body                *string           `json:"body"`
+		CheckInterval       string            `json:"check_interval"`
+		Timeout             string            `json:"timeout"`
+		ConnectTimeout      string            `json:"connect_timeout"`
+		ExpectedStatus      int               `json:"expected_status"`
+		FailureThreshold    int               `json:"failure_threshold"`
+		FailureDuration     string            `json:"failure_duration"`
+		SuccessThreshold    int               `json:"success_threshold"`
+		Headers             map[string]string `json:"headers"`
+		ExpectedHeaders     map[string]string `json:"expected_headers"`
+		ExpectedIP          *string           `json:"expected_ip"`
+		GraphQLQuery        *string           `json:"graphql_query"`
+		GraphQLDataPath     *string           `json:"graphql_data_path"`
+		JSONPath            *string           `json:"json_path"`
+		JSONPathExpected    *string           `json:"json_path_expected"`
+		DependsOn           []string          `json:"depends_on"`
+		Tags                []string          `json:"tags"`
+		AlwaysAlert         *bool             `json:"always_alert"`
+		AlertOnFirstFailure *bool             `json:"alert_on_first_failure"`
+		SlackMention        *string           `json:"slack_mention"`
+		AlertEmails         []string          `json:"alert_emails"`
+		UserAgent           *string           `json:"user_agent"`
+		ProxyURL            *string           `json:"proxy_url"`
+		AddressFamily       *string           `json:"address_family"`
+		ResolveOverride     *string           `json:"resolve_override"`
+		DisableKeepAlives   *bool             `json:"disable_keep_alives"`
+		MaxRedirects        *int              `json:"max_redirects"`
+		WebSocketPing       *bool             `json:"websocket_ping"`
+		MinBodyBytes        *int64            `json:"assert_min_body_bytes"`
+		MaxBodyBytes        *int64            `json:"assert_max_body_bytes"`
+		BodyMustNotContain  *string           `json:"body_must_not_contain"`
+		Invert              *bool             `json:"invert"`
+		Backoff             *bool             `json:"backoff"`
+		BackoffMax          string            `json:"backoff_max"`
+		ResponseTimeSLO     string            `json:"response_time_slo"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	req.ID = s.resolveEndpointID(req.ID)
 	if req.ID == "" {
 		http.Error(w, "Endpoint ID is required", http.StatusBadRequest)
 		return
@@ -1294,29 +2051,268 @@ func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.URL != "" {
+		if req.URL, err = normalizeTarget(endpoint.Type, req.URL); err != nil {
+			http.Error(w, "Invalid URL: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for i, target := range req.URLs {
+		if req.URLs[i], err = normalizeTarget(endpoint.Type, target); err != nil {
+			http.Error(w, "Invalid URL: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Name and URL may change; the ID stays stable so history is preserved
+	if req.Name != "" || req.URL != "" || req.AddressFamily != nil {
+		url, family := endpoint.URL, endpoint.AddressFamily
+		if req.URL != "" {
+			url = req.URL
+		}
+		if req.AddressFamily != nil {
+			family = *req.AddressFamily
+		}
+		allEndpoints, _ := s.db.GetAllEndpoints()
+		others := make([]*StoredEndpoint, 0, len(allEndpoints))
+		for _, ep := range allEndpoints {
+			if ep.ID == endpoint.ID {
+				continue
+			}
+			if req.Name != "" && ep.Name == req.Name {
+				http.Error(w, "Endpoint with this name already exists", http.StatusConflict)
+				return
+			}
+			others = append(others, ep)
+		}
+		if hasTarget(others, url, family) {
+			http.Error(w, "Endpoint with this URL already exists", http.StatusConflict)
+			return
+		}
+	}
+
 	// Update fields if provided
+	if req.Name != "" {
+		endpoint.Name = req.Name
+	}
+	if req.URL != "" {
+		endpoint.URL = req.URL
+	}
+	// A present but empty urls list removes the failover addresses
+	if req.URLs != nil {
+		endpoint.URLs = req.URLs
+	}
 	if req.CheckInterval != "" {
 		interval, err := time.ParseDuration(req.CheckInterval)
 		if err != nil {
 			http.Error(w, "Invalid check_interval format: "+err.Error(), http.StatusBadRequest)
 			return
 		}
+		// "0s" reverts to check_interval
 		endpoint.CheckInterval = interval
 	}
+	// "0s" reverts to default_timeout
 	if req.Timeout != "" {
 		timeout, err := time.ParseDuration(req.Timeout)
-		if err != nil {
-			http.Error(w, "Invalid timeout format: "+err.Error(), http.StatusBadRequest)
+		if err != nil || timeout < 0 {
+			http.Error(w, "Invalid timeout format: "+req.Timeout, http.StatusBadRequest)
 			return
 		}
 		endpoint.Timeout = timeout
 	}
+	if req.ConnectTimeout != "" {
+		connectTimeout, err := time.ParseDuration(req.ConnectTimeout)
+		if err != nil || connectTimeout < 0 {
+			http.Error(w, "Invalid connect_timeout: "+req.ConnectTimeout, http.StatusBadRequest)
+			return
+		}
+		endpoint.ConnectTimeout = connectTimeout
+	}
+	// Zero leaves the expected status unchanged; ExpectedStatusAny accepts any code
+	if req.ExpectedStatus != 0 {
+		if !validExpectedStatus(req.ExpectedStatus) {
+			http.Error(w, "Invalid expected_status", http.StatusBadRequest)
+			return
+		}
+		endpoint.ExpectedStatus = req.ExpectedStatus
+	}
 	if req.FailureThreshold > 0 {
 		endpoint.FailureThreshold = req.FailureThreshold
 	}
 	if req.SuccessThreshold > 0 {
 		endpoint.SuccessThreshold = req.SuccessThreshold
 	}
+	// "0s" switches back to counting failures
+	if req.FailureDuration != "" {
+		failureDuration, err := time.ParseDuration(req.FailureDuration)
+		if err != nil || failureDuration < 0 {
+			http.Error(w, "Invalid failure_duration: "+req.FailureDuration, http.StatusBadRequest)
+			return
+		}
+		endpoint.FailureDuration = failureDuration
+	}
+	// A present but empty headers object clears all custom headers
+	// The dashboard sees the values of the redact headers masked, so a masked
+	// value leaves the stored one unchanged
+	if req.Headers != nil {
+		restoreHeaderValues(req.Headers, endpoint.Headers)
+		endpoint.Headers = req.Headers
+	}
+	// Likewise an empty expected_headers object removes every assertion
+	if req.ExpectedHeaders != nil {
+		restoreHeaderValues(req.ExpectedHeaders, endpoint.ExpectedHeaders)
+		endpoint.ExpectedHeaders = req.ExpectedHeaders
+	}
+	// An empty expected_ip removes the assertion
+	if req.ExpectedIP != nil {
+		if err := validateExpectedIP(*req.ExpectedIP); err != nil {
+			http.Error(w, "Invalid expected_ip: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.ExpectedIP = *req.ExpectedIP
+	}
+	// An empty graphql_query reverts to the default query
+	if req.GraphQLQuery != nil {
+		endpoint.GraphQLQuery = *req.GraphQLQuery
+	}
+	if req.GraphQLDataPath != nil {
+		endpoint.GraphQLDataPath = *req.GraphQLDataPath
+	}
+	// An empty json_path turns the assertion off
+	if req.JSONPath != nil {
+		endpoint.JSONPath = *req.JSONPath
+	}
+	if req.JSONPathExpected != nil {
+		endpoint.JSONPathExpected = *req.JSONPathExpected
+	}
+	// Likewise a present but empty depends_on list removes all dependencies
+	if req.DependsOn != nil {
+		allEndpoints, _ := s.db.GetAllEndpoints()
+		if err := checkDependencies(endpoint.ID, req.DependsOn, allEndpoints); err != nil {
+			http.Error(w, "Invalid depends_on: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.DependsOn = req.DependsOn
+	}
+	if req.Tags != nil {
+		endpoint.Tags = req.Tags
+	}
+	if req.AlwaysAlert != nil {
+		endpoint.AlwaysAlert = *req.AlwaysAlert
+	}
+	if req.AlertOnFirstFailure != nil {
+		endpoint.AlertOnFirstFailure = *req.AlertOnFirstFailure
+	}
+	// An empty slack_mention reverts to the global mention
+	if req.SlackMention != nil {
+		endpoint.SlackMention = *req.SlackMention
+	}
+	if req.AlertEmails != nil {
+		if err := validateAlertEmails(req.AlertEmails); err != nil {
+			http.Error(w, "Invalid alert_emails: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.AlertEmails = req.AlertEmails
+	}
+	// An empty user_agent reverts to the global default
+	if req.UserAgent != nil {
+		endpoint.UserAgent = *req.UserAgent
+	}
+	// Likewise an empty proxy_url reverts to the global proxy or environment
+	if req.ProxyURL != nil {
+		// The dashboard only sees the proxy's credentials masked
+		*req.ProxyURL = unredactURL(*req.ProxyURL, endpoint.ProxyURL)
+		if _, err := parseProxyURL(*req.ProxyURL); err != nil {
+			http.Error(w, "Invalid proxy_url: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.ProxyURL = *req.ProxyURL
+	}
+	// An empty method reverts to GET, and an empty body sends none
+	if req.Method != nil || req.Body != nil {
+		method, body := endpoint.Method, endpoint.Body
+		if req.Method != nil {
+			method = *req.Method
+		}
+		if req.Body != nil {
+			body = *req.Body
+		}
+		if err := validateMethod(method, body); err != nil {
+			http.Error(w, "Invalid method: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.Method, endpoint.Body = method, body
+	}
+	// An empty address_family dials either family again
+	if req.AddressFamily != nil {
+		if !validAddressFamily(*req.AddressFamily) {
+			http.Error(w, "Invalid address_family: use ip4, ip6 or leave empty", http.StatusBadRequest)
+			return
+		}
+		endpoint.AddressFamily = *req.AddressFamily
+	}
+	// An empty resolve_override goes back to normal DNS resolution
+	if req.ResolveOverride != nil {
+		endpoint.ResolveOverride = *req.ResolveOverride
+	}
+	if req.ResolveOverride != nil || req.ProxyURL != nil {
+		if err := validateResolveOverride(endpoint.ResolveOverride, endpoint.ProxyURL); err != nil {
+			http.Error(w, "Invalid resolve_override: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.DisableKeepAlives != nil {
+		endpoint.DisableKeepAlives = *req.DisableKeepAlives
+	}
+	// 0 reverts to the default limit
+	if req.MaxRedirects != nil {
+		if *req.MaxRedirects < MaxRedirectsNone {
+			http.Error(w, "Invalid max_redirects: use -1 to not follow redirects, or a number of redirects", http.StatusBadRequest)
+			return
+		}
+		endpoint.MaxRedirects = *req.MaxRedirects
+	}
+	if req.WebSocketPing != nil {
+		endpoint.WebSocketPing = *req.WebSocketPing
+	}
+	// 0 removes a limit
+	minBody, maxBody := endpoint.MinBodyBytes, endpoint.MaxBodyBytes
+	if req.MinBodyBytes != nil {
+		minBody = *req.MinBodyBytes
+	}
+	if req.MaxBodyBytes != nil {
+		maxBody = *req.MaxBodyBytes
+	}
+	if err := validateBodySize(minBody, maxBody); err != nil {
+		http.Error(w, "Invalid body size: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	endpoint.MinBodyBytes, endpoint.MaxBodyBytes = minBody, maxBody
+	if req.BodyMustNotContain != nil {
+		endpoint.BodyMustNotContain = *req.BodyMustNotContain
+	}
+	if req.Invert != nil {
+		endpoint.Invert = *req.Invert
+	}
+	if req.Backoff != nil {
+		endpoint.Backoff = *req.Backoff
+	}
+	if req.BackoffMax != "" {
+		backoffMax, err := time.ParseDuration(req.BackoffMax)
+		if err != nil || backoffMax < 0 {
+			http.Error(w, "Invalid backoff_max: "+req.BackoffMax, http.StatusBadRequest)
+			return
+		}
+		endpoint.BackoffMax = backoffMax
+	}
+	if req.ResponseTimeSLO != "" {
+		responseTimeSLO, err := time.ParseDuration(req.ResponseTimeSLO)
+		if err != nil || responseTimeSLO < 0 {
+			http.Error(w, "Invalid response_time_slo: "+req.ResponseTimeSLO, http.StatusBadRequest)
+			return
+		}
+		endpoint.ResponseTimeSLO = responseTimeSLO
+	}
 
 	// Save to database
 	if err := s.db.SaveEndpoint(endpoint); err != nil {
@@ -1327,9 +2323,14 @@ func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 	// Update monitor state
 	s.monitor.UpdateEndpointSettings(req.ID, endpoint)
 
+	response := *endpoint
+	response.ProxyURL = redactURLUserinfo(response.ProxyURL)
+	redact := s.monitor.currentConfig().RedactHeaders
+	response.Headers = redactHeaderValues(response.Headers, redact)
+	response.ExpectedHeaders = redactHeaderValues(response.ExpectedHeaders, redact)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":  true,
-		"endpoint": endpoint,
+		"endpoint": &response,
 	})
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// slackPostMessageURL is Slack's chat.postMessage method, used instead of an
+// incoming webhook when alerting.slack_bot_token is set
+var slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// slackTemplateData is what alerting.slack_template is rendered with
+type slackTemplateData struct {
+	Subject             string
+	Message             string
+	AlertType           string
+	Endpoint            Endpoint
+	Status              string
+	ResponseTime        time.Duration
+	ConsecutiveFailures int
+	LastError           string
+	LastCheck           time.Time
+}
+
+// newSlackTemplateData collects the fields of an alert for slack_template
+func newSlackTemplateData(subject, message, alertType string, endpoint Endpoint, state *EndpointState) slackTemplateData {
+	return slackTemplateData{
+		Subject:             subject,
+		Message:             message,
+		AlertType:           alertType,
+		Endpoint:            endpoint,
+		Status:              string(state.Status),
+		ResponseTime:        state.ResponseTime,
+		ConsecutiveFailures: state.ConsecutiveFailures,
+		LastError:           state.LastError,
+		LastCheck:           state.LastCheck,
+	}
+}
+
+// validateSlackTemplate renders a slack_template with placeholder data, so a
+// syntax error or unknown field shows up when the config is loaded rather
+// than when an alert goes out
+func validateSlackTemplate(text string) error {
+	_, err := renderSlackTemplate(text, slackTemplateData{})
+	return err
+}
+
+// renderSlackTemplate renders a slack_template for one alert
+func renderSlackTemplate(text string, data slackTemplateData) (string, error) {
+	tmpl, err := template.New("slack_template").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// slackBotEnabled reports whether Slack alerts are posted with the Web API,
+// which unlike incoming webhooks can thread replies
+func (a *Alerting) slackBotEnabled() bool {
+	return a.SlackBotToken != "" && a.SlackChannel != ""
+}
+
+// queueSlackBotAlert sends an alert with sendSlackBotAlert in the background,
+// after any earlier post for the same endpoint has finished, so a recovery
+// always sees the thread its failure alert opened
+func (a *Alerter) queueSlackBotAlert(subject, message, alertType string, endpoint Endpoint, state *EndpointState) {
+	payload := a.slackPayload(subject, message, alertType, endpoint, state)
+	id := state.ID
+
+	done := make(chan struct{})
+	a.mu.Lock()
+	prev := a.slackLast[id]
+	a.slackLast[id] = done
+	a.mu.Unlock()
+
+	go func() {
+		if prev != nil {
+			<-prev
+		}
+		a.sendSlackBotAlert(payload, alertType, endpoint, id)
+		close(done)
+
+		a.mu.Lock()
+		if a.slackLast[id] == done {
+			delete(a.slackLast, id)
+		}
+		a.mu.Unlock()
+	}()
+}
+
+// sendSlackBotAlert posts an alert to alerting.slack_channel with
+// chat.postMessage. A failure alert starts a thread for the incident, and
+// reminders and the recovery are posted as replies in it.
+func (a *Alerter) sendSlackBotAlert(payload map[string]interface{}, alertType string, endpoint Endpoint, id string) {
+	a.mu.Lock()
+	thread := a.slackThreads[id]
+	if alertType == "recovery" {
+		// The incident is over whether or not the reply gets through
+		delete(a.slackThreads, id)
+	}
+	a.mu.Unlock()
+	if thread != "" && (alertType == "repeat" || alertType == "recovery") {
+		payload["thread_ts"] = thread
+	}
+
+	ts, err := a.postSlackMessage(payload)
+	if err != nil {
+		log.Printf("Failed to send Slack alert: %v", err)
+		return
+	}
+	log.Printf("Slack alert sent successfully for endpoint: %s", endpoint.Name)
+
+	if alertType == "failure" {
+		a.mu.Lock()
+		a.slackThreads[id] = ts
+		a.mu.Unlock()
+	}
+}
+
+// postSlackMessage posts payload to alerting.slack_channel and returns the
+// new message's timestamp, which identifies it for threading
+func (a *Alerter) postSlackMessage(payload map[string]interface{}) (string, error) {
+	cfg := a.cfg()
+	// payload may be shared with the webhook senders, so add the channel to a copy
+	message := make(map[string]interface{}, len(payload)+1)
+	for key, value := range payload {
+		message[key] = value
+	}
+	message["channel"] = cfg.SlackChannel
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slackPostMessageURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+cfg.SlackBotToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Slack reports most errors as 200 with ok: false
+	var result struct {
+		OK    bool   `json:"ok"`
+		TS    string `json:"ts"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("chat.postMessage returned %s: %w", resp.Status, err)
+	}
+	if !result.OK {
+		return "", fmt.Errorf("chat.postMessage failed: %s", result.Error)
+	}
+	return result.TS, nil
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS endpoints (
+	id      TEXT PRIMARY KEY,
+	name    TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	enabled INTEGER NOT NULL,
+	data    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS history (
+	endpoint_id   TEXT NOT NULL,
+	timestamp     INTEGER NOT NULL,
+	status        TEXT NOT NULL,
+	response_time INTEGER NOT NULL,
+	status_code   INTEGER NOT NULL,
+	error         TEXT NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_endpoint_time ON history (endpoint_id, timestamp);
+CREATE INDEX IF NOT EXISTS history_time ON history (timestamp);
+CREATE TABLE IF NOT EXISTS settings (
+	key   TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+);
+`
+
+// SQLiteStore is a Store backed by a SQLite database. Endpoints and history rows
+// keep their full JSON in a data column alongside columns for querying with SQL;
+// timestamps are stored as Unix nanoseconds and response times as nanoseconds.
+type SQLiteStore struct {
+	db       *sql.DB
+	config   *StorageConfig
+	cleanups cleanupTracker
+}
+
+// NewSQLiteStore opens or creates a SQLite database at path
+func NewSQLiteStore(path string, config *StorageConfig) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	store := &SQLiteStore{db: db, config: config}
+
+	// Start cleanup goroutine
+	go startCleanupRoutine(store, config)
+
+	return store, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Backup writes a consistent copy of the database to w. SQLite can only
+// snapshot to a file, so the copy goes through a temporary file.
+func (s *SQLiteStore) Backup(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "cronzee-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "backup.db")
+	if _, err := s.db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// Compact rebuilds the database file with VACUUM to release the space left by
+// deleted records. It returns the file size before and after.
+func (s *SQLiteStore) Compact() (before, after int64, err error) {
+	stats, err := s.DBStats()
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return 0, 0, fmt.Errorf("failed to compact database: %w", err)
+	}
+	compacted, err := s.DBStats()
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats.SizeBytes, compacted.SizeBytes, nil
+}
+
+// DBStats reports record counts and the size of the main database file
+func (s *SQLiteStore) DBStats() (*DBStats, error) {
+	stats := &DBStats{Driver: "sqlite", LastCleanup: s.cleanups.stats()}
+	var pageCount, pageSize int64
+	err := s.db.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM endpoints),
+		(SELECT COUNT(*) FROM history),
+		(SELECT page_count FROM pragma_page_count()),
+		(SELECT page_size FROM pragma_page_size())`).Scan(&stats.Endpoints, &stats.HistoryRecords, &pageCount, &pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database stats: %w", err)
+	}
+	stats.SizeBytes = pageCount * pageSize
+	return stats, nil
+}
+
+// Ping verifies the database is reachable
+func (s *SQLiteStore) Ping() error {
+	var one int
+	return s.db.QueryRow("SELECT 1").Scan(&one)
+}
+
+// SaveEndpoint saves or updates an endpoint
+func (s *SQLiteStore) SaveEndpoint(endpoint *StoredEndpoint) error {
+	endpoint.applyDefaults()
+
+	data, err := json.Marshal(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal endpoint: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO endpoints (id, name, url, enabled, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, url = excluded.url, enabled = excluded.enabled, data = excluded.data`,
+		endpoint.ID, endpoint.Name, endpoint.URL, endpoint.Enabled, string(data),
+	)
+	return err
+}
+
+// GetEndpoint retrieves an endpoint by ID
+func (s *SQLiteStore) GetEndpoint(id string) (*StoredEndpoint, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM endpoints WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("endpoint not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint StoredEndpoint
+	if err := json.Unmarshal([]byte(data), &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// GetAllEndpoints retrieves all endpoints
+func (s *SQLiteStore) GetAllEndpoints() ([]*StoredEndpoint, error) {
+	return s.queryEndpoints(`SELECT data FROM endpoints ORDER BY id`)
+}
+
+// GetEnabledEndpoints retrieves only enabled endpoints
+func (s *SQLiteStore) GetEnabledEndpoints() ([]*StoredEndpoint, error) {
+	return s.queryEndpoints(`SELECT data FROM endpoints WHERE enabled = 1 ORDER BY id`)
+}
+
+// FindEndpointByURL returns the endpoint monitoring url, or nil if there is none
+func (s *SQLiteStore) FindEndpointByURL(url string) (*StoredEndpoint, error) {
+	endpoints, err := s.queryEndpoints(`SELECT data FROM endpoints WHERE url = ? LIMIT 1`, url)
+	if err != nil || len(endpoints) == 0 {
+		return nil, err
+	}
+	return endpoints[0], nil
+}
+
+// queryEndpoints decodes the data column of every row returned by query
+func (s *SQLiteStore) queryEndpoints(query string, args ...interface{}) ([]*StoredEndpoint, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*StoredEndpoint
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var endpoint StoredEndpoint
+		if err := json.Unmarshal([]byte(data), &endpoint); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+// DeleteEndpoint removes an endpoint
+func (s *SQLiteStore) DeleteEndpoint(id string) error {
+	_, err := s.db.Exec(`DELETE FROM endpoints WHERE id = ?`, id)
+	return err
+}
+
+// updateEndpoint loads an endpoint, applies fn and saves it back
+func (s *SQLiteStore) updateEndpoint(id string, fn func(*StoredEndpoint)) error {
+	endpoint, err := s.GetEndpoint(id)
+	if err != nil {
+		return err
+	}
+	fn(endpoint)
+	return s.SaveEndpoint(endpoint)
+}
+
+// EnableEndpoint enables an endpoint
+func (s *SQLiteStore) EnableEndpoint(id string) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) { e.Enabled = true })
+}
+
+// DisableEndpoint disables an endpoint
+func (s *SQLiteStore) DisableEndpoint(id string) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) { e.Enabled = false })
+}
+
+// SuppressAlerts suppresses alerts for an endpoint
+func (s *SQLiteStore) SuppressAlerts(id string) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) { e.AlertsSuppressed = true })
+}
+
+// UnsuppressAlerts enables alerts for an endpoint
+func (s *SQLiteStore) UnsuppressAlerts(id string) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) { e.AlertsSuppressed = false })
+}
+
+// SnoozeAlerts silences alerts for an endpoint until the given time
+func (s *SQLiteStore) SnoozeAlerts(id string, until time.Time) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) { e.SnoozeUntil = until })
+}
+
+// AcknowledgeIncident marks the current incident for an endpoint as acknowledged
+func (s *SQLiteStore) AcknowledgeIncident(id string, at time.Time) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) {
+		e.Acknowledged = true
+		e.AcknowledgedAt = at
+	})
+}
+
+// ClearAcknowledgement clears the incident acknowledgement for an endpoint
+func (s *SQLiteStore) ClearAcknowledgement(id string) error {
+	return s.updateEndpoint(id, func(e *StoredEndpoint) {
+		e.Acknowledged = false
+		e.AcknowledgedAt = time.Time{}
+	})
+}
+
+// SaveHealthCheckRecord saves a health check result to history
+func (s *SQLiteStore) SaveHealthCheckRecord(record *HealthCheckRecord) error {
+	return s.SaveHealthCheckRecords([]*HealthCheckRecord{record})
+}
+
+// SaveHealthCheckRecords saves several health check results in one transaction
+func (s *SQLiteStore) SaveHealthCheckRecords(records []*HealthCheckRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO history (endpoint_id, timestamp, status, response_time, status_code, error, data) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal health check record: %w", err)
+		}
+		if _, err := stmt.Exec(
+			record.EndpointID, record.Timestamp.UnixNano(), record.Status, int64(record.ResponseTime), record.StatusCode, record.Error, string(data),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetHealthHistory retrieves health check history for an endpoint, newest first,
+// along with the total number of records matching the time range
+func (s *SQLiteStore) GetHealthHistory(endpointID string, query HistoryQuery) ([]*HealthCheckRecord, int, error) {
+	where := []string{"endpoint_id = ?"}
+	args := []interface{}{endpointID}
+	if !query.From.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, query.From.UnixNano())
+	}
+	if !query.To.IsZero() {
+		where = append(where, "timestamp <= ?")
+		args = append(args, query.To.UnixNano())
+	}
+	switch query.Status {
+	case "":
+	case HistoryStatusDegraded:
+		where = append(where, "json_extract(data, '$.degraded') = 1")
+	case HistoryStatusSLOBreach:
+		where = append(where, "json_extract(data, '$.slo_breach') = 1")
+	default:
+		where = append(where, "status = ?")
+		args = append(args, query.Status)
+	}
+	clause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM history WHERE `+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(
+		`SELECT data FROM history WHERE `+clause+` ORDER BY timestamp DESC LIMIT ? OFFSET ?`,
+		append(args, limit, query.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var records []*HealthCheckRecord
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, err
+		}
+		var record HealthCheckRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// StreamHealthHistory calls fn for each of an endpoint's history records
+// between from and to (zero for unbounded), oldest first, stopping at the
+// first error fn returns. Records are read in chunks, so fn is never called
+// while a query is open.
+func (s *SQLiteStore) StreamHealthHistory(endpointID string, from, to time.Time, fn func(*HealthCheckRecord) error) error {
+	upper := int64(math.MaxInt64)
+	if !to.IsZero() {
+		upper = to.UnixNano()
+	}
+	lastTime, lastRow := int64(math.MinInt64), int64(0)
+	if !from.IsZero() {
+		lastTime = from.UnixNano() - 1
+	}
+
+	for {
+		records, n, err := s.historyChunk(endpointID, upper, &lastTime, &lastRow)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if n < historyStreamChunk {
+			return nil
+		}
+	}
+}
+
+// historyChunk reads up to historyStreamChunk records ordered after the
+// (timestamp, rowid) position in lastTime and lastRow and not after upper,
+// advancing the position past the rows it read. It also returns the number of
+// rows read, which includes any that could not be decoded.
+func (s *SQLiteStore) historyChunk(endpointID string, upper int64, lastTime, lastRow *int64) ([]*HealthCheckRecord, int, error) {
+	rows, err := s.db.Query(
+		`SELECT rowid, timestamp, data FROM history
+		WHERE endpoint_id = ? AND timestamp <= ? AND (timestamp > ? OR (timestamp = ? AND rowid > ?))
+		ORDER BY timestamp, rowid LIMIT ?`,
+		endpointID, upper, *lastTime, *lastTime, *lastRow, historyStreamChunk,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var records []*HealthCheckRecord
+	n := 0
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(lastRow, lastTime, &data); err != nil {
+			return nil, 0, err
+		}
+		n++
+		var record HealthCheckRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records, n, rows.Err()
+}
+
+// GetHistoryStats aggregates all history records, counting those at or after since in the window
+func (s *SQLiteStore) GetHistoryStats(since time.Time) (*HistoryStats, error) {
+	stats := &HistoryStats{WindowByEndpoint: make(map[string]*WindowCounts)}
+	var respTotal sql.NullInt64
+	err := s.db.QueryRow(
+		`SELECT
+			(SELECT COUNT(*) FROM history),
+			COUNT(*),
+			COALESCE(SUM(status = ?), 0),
+			SUM(CASE WHEN response_time > 0 THEN response_time END),
+			COUNT(CASE WHEN response_time > 0 THEN 1 END)
+		FROM history WHERE timestamp >= ?`,
+		string(StatusHealthy), since.UnixNano(),
+	).Scan(&stats.TotalChecks, &stats.WindowChecks, &stats.WindowHealthy, &respTotal, &stats.WindowRespSamples)
+	if err != nil {
+		return nil, err
+	}
+	stats.WindowRespTotal = time.Duration(respTotal.Int64)
+
+	rows, err := s.db.Query(
+		`SELECT endpoint_id, COUNT(*), COALESCE(SUM(status = ?), 0) FROM history WHERE timestamp >= ? GROUP BY endpoint_id`,
+		string(StatusHealthy), since.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		counts := &WindowCounts{}
+		if err := rows.Scan(&id, &counts.Checks, &counts.Healthy); err != nil {
+			return nil, err
+		}
+		stats.WindowByEndpoint[id] = counts
+	}
+	return stats, rows.Err()
+}
+
+// ClearHistory deletes all health check records for an endpoint
+func (s *SQLiteStore) ClearHistory(endpointID string) error {
+	result, err := s.db.Exec(`DELETE FROM history WHERE endpoint_id = ?`, endpointID)
+	if err != nil {
+		return err
+	}
+	deleted, _ := result.RowsAffected()
+	log.Printf("Cleared %d health check records for endpoint: %s", deleted, endpointID)
+	return nil
+}
+
+// archiveAndCleanup is CleanupOldData with archiving: it selects the records
+// to remove, archives them, and then deletes exactly those rows. Records that
+// can't be decoded for the archive are skipped and kept.
+func (s *SQLiteStore) archiveAndCleanup(cutoff time.Time) (deleted, skipped int, err error) {
+	rows, err := s.db.Query(
+		`SELECT rowid, data FROM (
+			SELECT rowid, data, timestamp, ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY timestamp DESC) AS rn FROM history
+		) WHERE timestamp < ? OR (? > 0 AND rn > ?)`,
+		cutoff.UnixNano(), s.config.MaxRecordsPerEndpoint, s.config.MaxRecordsPerEndpoint,
+	)
+	if err != nil {
+		return 0, skipped, err
+	}
+	var rowids []int64
+	var records []*HealthCheckRecord
+	for rows.Next() {
+		var rowid int64
+		var data string
+		if err := rows.Scan(&rowid, &data); err != nil {
+			rows.Close()
+			return 0, skipped, err
+		}
+		var record HealthCheckRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			skipped++
+			continue
+		}
+		rowids = append(rowids, rowid)
+		records = append(records, &record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, skipped, err
+	}
+
+	if err := archiveRecords(s.config.ArchiveDir, records); err != nil {
+		return 0, skipped, err
+	}
+
+	// Delete in chunks to stay under SQLite's bound parameter limit
+	const chunk = 500
+	for start := 0; start < len(rowids); start += chunk {
+		ids := rowids[start:min(start+chunk, len(rowids))]
+		args := make([]interface{}, len(ids))
+		for i, id := range ids {
+			args[i] = id
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		if _, err := s.db.Exec(`DELETE FROM history WHERE rowid IN (`+placeholders+`)`, args...); err != nil {
+			return start, skipped, err
+		}
+	}
+
+	if len(rowids) > 0 {
+		log.Printf("Archived and cleaned up %d old health check records (older than %d days or over per-endpoint cap)", len(rowids), DataRetentionDays)
+	}
+	return len(rowids), skipped, nil
+}
+
+// GetSetting returns the value stored under key, or nil if unset
+func (s *SQLiteStore) GetSetting(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return value, err
+}
+
+// SaveSetting stores value under key
+func (s *SQLiteStore) SaveSetting(key string, value []byte) error {
+	_, err := s.db.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+// CleanupOldData removes data older than the retention period and enforces
+// the per-endpoint cap, returning how many records it deleted
+func (s *SQLiteStore) CleanupOldData() (deleted int, err error) {
+	skipped := 0
+	defer func() { s.cleanups.record(deleted, skipped, err) }()
+
+	cutoff := time.Now().AddDate(0, 0, -DataRetentionDays)
+	if s.config.ArchiveDir != "" {
+		deleted, skipped, err = s.archiveAndCleanup(cutoff)
+		return deleted, err
+	}
+
+	result, err := s.db.Exec(`DELETE FROM history WHERE timestamp < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	deletedCount, _ := result.RowsAffected()
+
+	if limit := s.config.MaxRecordsPerEndpoint; limit > 0 {
+		result, err := s.db.Exec(
+			`DELETE FROM history WHERE rowid IN (
+				SELECT rowid FROM (
+					SELECT rowid, ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY timestamp DESC) AS rn FROM history
+				) WHERE rn > ?
+			)`,
+			limit,
+		)
+		if err != nil {
+			return int(deletedCount), err
+		}
+		capped, _ := result.RowsAffected()
+		deletedCount += capped
+	}
+
+	if deletedCount > 0 {
+		log.Printf("Cleaned up %d old health check records (older than %d days or over per-endpoint cap)", deletedCount, DataRetentionDays)
+	}
+	return int(deletedCount), nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// statsDQueueSize is how many metrics may wait to be sent before new ones are
+// dropped, so a slow network never holds up a check
+const statsDQueueSize = 1000
+
+// statsDExporter sends each check's response time as a timing and its
+// outcome as a counter to a StatsD server over UDP. With plain StatsD the
+// endpoint name is part of the metric name,
+// <prefix>.<endpoint>.response_time and <prefix>.<endpoint>.<status>; with
+// DogStatsD tags the metrics are <prefix>.check.response_time and
+// <prefix>.check.<status>, tagged with endpoint and status.
+type statsDExporter struct {
+	prefix  string
+	tags    bool
+	conn    net.Conn
+	queue   chan string
+	dropped atomic.Int64
+	// failing counts the sends that have failed in a row, so only the first
+	// error of an outage and the recovery are logged
+	failing atomic.Int64
+}
+
+// newStatsDExporter creates an exporter sending to config.StatsDAddress
+func newStatsDExporter(config ExportersConfig) (*statsDExporter, error) {
+	conn, err := net.Dial("udp", config.StatsDAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &statsDExporter{
+		prefix: config.StatsDPrefix,
+		tags:   config.StatsDTags,
+		conn:   conn,
+		queue:  make(chan string, statsDQueueSize),
+	}, nil
+}
+
+// Export queues the check's metrics, dropping them if the queue is full
+func (e *statsDExporter) Export(endpoint Endpoint, record *HealthCheckRecord) {
+	responseMs := strconv.FormatFloat(float64(record.ResponseTime.Microseconds())/1000.0, 'f', -1, 64)
+	var metrics []string
+	if e.tags {
+		tags := fmt.Sprintf("|#endpoint:%s,status:%s", statsDTagValue(endpoint.Name), statsDTagValue(record.Status))
+		metrics = []string{
+			fmt.Sprintf("%s.check.response_time:%s|ms%s", e.prefix, responseMs, tags),
+			fmt.Sprintf("%s.check.%s:1|c%s", e.prefix, statsDName(record.Status), tags),
+		}
+	} else {
+		name := e.prefix + "." + statsDName(endpoint.Name)
+		metrics = []string{
+			fmt.Sprintf("%s.response_time:%s|ms", name, responseMs),
+			fmt.Sprintf("%s.%s:1|c", name, statsDName(record.Status)),
+		}
+	}
+
+	for _, metric := range metrics {
+		select {
+		case e.queue <- metric:
+		default:
+			e.dropped.Add(1)
+		}
+	}
+}
+
+// run sends queued metrics until ctx is cancelled
+func (e *statsDExporter) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case metric := <-e.queue:
+			e.send(metric)
+		}
+	}
+}
+
+// Flush sends the metrics still queued and closes the connection
+func (e *statsDExporter) Flush() error {
+	defer e.conn.Close()
+	for {
+		select {
+		case metric := <-e.queue:
+			e.send(metric)
+		default:
+			if dropped := e.dropped.Load(); dropped > 0 {
+				log.Printf("StatsD export fell behind, dropped %d metrics in total", dropped)
+			}
+			return nil
+		}
+	}
+}
+
+// send writes one metric. UDP gives no delivery guarantee anyway, so errors
+// such as a refused port are only logged, once when sending starts failing
+// and again when it works.
+func (e *statsDExporter) send(metric string) {
+	if _, err := e.conn.Write([]byte(metric)); err != nil {
+		if e.failing.Add(1) == 1 {
+			log.Printf("Error sending StatsD metrics, not logging further errors until it works again: %v", err)
+		}
+		return
+	}
+	if failed := e.failing.Swap(0); failed > 0 {
+		log.Printf("StatsD metrics are being sent again after %d failed", failed)
+	}
+}
+
+// statsDNameInvalid matches the characters not allowed in a metric name part
+var statsDNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// statsDName turns s into a metric name part
+func statsDName(s string) string {
+	return strings.Trim(statsDNameInvalid.ReplaceAllString(s, "_"), "_")
+}
+
+// statsDTagReplacer replaces the characters that delimit DogStatsD tags
+var statsDTagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", " ")
+
+// statsDTagValue makes s safe to use as a DogStatsD tag value
+func statsDTagValue(s string) string {
+	return statsDTagReplacer.Replace(s)
+}
@@ -0,0 +1,461 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists endpoints and their health check history
+type Store interface {
+	Close() error
+	Ping() error
+
+	SaveEndpoint(endpoint *StoredEndpoint) error
+	GetEndpoint(id string) (*StoredEndpoint, error)
+	GetAllEndpoints() ([]*StoredEndpoint, error)
+	GetEnabledEndpoints() ([]*StoredEndpoint, error)
+	FindEndpointByURL(url string) (*StoredEndpoint, error)
+	DeleteEndpoint(id string) error
+	EnableEndpoint(id string) error
+	DisableEndpoint(id string) error
+	SuppressAlerts(id string) error
+	UnsuppressAlerts(id string) error
+	SnoozeAlerts(id string, until time.Time) error
+	AcknowledgeIncident(id string, at time.Time) error
+	ClearAcknowledgement(id string) error
+
+	SaveHealthCheckRecord(record *HealthCheckRecord) error
+	SaveHealthCheckRecords(records []*HealthCheckRecord) error
+	GetHealthHistory(endpointID string, query HistoryQuery) ([]*HealthCheckRecord, int, error)
+	StreamHealthHistory(endpointID string, from, to time.Time, fn func(*HealthCheckRecord) error) error
+	GetHistoryStats(since time.Time) (*HistoryStats, error)
+	ClearHistory(endpointID string) error
+	CleanupOldData() (int, error)
+
+	GetSetting(key string) ([]byte, error)
+	SaveSetting(key string, value []byte) error
+
+	DBStats() (*DBStats, error)
+}
+
+// historyStreamChunk is how many records StreamHealthHistory reads at a time.
+// Stores release their locks between chunks, so a slow consumer doesn't hold
+// up writes.
+const historyStreamChunk = 500
+
+// DBStats describes how much a store holds, to help tune retention
+type DBStats struct {
+	Driver         string     `json:"driver"`
+	Endpoints      int        `json:"endpoints"`
+	HistoryRecords int        `json:"history_records"`
+	SizeBytes      int64      `json:"size_bytes"` // on-disk size, 0 for the in-memory store
+	Bolt           *BoltStats `json:"bolt,omitempty"`
+	// LastCleanup is nil until the first cleanup has run
+	LastCleanup *CleanupStats `json:"last_cleanup,omitempty"`
+}
+
+// CleanupStats describes the outcome of a store's latest history cleanup
+type CleanupStats struct {
+	Time    time.Time `json:"time"`
+	Deleted int       `json:"deleted"`
+	// Skipped counts records cleanup couldn't decode; they are left in place
+	Skipped int    `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// cleanupTracker remembers a store's latest cleanup for DBStats
+type cleanupTracker struct {
+	mu   sync.Mutex
+	last *CleanupStats
+}
+
+// record stores the outcome of a cleanup, logging any records it skipped so
+// corrupt ones don't pile up unnoticed
+func (t *cleanupTracker) record(deleted, skipped int, err error) {
+	if skipped > 0 {
+		log.Printf("Cleanup skipped %d history records that could not be decoded", skipped)
+	}
+	stats := &CleanupStats{Time: time.Now(), Deleted: deleted, Skipped: skipped}
+	if err != nil {
+		stats.Error = err.Error()
+	}
+	t.mu.Lock()
+	t.last = stats
+	t.mu.Unlock()
+}
+
+// stats returns a copy of the latest cleanup's outcome, or nil
+func (t *cleanupTracker) stats() *CleanupStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last == nil {
+		return nil
+	}
+	stats := *t.last
+	return &stats
+}
+
+// BackupStore is implemented by stores that can write a consistent copy of
+// themselves while in use
+type BackupStore interface {
+	Backup(w io.Writer) error
+}
+
+// CompactStore is implemented by stores that can shrink their file to give
+// space freed by deleted records back to the filesystem
+type CompactStore interface {
+	Compact() (before, after int64, err error)
+}
+
+// MemoryPath selects the in-memory store when passed as the database path
+const MemoryPath = ":memory:"
+
+// OpenStore opens the storage backend selected by driver ("bolt", "sqlite" or "memory").
+// The default bolt driver with path ":memory:" also selects the in-memory store.
+func OpenStore(driver, path string, config *StorageConfig) (Store, error) {
+	if (driver == "" || driver == "bolt") && path == MemoryPath {
+		driver = "memory"
+	}
+
+	switch driver {
+	case "memory":
+		return NewMemoryStore(config), nil
+	case "", "bolt":
+		db, err := NewDatabase(path, config)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "sqlite":
+		db, err := NewSQLiteStore(path, config)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", driver)
+	}
+}
+
+// applyDefaults sets timestamps and fills in unset endpoint settings before saving
+func (e *StoredEndpoint) applyDefaults() {
+	now := time.Now()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	e.UpdatedAt = now
+
+	if e.Method == "" {
+		e.Method = "GET"
+	}
+	if e.ExpectedStatus == 0 {
+		e.ExpectedStatus = 200
+	}
+	if e.FailureThreshold == 0 {
+		e.FailureThreshold = 3
+	}
+	if e.SuccessThreshold == 0 {
+		e.SuccessThreshold = 2
+	}
+	if e.Type == CheckTypeHeartbeat && e.HeartbeatToken == "" {
+		e.HeartbeatToken = newHeartbeatToken()
+	}
+}
+
+// defaultTimeoutMigratedKey and defaultCheckIntervalMigratedKey are the
+// settings that record that a store's endpoints have had their stored default
+// timeout and check interval cleared
+const (
+	defaultTimeoutMigratedKey       = "default_timeout_migrated"
+	defaultCheckIntervalMigratedKey = "default_check_interval_migrated"
+)
+
+// migrateDefaultTimeouts clears the 10s timeout that endpoints saved before
+// default_timeout existed were all given unless they set their own, so they
+// follow default_timeout. An endpoint that set 10s on purpose can't be told
+// apart, so this only runs when asked for with -follow-defaults, and then once
+// per store.
+func migrateDefaultTimeouts(store Store) error {
+	migrated, err := migrateEndpointsOnce(store, defaultTimeoutMigratedKey, func(ep *StoredEndpoint) bool {
+		if ep.Timeout != DefaultCheckTimeout {
+			return false
+		}
+		ep.Timeout = 0
+		return true
+	})
+	if migrated > 0 {
+		log.Printf("Cleared the stored %v timeout of %d endpoints; they now follow default_timeout", DefaultCheckTimeout, migrated)
+	}
+	return err
+}
+
+// migrateDefaultCheckIntervals clears the 30s check interval that endpoints
+// were all saved with unless they set their own, so they follow
+// check_interval. Like migrateDefaultTimeouts it only runs with
+// -follow-defaults, once per store, and an endpoint that chose 30s follows
+// check_interval too.
+func migrateDefaultCheckIntervals(store Store) error {
+	migrated, err := migrateEndpointsOnce(store, defaultCheckIntervalMigratedKey, func(ep *StoredEndpoint) bool {
+		if ep.CheckInterval != DefaultCheckInterval {
+			return false
+		}
+		ep.CheckInterval = 0
+		return true
+	})
+	if migrated > 0 {
+		log.Printf("Cleared the stored %v check interval of %d endpoints; they now follow check_interval", DefaultCheckInterval, migrated)
+	}
+	return err
+}
+
+// migrateEndpointsOnce saves every endpoint that migrate changes, unless the
+// setting key says it has already run, then records that it has. It returns
+// how many endpoints were changed.
+func migrateEndpointsOnce(store Store, key string, migrate func(*StoredEndpoint) bool) (int, error) {
+	done, err := store.GetSetting(key)
+	if err != nil || done != nil {
+		return 0, err
+	}
+
+	endpoints, err := store.GetAllEndpoints()
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, ep := range endpoints {
+		if !migrate(ep) {
+			continue
+		}
+		if err := store.SaveEndpoint(ep); err != nil {
+			return migrated, fmt.Errorf("failed to migrate endpoint %s: %w", ep.Name, err)
+		}
+		migrated++
+	}
+	return migrated, store.SaveSetting(key, []byte(time.Now().Format(time.RFC3339)))
+}
+
+// HistoryPoint aggregates the health checks that fall into one time bucket
+type HistoryPoint struct {
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	Checks            int       `json:"checks"`
+	Healthy           int       `json:"healthy"`
+	UptimePercent     float64   `json:"uptime_percent"`
+	AvgResponseTimeMs float64   `json:"avg_response_time_ms"`
+	P95ResponseTimeMs float64   `json:"p95_response_time_ms"`
+}
+
+// historyBuckets aggregates records one at a time into equal time buckets,
+// oldest first, so callers can feed it from StreamHealthHistory instead of
+// loading a range. Buckets without checks are kept so the time axis stays even.
+type historyBuckets struct {
+	from   time.Time
+	width  time.Duration
+	points []HistoryPoint
+	times  [][]time.Duration
+}
+
+// newHistoryBuckets splits [from, to] into n equal time buckets
+func newHistoryBuckets(n int, from, to time.Time) *historyBuckets {
+	width := to.Sub(from) / time.Duration(n)
+	if width <= 0 {
+		n, width = 1, to.Sub(from)+1
+	}
+
+	b := &historyBuckets{
+		from:   from,
+		width:  width,
+		points: make([]HistoryPoint, n),
+		times:  make([][]time.Duration, n),
+	}
+	for i := range b.points {
+		b.points[i].Start = from.Add(time.Duration(i) * width)
+		b.points[i].End = b.points[i].Start.Add(width)
+	}
+	return b
+}
+
+// add counts a record in its bucket; records outside [from, to] are dropped
+func (b *historyBuckets) add(rec *HealthCheckRecord) {
+	n := len(b.points)
+	i := int(rec.Timestamp.Sub(b.from) / b.width)
+	if i == n {
+		i = n - 1 // the upper bound itself belongs to the last bucket
+	}
+	if i < 0 || i >= n {
+		return
+	}
+	b.points[i].Checks++
+	if rec.Status == string(StatusHealthy) {
+		b.points[i].Healthy++
+	}
+	if rec.ResponseTime > 0 {
+		b.times[i] = append(b.times[i], rec.ResponseTime)
+	}
+}
+
+// streamHistoryBuckets aggregates an endpoint's records between from and to
+// into n buckets, reading them with StreamHealthHistory so the range never has
+// to fit in memory. Zero bounds default to the oldest and newest record keep
+// accepts, which takes an extra pass. Each accepted record is also handed to
+// visit, oldest first. No buckets are returned when no record was accepted.
+func streamHistoryBuckets(store Store, endpointID string, n int, from, to time.Time, keep func(*HealthCheckRecord) bool, visit func(*HealthCheckRecord)) ([]HistoryPoint, error) {
+	lower, upper := from, to
+	if lower.IsZero() || upper.IsZero() {
+		var oldest, newest time.Time
+		err := store.StreamHealthHistory(endpointID, from, to, func(rec *HealthCheckRecord) error {
+			if keep(rec) {
+				if oldest.IsZero() {
+					oldest = rec.Timestamp
+				}
+				newest = rec.Timestamp
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if oldest.IsZero() {
+			return []HistoryPoint{}, nil
+		}
+		if lower.IsZero() {
+			lower = oldest
+		}
+		if upper.IsZero() {
+			upper = newest
+		}
+	}
+
+	b := newHistoryBuckets(n, lower, upper)
+	accepted := 0
+	err := store.StreamHealthHistory(endpointID, from, to, func(rec *HealthCheckRecord) error {
+		if !keep(rec) {
+			return nil
+		}
+		b.add(rec)
+		accepted++
+		visit(rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if accepted == 0 {
+		return []HistoryPoint{}, nil
+	}
+	return b.result(), nil
+}
+
+// result fills in each bucket's uptime and response time figures
+func (b *historyBuckets) result() []HistoryPoint {
+	for i := range b.points {
+		p := &b.points[i]
+		if p.Checks > 0 {
+			p.UptimePercent = float64(p.Healthy) / float64(p.Checks) * 100
+		}
+		times := b.times[i]
+		if len(times) == 0 {
+			continue
+		}
+		sort.Slice(times, func(a, b int) bool { return times[a] < times[b] })
+		var sum time.Duration
+		for _, d := range times {
+			sum += d
+		}
+		p.AvgResponseTimeMs = float64((sum / time.Duration(len(times))).Microseconds()) / 1000.0
+		p95 := times[(len(times)*95+99)/100-1]
+		p.P95ResponseTimeMs = float64(p95.Microseconds()) / 1000.0
+	}
+	return b.points
+}
+
+// TimelineInterval is a stretch of time during which all of an endpoint's
+// checks had the same status
+type TimelineInterval struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Status string    `json:"status"`
+}
+
+// buildTimeline collapses records into intervals of consecutive checks with the
+// same status, oldest first. Each interval ends where the next one starts; the
+// last ends at the newest record.
+func buildTimeline(records []*HealthCheckRecord) []TimelineInterval {
+	sorted := make([]*HealthCheckRecord, len(records))
+	copy(sorted, records)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	intervals := []TimelineInterval{}
+	for _, rec := range sorted {
+		if n := len(intervals); n > 0 {
+			last := &intervals[n-1]
+			last.End = rec.Timestamp
+			if last.Status == rec.Status {
+				continue
+			}
+		}
+		intervals = append(intervals, TimelineInterval{Start: rec.Timestamp, End: rec.Timestamp, Status: rec.Status})
+	}
+	return intervals
+}
+
+// checkDependencies verifies that deps names existing endpoints other than id
+// and that adding them would not create a dependency cycle
+func checkDependencies(id string, deps []string, endpoints []*StoredEndpoint) error {
+	parents := make(map[string][]string, len(endpoints))
+	for _, ep := range endpoints {
+		parents[ep.ID] = ep.DependsOn
+	}
+	for _, dep := range deps {
+		if dep == id {
+			return fmt.Errorf("endpoint cannot depend on itself")
+		}
+		if _, ok := parents[dep]; !ok {
+			return fmt.Errorf("unknown dependency: %s", dep)
+		}
+	}
+	parents[id] = deps
+
+	// Walk up from each dependency; reaching id again means a cycle
+	seen := make(map[string]bool)
+	queue := append([]string(nil), deps...)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == id {
+			return fmt.Errorf("dependency cycle through %s", id)
+		}
+		if seen[cur] {
+			continue
+		}
+		seen[cur] = true
+		queue = append(queue, parents[cur]...)
+	}
+	return nil
+}
+
+// startCleanupRoutine runs periodic cleanup of old data every
+// storage.cleanup_interval
+func startCleanupRoutine(store Store, config *StorageConfig) {
+	interval := config.CleanupInterval
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Run initial cleanup
+	if _, err := store.CleanupOldData(); err != nil {
+		log.Printf("Error during initial cleanup: %v", err)
+	}
+
+	for range ticker.C {
+		if _, err := store.CleanupOldData(); err != nil {
+			log.Printf("Error during cleanup: %v", err)
+		}
+	}
+}
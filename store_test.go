@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// storeDrivers are the storage backends every Store test runs against
+var storeDrivers = []string{"memory", "bolt", "sqlite"}
+
+// openTestStore opens a store of the given driver in a temporary directory,
+// closed when the test ends. It waits for the cleanup every store runs when
+// opened, so that it can't race with records the test saves.
+func openTestStore(t *testing.T, driver string, config *StorageConfig) Store {
+	t.Helper()
+	path := MemoryPath
+	if driver != "memory" {
+		path = filepath.Join(t.TempDir(), "cronzee.db")
+	}
+	store, err := OpenStore(driver, path, config)
+	if err != nil {
+		t.Fatalf("OpenStore(%q): %v", driver, err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		stats, err := store.DBStats()
+		if err != nil {
+			t.Fatalf("DBStats: %v", err)
+		}
+		if stats.LastCleanup != nil {
+			return store
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("initial cleanup did not run")
+		}
+	}
+}
+
+// saveRecords saves a record for endpointID at each of the given ages
+func saveRecords(t *testing.T, store Store, endpointID string, now time.Time, ages ...time.Duration) {
+	t.Helper()
+	for _, age := range ages {
+		record := &HealthCheckRecord{
+			EndpointID: endpointID,
+			Timestamp:  now.Add(-age),
+			Status:     string(StatusHealthy),
+		}
+		if err := store.SaveHealthCheckRecord(record); err != nil {
+			t.Fatalf("SaveHealthCheckRecord: %v", err)
+		}
+	}
+}
+
+// minutes returns the ages 1m through n minutes
+func minutes(n int) []time.Duration {
+	ages := make([]time.Duration, n)
+	for i := range ages {
+		ages[i] = time.Duration(i+1) * time.Minute
+	}
+	return ages
+}
+
+func TestCleanupOldData(t *testing.T) {
+	expired := time.Duration(DataRetentionDays+1) * 24 * time.Hour
+
+	tests := []struct {
+		name        string
+		maxRecords  int
+		records     map[string][]time.Duration
+		wantDeleted int
+		wantLeft    map[string]int
+	}{
+		{
+			name:        "removes records past retention",
+			records:     map[string][]time.Duration{"a": append(minutes(5), expired, expired+time.Hour)},
+			wantDeleted: 2,
+			wantLeft:    map[string]int{"a": 5},
+		},
+		{
+			name:        "caps what survives retention",
+			maxRecords:  3,
+			records:     map[string][]time.Duration{"a": append(minutes(5), expired, expired+time.Hour)},
+			wantDeleted: 4,
+			wantLeft:    map[string]int{"a": 3},
+		},
+		{
+			name:        "caps each endpoint separately",
+			maxRecords:  3,
+			records:     map[string][]time.Duration{"a": minutes(5), "b": minutes(2)},
+			wantDeleted: 2,
+			wantLeft:    map[string]int{"a": 3, "b": 2},
+		},
+		{
+			name:       "leaves endpoints under the cap alone",
+			maxRecords: 10,
+			records:    map[string][]time.Duration{"a": minutes(5)},
+			wantLeft:   map[string]int{"a": 5},
+		},
+	}
+
+	for _, driver := range storeDrivers {
+		for _, tt := range tests {
+			t.Run(driver+"/"+tt.name, func(t *testing.T) {
+				store := openTestStore(t, driver, &StorageConfig{MaxRecordsPerEndpoint: tt.maxRecords})
+				now := time.Now()
+				for id, ages := range tt.records {
+					saveRecords(t, store, id, now, ages...)
+				}
+
+				deleted, err := store.CleanupOldData()
+				if err != nil {
+					t.Fatalf("CleanupOldData: %v", err)
+				}
+				if deleted != tt.wantDeleted {
+					t.Errorf("deleted %d records, want %d", deleted, tt.wantDeleted)
+				}
+
+				for id, want := range tt.wantLeft {
+					records, total, err := store.GetHealthHistory(id, HistoryQuery{})
+					if err != nil {
+						t.Fatalf("GetHealthHistory(%q): %v", id, err)
+					}
+					if total != want || len(records) != want {
+						t.Fatalf("%s: %d records left (total %d), want %d", id, len(records), total, want)
+					}
+					// The newest records are the ones kept
+					if oldest := now.Add(-time.Duration(want) * time.Minute); records[want-1].Timestamp.Before(oldest.Add(-time.Second)) {
+						t.Errorf("%s: oldest record kept is from %v, want %v", id, records[want-1].Timestamp, oldest)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGetHealthHistoryQuery(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	// Ten records a minute apart, 1m to 10m old; every third one failed and
+	// the ones that passed slowly are flagged degraded
+	var records []*HealthCheckRecord
+	for i := 1; i <= 10; i++ {
+		record := &HealthCheckRecord{
+			EndpointID: "a",
+			Timestamp:  now.Add(-time.Duration(i) * time.Minute),
+			Status:     string(StatusHealthy),
+			Error:      fmt.Sprintf("check %d", i),
+		}
+		switch {
+		case i%3 == 0:
+			record.Status = string(StatusUnhealthy)
+		case i%4 == 0:
+			record.Degraded = true
+		}
+		records = append(records, record)
+	}
+
+	tests := []struct {
+		name      string
+		query     HistoryQuery
+		wantTotal int
+		want      []int // the records returned, by age in minutes
+	}{
+		{name: "all, newest first", query: HistoryQuery{}, wantTotal: 10, want: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "first page", query: HistoryQuery{Limit: 3}, wantTotal: 10, want: []int{1, 2, 3}},
+		{name: "second page", query: HistoryQuery{Limit: 3, Offset: 3}, wantTotal: 10, want: []int{4, 5, 6}},
+		{name: "last partial page", query: HistoryQuery{Limit: 3, Offset: 9}, wantTotal: 10, want: []int{10}},
+		{name: "offset past the end", query: HistoryQuery{Limit: 3, Offset: 20}, wantTotal: 10},
+		{name: "unhealthy", query: HistoryQuery{Status: string(StatusUnhealthy)}, wantTotal: 3, want: []int{3, 6, 9}},
+		{name: "unhealthy, paged", query: HistoryQuery{Status: string(StatusUnhealthy), Limit: 2, Offset: 1}, wantTotal: 3, want: []int{6, 9}},
+		{name: "healthy", query: HistoryQuery{Status: string(StatusHealthy), Limit: 2}, wantTotal: 7, want: []int{1, 2}},
+		{name: "degraded", query: HistoryQuery{Status: HistoryStatusDegraded}, wantTotal: 2, want: []int{4, 8}},
+		{
+			name:      "time range",
+			query:     HistoryQuery{From: now.Add(-5 * time.Minute), To: now.Add(-2 * time.Minute)},
+			wantTotal: 4,
+			want:      []int{2, 3, 4, 5},
+		},
+		{
+			name:      "time range and status",
+			query:     HistoryQuery{From: now.Add(-7 * time.Minute), To: now.Add(-2 * time.Minute), Status: string(StatusUnhealthy)},
+			wantTotal: 2,
+			want:      []int{3, 6},
+		},
+	}
+
+	for _, driver := range storeDrivers {
+		store := openTestStore(t, driver, &StorageConfig{})
+		for _, record := range records {
+			if err := store.SaveHealthCheckRecord(record); err != nil {
+				t.Fatalf("%s: SaveHealthCheckRecord: %v", driver, err)
+			}
+		}
+		// Another endpoint's history must not show up
+		saveRecords(t, store, "b", now, minutes(3)...)
+
+		for _, tt := range tests {
+			t.Run(driver+"/"+tt.name, func(t *testing.T) {
+				got, total, err := store.GetHealthHistory("a", tt.query)
+				if err != nil {
+					t.Fatalf("GetHealthHistory: %v", err)
+				}
+				if total != tt.wantTotal {
+					t.Errorf("total = %d, want %d", total, tt.wantTotal)
+				}
+				var ages []int
+				for _, record := range got {
+					ages = append(ages, int(now.Sub(record.Timestamp)/time.Minute))
+				}
+				if fmt.Sprint(ages) != fmt.Sprint(tt.want) {
+					t.Errorf("got records %v minutes old, want %v", ages, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spans are sent to the collector in batches of up to tracingBatchSize, at
+// least every tracingFlushInterval. While the collector is unreachable at most
+// tracingMaxPending spans wait; beyond that the oldest are dropped.
+const (
+	tracingFlushInterval = 5 * time.Second
+	tracingBatchSize     = 512
+	tracingMaxPending    = 10 * tracingBatchSize
+	tracingTimeout       = 10 * time.Second
+)
+
+// OTLP span kinds and status codes, from the OpenTelemetry protocol
+const (
+	otlpSpanKindClient = 3
+	otlpStatusOK       = 1
+	otlpStatusError    = 2
+)
+
+// tracer records a span for every check and sends them to an OpenTelemetry
+// collector with OTLP over HTTP, JSON encoded. A nil tracer records nothing.
+type tracer struct {
+	*batcher[otlpSpan]
+	config OTLPConfig
+	client *http.Client
+}
+
+// newTracer creates a tracer for config, or returns nil if no collector
+// endpoint is configured
+func newTracer(config OTLPConfig) *tracer {
+	if config.Endpoint == "" {
+		return nil
+	}
+	t := &tracer{
+		config: config,
+		client: &http.Client{Timeout: tracingTimeout},
+	}
+	t.batcher = newBatcher("spans", config.Endpoint, tracingBatchSize, tracingMaxPending, tracingFlushInterval, t.export)
+	return t
+}
+
+// checkSpan is a check in progress. A nil checkSpan records nothing.
+type checkSpan struct {
+	tracer   *tracer
+	id       string
+	endpoint Endpoint
+	traceID  string
+	spanID   string
+	start    time.Time
+}
+
+// start begins the span of a check of the endpoint with the given ID
+func (t *tracer) start(id string, endpoint Endpoint) *checkSpan {
+	if t == nil {
+		return nil
+	}
+	return &checkSpan{
+		tracer:   t,
+		id:       id,
+		endpoint: endpoint,
+		traceID:  randomHex(16),
+		spanID:   randomHex(8),
+		start:    time.Now(),
+	}
+}
+
+// propagate returns endpoint with a W3C traceparent header added for HTTP
+// and GraphQL checks when exporters.otlp.propagate is set, so spans of the
+// checked service join the check's trace
+func (s *checkSpan) propagate(endpoint Endpoint) Endpoint {
+	if s == nil || !s.tracer.config.Propagate {
+		return endpoint
+	}
+	if checkType := checkTypeOrDefault(endpoint.Type); checkType != CheckTypeHTTP && checkType != CheckTypeGraphQL {
+		return endpoint
+	}
+	headers := make(map[string]string, len(endpoint.Headers)+1)
+	for name, value := range endpoint.Headers {
+		headers[name] = value
+	}
+	headers["traceparent"] = "00-" + s.traceID + "-" + s.spanID + "-01"
+	endpoint.Headers = headers
+	return endpoint
+}
+
+// end finishes the span with the outcome of the check. target is the URL
+// that passed, or the last one tried, and errorMsg is empty if the check
+// passed.
+func (s *checkSpan) end(target string, result checkResult, errorMsg string) {
+	if s == nil {
+		return
+	}
+	checkType := checkTypeOrDefault(s.endpoint.Type)
+	attrs := []otlpAttribute{
+		stringAttribute("cronzee.endpoint.id", s.id),
+		stringAttribute("cronzee.endpoint.name", s.endpoint.Name),
+		stringAttribute("cronzee.check.type", checkType),
+		stringAttribute("url.full", target),
+	}
+	switch checkType {
+	case CheckTypeHTTP:
+		attrs = append(attrs, stringAttribute("http.request.method", s.endpoint.Method))
+	case CheckTypeGraphQL:
+		// GraphQL queries always go out as a POST
+		attrs = append(attrs, stringAttribute("http.request.method", http.MethodPost))
+	}
+	if result.statusCode != 0 {
+		attrs = append(attrs, intAttribute("http.response.status_code", result.statusCode))
+	}
+	if result.remoteAddr != "" {
+		attrs = append(attrs, stringAttribute("network.peer.address", result.remoteAddr))
+	}
+	outcome, status := string(StatusHealthy), otlpStatus{Code: otlpStatusOK}
+	if errorMsg != "" {
+		outcome, status = string(StatusUnhealthy), otlpStatus{Code: otlpStatusError, Message: errorMsg}
+	}
+	attrs = append(attrs, stringAttribute("cronzee.check.outcome", outcome))
+
+	s.tracer.add(otlpSpan{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		Name:              "check " + s.endpoint.Name,
+		Kind:              otlpSpanKindClient,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(time.Now().UnixNano(), 10),
+		Attributes:        attrs,
+		Status:            status,
+	})
+}
+
+// checkTypeOrDefault returns the check type, treating empty as http
+func checkTypeOrDefault(checkType string) string {
+	if checkType == "" {
+		return CheckTypeHTTP
+	}
+	return checkType
+}
+
+// export sends one batch of spans to the collector's /v1/traces
+func (t *tracer) export(spans []otlpSpan) error {
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{stringAttribute("service.name", t.config.ServiceName)},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "cronzee", "version": version},
+				"spans": spans,
+			}},
+		}},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(t.config.Endpoint, "/")+"/v1/traces", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range t.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// otlpSpan is a span in the OTLP JSON encoding
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+// otlpStatus is a span's status in the OTLP JSON encoding
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpAttribute is a key-value attribute in the OTLP JSON encoding
+type otlpAttribute struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+// stringAttribute returns a string-valued attribute
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]string{"stringValue": value}}
+}
+
+// intAttribute returns an integer-valued attribute; OTLP JSON encodes 64-bit
+// integers as strings
+func intAttribute(key string, value int) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]string{"intValue": strconv.Itoa(value)}}
+}
+
+// randomHex returns n random bytes, hex encoded, for trace and span IDs
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
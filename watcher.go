@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDebounce collapses the burst of events editors emit on save
+const configReloadDebounce = 500 * time.Millisecond
+
+// WatchConfig watches the config file and reloads it into the monitor when it changes.
+// The parent directory is watched so editors that save via rename are picked up.
+func WatchConfig(path string, monitor *Monitor) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	log.Printf("Watching %s for configuration changes", absPath)
+
+	go func() {
+		defer watcher.Close()
+
+		var debounce *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != absPath {
+					continue
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+					continue
+				}
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(configReloadDebounce, func() {
+					reloadConfig(absPath, monitor)
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Config watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// reloadConfig loads and applies the config file, keeping the current config on error
+func reloadConfig(path string, monitor *Monitor) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		log.Printf("Ignoring config change: %v", err)
+		return
+	}
+	if err := monitor.ReloadConfig(config); err != nil {
+		log.Printf("Ignoring config change: %v", err)
+	}
+}
@@ -0,0 +1,1052 @@
+let endpointsData = {};
+
+function formatDuration(ms) {
+    if (ms < 1000) return ms.toFixed(0) + 'ms';
+    return (ms / 1000).toFixed(2) + 's';
+}
+
+function formatBytes(bytes) {
+    const units = ['B', 'KB', 'MB', 'GB', 'TB'];
+    let i = 0;
+    while (bytes >= 1024 && i < units.length - 1) {
+        bytes /= 1024;
+        i++;
+    }
+    return (i === 0 ? bytes : bytes.toFixed(1)) + ' ' + units[i];
+}
+
+function formatCountdown(until) {
+    const secs = Math.max(0, Math.floor((new Date(until) - new Date()) / 1000));
+    const h = Math.floor(secs / 3600), m = Math.floor(secs % 3600 / 60), s = secs % 60;
+    if (h > 0) return h + 'h ' + String(m).padStart(2, '0') + 'm';
+    return m + 'm ' + String(s).padStart(2, '0') + 's';
+}
+
+function formatTime(timestamp) {
+    return new Date(timestamp).toLocaleTimeString();
+}
+
+// formatGoDuration shortens a Go duration string such as "1m0s" to "1m"
+function formatGoDuration(d) {
+    return d.replace(/(\D)0s$/, '$1').replace(/(\D)0m$/, '$1');
+}
+
+function formatInterval(ns) {
+    if (!ns) return '30s';
+    const seconds = ns / 1000000000;
+    if (seconds >= 60) return Math.round(seconds / 60) + 'm';
+    return Math.round(seconds) + 's';
+}
+
+// REFRESH_INTERVAL_MS and CHART_POINTS come from server.refresh_interval and
+// server.chart_points, rendered into the page by the server
+const REFRESH_INTERVAL_MS = Number(document.body.dataset.refreshMs) || 30000;
+const CHART_POINTS = Number(document.body.dataset.chartPoints) || 50;
+
+// PAGE_TITLE is server.title; the tab title and favicon also show the status
+const PAGE_TITLE = document.title;
+
+// loadHistoryCharts draws every endpoint's sparkline from one request, so a
+// refresh costs the same against server.rate_limit however many endpoints
+// there are
+async function loadHistoryCharts() {
+    try {
+        const resp = await fetch('/api/history/recent?limit=' + CHART_POINTS);
+        if (!resp.ok) return;
+        const data = await resp.json();
+        Object.entries(data.endpoints || {}).forEach(([id, recent]) => drawHistoryChart(id, recent));
+    } catch (err) {
+        console.error('Error loading history:', err);
+    }
+}
+
+// drawHistoryChart fills an endpoint's sparkline and average response time
+function drawHistoryChart(endpointId, data) {
+    try {
+        const chart = document.getElementById('chart-' + endpointId);
+        if (!chart) return;
+        
+        chart.innerHTML = '';
+        const records = (data.records || []).reverse();
+        
+        if (records.length === 0) {
+            chart.innerHTML = '<span style="color:#9ca3af;font-size:0.7em;margin:auto;">No history</span>';
+            return;
+        }
+        
+        records.slice(0, 20).forEach(record => {
+            const bar = document.createElement('div');
+            bar.className = 'bar';
+            if (record.status === 'healthy') {
+                bar.classList.add('success');
+            } else if (record.status === 'unhealthy') {
+                bar.classList.add('failure');
+            } else {
+                bar.classList.add('unknown');
+            }
+            const respTime = record.response_time ? formatDuration(record.response_time / 1000000) : '-';
+            bar.title = record.status + ' | ' + respTime + ' | ' + new Date(record.timestamp).toLocaleString();
+            chart.appendChild(bar);
+        });
+        
+        // Update average response time
+        const avgEl = document.getElementById('avg-' + endpointId);
+        if (avgEl && data.avg_response_time_ms) {
+            avgEl.textContent = formatDuration(data.avg_response_time_ms);
+        }
+    } catch (err) {
+        console.error('Error loading history:', err);
+    }
+}
+
+function showToast(message, type = 'success') {
+    const toast = document.createElement('div');
+    toast.className = 'toast ' + type;
+    toast.textContent = message;
+    document.body.appendChild(toast);
+    setTimeout(() => toast.remove(), 3000);
+}
+
+// cloneSourceId is the endpoint being cloned while the add modal is in clone mode
+let cloneSourceId = '';
+
+function openAddModal() {
+    document.getElementById('addModal').classList.add('active');
+    updateTypeFields();
+    toggleStatusAny('ep');
+}
+
+// parseList splits a comma separated list such as tags or URLs, dropping blanks
+function parseList(value) {
+    return value.split(',').map(t => t.trim()).filter(t => t !== '');
+}
+
+// toggleStatusAny disables the status code input while "any status" is checked
+function toggleStatusAny(prefix) {
+    document.getElementById(prefix + '-status').disabled = document.getElementById(prefix + '-status-any').checked;
+}
+
+// expectedStatusValue returns the expected status to submit; -1 accepts any code
+function expectedStatusValue(prefix) {
+    if (document.getElementById(prefix + '-status-any').checked) return -1;
+    return parseInt(document.getElementById(prefix + '-status').value) || 200;
+}
+
+// updateTypeFields shows only the add-form fields relevant to the selected check type
+function updateTypeFields() {
+    const type = document.getElementById('ep-type').value;
+    document.querySelectorAll('#addForm .type-field').forEach(el => {
+        el.style.display = el.dataset.types.split(' ').includes(type) ? '' : 'none';
+    });
+    // Heartbeat endpoints are pinged rather than polled, so they need no URL
+    document.getElementById('ep-url').required = type !== 'heartbeat';
+}
+
+function addHeaderRow(containerId, key = '', value = '') {
+    const row = document.createElement('div');
+    row.className = 'header-row';
+    const keyInput = document.createElement('input');
+    keyInput.type = 'text';
+    keyInput.className = 'header-key';
+    keyInput.placeholder = 'Header name';
+    keyInput.value = key;
+    const valueInput = document.createElement('input');
+    valueInput.type = 'text';
+    valueInput.className = 'header-value';
+    valueInput.placeholder = 'Value';
+    valueInput.value = value;
+    const removeBtn = document.createElement('button');
+    removeBtn.type = 'button';
+    removeBtn.className = 'icon-btn delete';
+    removeBtn.title = 'Remove Header';
+    removeBtn.textContent = '✕';
+    removeBtn.onclick = () => row.remove();
+    row.append(keyInput, valueInput, removeBtn);
+    document.getElementById(containerId).appendChild(row);
+}
+
+function setHeaderRows(containerId, headers) {
+    document.getElementById(containerId).innerHTML = '';
+    Object.entries(headers || {}).forEach(([key, value]) => addHeaderRow(containerId, key, value));
+}
+
+function collectHeaders(containerId) {
+    const headers = {};
+    document.querySelectorAll('#' + containerId + ' .header-row').forEach(row => {
+        const key = row.querySelector('.header-key').value.trim();
+        if (key) headers[key] = row.querySelector('.header-value').value;
+    });
+    return headers;
+}
+
+function closeAddModal() {
+    document.getElementById('addModal').classList.remove('active');
+    document.getElementById('addForm').reset();
+    setHeaderRows('ep-headers', {});
+    cloneSourceId = '';
+    document.getElementById('add-title').textContent = 'Add New Endpoint';
+}
+
+// openCloneModal opens the add modal prefilled with an endpoint's settings.
+// The URL is left empty since two endpoints can't check the same target;
+// settings the form doesn't show are copied by the server.
+function openCloneModal(id) {
+    const ep = endpointsData[id] || {};
+    cloneSourceId = id;
+    document.getElementById('add-title').textContent = 'Clone ' + ep.name;
+    document.getElementById('ep-name').value = ep.name + ' (copy)';
+    document.getElementById('ep-type').value = ep.type || 'http';
+    document.getElementById('ep-url').value = '';
+    document.getElementById('ep-urls').value = '';
+    document.getElementById('ep-expected-ip').value = ep.expected_ip || '';
+    document.getElementById('ep-graphql-query').value = ep.graphql_query || '';
+    document.getElementById('ep-graphql-data-path').value = ep.graphql_data_path || '';
+    document.getElementById('ep-json-path').value = ep.json_path || '';
+    document.getElementById('ep-json-path-expected').value = ep.json_path_expected || '';
+    document.getElementById('ep-method').value = (ep.method || 'GET').toUpperCase();
+    document.getElementById('ep-body').value = ep.body || '';
+    document.getElementById('ep-interval').value = ep.check_interval ? formatInterval(ep.check_interval) : '';
+    document.getElementById('ep-timeout').value = ep.timeout ? formatInterval(ep.timeout) : '';
+    document.getElementById('ep-connect-timeout').value = ep.connect_timeout ? formatInterval(ep.connect_timeout) : '';
+    const status = ep.expected_status || 200;
+    document.getElementById('ep-status-any').checked = status === -1;
+    document.getElementById('ep-status').value = status === -1 ? '' : status;
+    document.getElementById('ep-failure').value = ep.failure_threshold || 3;
+    document.getElementById('ep-failure-duration').value = ep.failure_duration ? formatInterval(ep.failure_duration) : '';
+    document.getElementById('ep-success').value = ep.success_threshold || 2;
+    document.getElementById('ep-response-time-slo').value = ep.response_time_slo ? (ep.response_time_slo / 1000000) + 'ms' : '';
+    document.getElementById('ep-invert').checked = !!ep.invert;
+    document.getElementById('ep-backoff').checked = !!ep.backoff;
+    document.getElementById('ep-always-alert').checked = !!ep.always_alert;
+    document.getElementById('ep-alert-first-failure').checked = !!ep.alert_on_first_failure;
+    document.getElementById('ep-slack-mention').value = ep.slack_mention || '';
+    document.getElementById('ep-alert-emails').value = (ep.alert_emails || []).join(', ');
+    document.getElementById('ep-tags').value = (ep.tags || []).join(', ');
+    document.getElementById('ep-user-agent').value = ep.user_agent || '';
+    document.getElementById('ep-proxy-url').value = ep.proxy_url || '';
+    document.getElementById('ep-address-family').value = ep.address_family || '';
+    document.getElementById('ep-resolve-override').value = ep.resolve_override || '';
+    document.getElementById('ep-disable-keep-alives').checked = !!ep.disable_keep_alives;
+    document.getElementById('ep-max-redirects').value = ep.max_redirects || '';
+    document.getElementById('ep-websocket-ping').checked = !!ep.websocket_ping;
+    document.getElementById('ep-min-body-bytes').value = ep.assert_min_body_bytes || '';
+    document.getElementById('ep-max-body-bytes').value = ep.assert_max_body_bytes || '';
+    document.getElementById('ep-body-must-not-contain').value = ep.body_must_not_contain || '';
+    setHeaderRows('ep-headers', ep.headers);
+    openAddModal();
+}
+
+async function addEndpoint(e) {
+    e.preventDefault();
+    const data = {
+        name: document.getElementById('ep-name').value,
+        type: document.getElementById('ep-type').value,
+        url: document.getElementById('ep-url').value,
+        expected_ip: document.getElementById('ep-expected-ip').value,
+        graphql_query: document.getElementById('ep-graphql-query').value.trim(),
+        graphql_data_path: document.getElementById('ep-graphql-data-path').value.trim(),
+        json_path: document.getElementById('ep-json-path').value.trim(),
+        json_path_expected: document.getElementById('ep-json-path-expected').value.trim(),
+        method: document.getElementById('ep-method').value,
+        body: document.getElementById('ep-body').value,
+        check_interval: document.getElementById('ep-interval').value,
+        timeout: document.getElementById('ep-timeout').value,
+        connect_timeout: document.getElementById('ep-connect-timeout').value.trim(),
+        expected_status: expectedStatusValue('ep'),
+        failure_threshold: parseInt(document.getElementById('ep-failure').value) || 3,
+        failure_duration: document.getElementById('ep-failure-duration').value.trim(),
+        success_threshold: parseInt(document.getElementById('ep-success').value) || 2,
+        response_time_slo: document.getElementById('ep-response-time-slo').value.trim(),
+        invert: document.getElementById('ep-invert').checked,
+        backoff: document.getElementById('ep-backoff').checked,
+        always_alert: document.getElementById('ep-always-alert').checked,
+        alert_on_first_failure: document.getElementById('ep-alert-first-failure').checked,
+        slack_mention: document.getElementById('ep-slack-mention').value.trim(),
+        alert_emails: parseList(document.getElementById('ep-alert-emails').value),
+        tags: parseList(document.getElementById('ep-tags').value),
+        urls: parseList(document.getElementById('ep-urls').value),
+        user_agent: document.getElementById('ep-user-agent').value.trim(),
+        proxy_url: document.getElementById('ep-proxy-url').value.trim(),
+        address_family: document.getElementById('ep-address-family').value,
+        resolve_override: document.getElementById('ep-resolve-override').value.trim(),
+        disable_keep_alives: document.getElementById('ep-disable-keep-alives').checked,
+        max_redirects: parseInt(document.getElementById('ep-max-redirects').value) || 0,
+        websocket_ping: document.getElementById('ep-websocket-ping').checked,
+        assert_min_body_bytes: parseInt(document.getElementById('ep-min-body-bytes').value) || 0,
+        assert_max_body_bytes: parseInt(document.getElementById('ep-max-body-bytes').value) || 0,
+        body_must_not_contain: document.getElementById('ep-body-must-not-contain').value,
+        headers: collectHeaders('ep-headers')
+    };
+    if (cloneSourceId) data.id = cloneSourceId;
+    try {
+        const resp = await fetch(cloneSourceId ? '/api/endpoints/clone' : '/api/endpoints/add', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify(data)
+        });
+        if (resp.ok) {
+            const result = await resp.json();
+            showToast(cloneSourceId ? 'Endpoint cloned' : 'Endpoint added successfully');
+            // The token isn't shown again, so offer the ping URL for copying now
+            const ep = result.endpoint || {};
+            if (ep.heartbeat_token) {
+                prompt('Have the job ping this URL:', location.origin + '/api/ping/' + ep.id + '/' + ep.heartbeat_token);
+            }
+            closeAddModal();
+            updateDashboard();
+        } else {
+            const err = await resp.text();
+            showToast(err, 'error');
+        }
+    } catch (err) {
+        showToast('Failed to add endpoint', 'error');
+    }
+}
+
+// importEndpoints uploads an export file; endpoints that already exist are skipped
+async function importEndpoints(input) {
+    const file = input.files[0];
+    input.value = '';
+    if (!file) return;
+    const format = /\.ya?ml$/i.test(file.name) ? 'yaml' : 'json';
+    try {
+        const resp = await fetch('/api/endpoints/import?format=' + format, {
+            method: 'POST',
+            body: await file.text()
+        });
+        if (resp.ok) {
+            const result = await resp.json();
+            showToast(`Imported: ${result.created} created, ${result.skipped} skipped`);
+            updateDashboard();
+        } else {
+            showToast('Import failed: ' + await resp.text(), 'error');
+        }
+    } catch (err) {
+        showToast('Import failed', 'error');
+    }
+}
+
+async function deleteEndpoint(id, name) {
+    if (!confirm('Delete endpoint "' + name + '"?')) return;
+    try {
+        const resp = await fetch('/api/endpoints/delete', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({id: id})
+        });
+        const text = await resp.text();
+        if (resp.ok) {
+            showToast('Endpoint deleted');
+            updateDashboard();
+        } else {
+            showToast('Failed to delete endpoint: ' + text, 'error');
+        }
+    } catch (err) {
+        console.error('Delete error:', err);
+        showToast('Failed to delete endpoint', 'error');
+    }
+}
+
+async function toggleEndpoint(id, enable) {
+    const action = enable ? 'enable' : 'disable';
+    try {
+        const resp = await fetch('/api/endpoints/' + action, {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({id: id})
+        });
+        if (resp.ok) {
+            showToast('Endpoint ' + action + 'd');
+            updateDashboard();
+        } else {
+            showToast('Failed to ' + action + ' endpoint', 'error');
+        }
+    } catch (err) {
+        showToast('Failed to ' + action + ' endpoint', 'error');
+    }
+}
+
+async function toggleAlerts(id, suppress) {
+    const action = suppress ? 'suppress' : 'unsuppress';
+    try {
+        const resp = await fetch('/api/endpoints/' + action, {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({id: id})
+        });
+        if (resp.ok) {
+            showToast(suppress ? 'Alerts suppressed' : 'Alerts enabled');
+            updateDashboard();
+        } else {
+            showToast('Failed to update alerts', 'error');
+        }
+    } catch (err) {
+        showToast('Failed to update alerts', 'error');
+    }
+}
+
+// updateGroups renders one rollup card per endpoint tag
+async function updateGroups() {
+    try {
+        const resp = await fetch('/api/groups');
+        const data = await resp.json();
+        const container = document.getElementById('groups');
+        container.innerHTML = (data.groups || []).map(g => `
+            <div class="group-card ${g.status}">
+                <h3>${g.name}</h3>
+                <div class="group-status">${g.status}</div>
+                <div class="group-summary">${g.summary}${g.checks_24h > 0 ? ' • ' + g.uptime_24h_percent.toFixed(2) + '% uptime (24h)' : ''}</div>
+            </div>
+        `).join('');
+    } catch (error) {
+        console.error('Error fetching groups:', error);
+    }
+}
+
+async function updateDBStats() {
+    try {
+        const resp = await fetch('/api/dbstats');
+        const data = await resp.json();
+        const size = data.driver === 'memory' ? 'in memory' : formatBytes(data.size_bytes);
+        document.getElementById('db-size').textContent =
+            'Database: ' + size + ', ' + data.history_records.toLocaleString() + ' records';
+    } catch (error) {
+        console.error('Error fetching database stats:', error);
+    }
+}
+
+async function updateDashboard() {
+    updateGroups();
+    updateDBStats();
+    try {
+        const params = new URLSearchParams({
+            sort: document.getElementById('endpoint-sort').value,
+            search: document.getElementById('endpoint-search').value.trim()
+        });
+        const [statusResp, endpointsResp] = await Promise.all([
+            fetch('/api/status?' + params),
+            fetch('/api/endpoints?' + params)
+        ]);
+        const statusData = await statusResp.json();
+        const endpointsDbData = await endpointsResp.json();
+        
+        // Create a map of endpoint settings from DB
+        const dbEndpoints = {};
+        (endpointsDbData.endpoints || []).forEach(ep => {
+            dbEndpoints[ep.id] = ep;
+        });
+
+        
+        const endpointsContainer = document.getElementById('endpoints');
+        endpointsContainer.innerHTML = '';
+
+        // Combine status data with DB settings, in the order the server sorted them
+        const allEndpoints = [];
+        (statusData.order || []).forEach(name => {
+            const endpoint = statusData.endpoints[name];
+            const dbEp = Object.values(dbEndpoints).find(e => e.name === endpoint.name) || {};
+            // The stored check_interval is 0 for endpoints that follow the
+            // global one, so keep the interval actually in use for display
+            allEndpoints.push({...endpoint, ...dbEp, id: endpoint.id || dbEp.id || name, effective_interval: endpoint.check_interval});
+        });
+
+        // Also add any DB endpoints not in status
+        Object.values(dbEndpoints).forEach(dbEp => {
+            if (!allEndpoints.find(e => e.id === dbEp.id)) {
+                allEndpoints.push({...dbEp, status: 'unknown'});
+            }
+        });
+
+        endpointsData = {};
+        allEndpoints.forEach(endpoint => {
+            endpointsData[endpoint.id] = endpoint;
+            const isEnabled = endpoint.enabled !== false;
+            const isSuppressed = endpoint.alerts_suppressed === true;
+            const isAcked = endpoint.acknowledged === true;
+            const isSnoozed = !!endpoint.snooze_until && new Date(endpoint.snooze_until) > new Date();
+
+            const row = document.createElement('div');
+            row.className = 'endpoint-row ' + endpoint.status + (isEnabled ? '' : ' disabled');
+            
+            row.innerHTML = `
+                <div class="endpoint-status ${endpoint.status}"></div>
+                <a class="endpoint-name" href="/endpoint/${encodeURIComponent(endpoint.id)}" title="Open the page for ${endpoint.name}">${endpoint.name}</a>
+                <div class="endpoint-url" title="${[...new Set([endpoint.url, ...(endpoint.urls || [])])].join('\n')}">${endpoint.active_url || endpoint.url}</div>
+                ${isAcked ? '<span class="badge-ack" title="Incident acknowledged">ACKED</span>' : ''}
+                ${isSnoozed ? `<span class="badge-snooze" title="Alerts snoozed" data-until="${endpoint.snooze_until}">💤 ${formatCountdown(endpoint.snooze_until)}</span>` : ''}
+                ${endpoint.degraded ? `<span class="badge-slow" title="Recent response times are well above the baseline of ${formatDuration(endpoint.baseline_response_time_ms || 0)}">SLOW</span>` : ''}
+                ${endpoint.slo_breach ? `<span class="badge-slo" title="Last check took longer than the SLO of ${formatDuration(endpoint.response_time_slo_ms)}${endpoint.slo_compliance_24h != null ? '; ' + endpoint.slo_compliance_24h.toFixed(2) + '% of checks within it (24h)' : ''}">SLO</span>` : ''}
+                ${endpoint.flapping ? '<span class="badge-flap" title="Status is changing repeatedly; alerts paused">FLAPPING</span>' : ''}
+                <div class="history-mini" id="chart-${endpoint.id}"></div>
+                <div class="endpoint-stats">
+                    <span title="Response Time">${formatDuration(endpoint.response_time_ms || 0)}</span>
+                    <span class="stat-avg" title="Avg Response" id="avg-${endpoint.id}">-</span>
+                    <span title="Interval">${endpoint.effective_interval ? formatGoDuration(endpoint.effective_interval) : formatInterval(endpoint.check_interval)}</span>
+                    <span class="stat-success" title="Consecutive Successes">✓${endpoint.consecutive_successes || 0}</span>
+                    <span class="stat-fail" title="Consecutive Failures">✗${endpoint.consecutive_failures || 0}</span>
+                </div>
+                <div class="endpoint-actions" data-endpoint-id="${endpoint.id}" data-endpoint-name="${endpoint.name}" data-url="${endpoint.url}"
+                     data-timeout="${endpoint.timeout ? formatInterval(endpoint.timeout) : ''}"
+                     data-failure="${endpoint.failure_threshold || 3}" data-success="${endpoint.success_threshold || 2}">
+                    <button class="icon-btn edit" data-action="history" title="View History">📊</button>
+                    <button class="icon-btn edit mutating" data-action="edit" title="Edit">✏️</button>
+                    <button class="icon-btn edit mutating" data-action="clone" title="Clone">📋</button>
+                    ${endpoint.status === 'unhealthy' && !isAcked ? '<button class="icon-btn ack mutating" data-action="acknowledge" title="Acknowledge Incident">✋</button>' : ''}
+                    ${isEnabled ? '<button class="icon-btn edit mutating" data-action="check" title="Check Now">⚡</button>' : ''}
+                    <button class="icon-btn edit mutating" data-action="reset" title="Reset Counters">🔄</button>
+                    <button class="icon-btn mutating ${isEnabled ? 'toggle-on' : 'toggle-off'}" data-action="${isEnabled ? 'disable' : 'enable'}" title="${isEnabled ? 'Disable' : 'Enable'}">${isEnabled ? '⏸️' : '▶️'}</button>
+                    <button class="icon-btn mutating ${isSuppressed ? 'alert-on' : 'alert-off'}" data-action="${isSuppressed ? 'unsuppress' : 'suppress'}" title="${isSuppressed ? 'Enable Alerts' : 'Suppress Alerts'}">${isSuppressed ? '🔔' : '🔕'}</button>
+                    <button class="icon-btn mutating ${isSnoozed ? 'alert-on' : 'alert-off'}" data-action="${isSnoozed ? 'unsnooze' : 'snooze'}" title="${isSnoozed ? 'End Snooze' : 'Snooze Alerts'}">${isSnoozed ? '⏰' : '💤'}</button>
+                    <button class="icon-btn delete mutating" data-action="delete" title="Delete">🗑️</button>
+                </div>
+            `;
+            
+            endpointsContainer.appendChild(row);
+        });
+        loadHistoryCharts();
+
+        // The summary covers every endpoint, not just those the search matched
+        const counts = statusData.counts;
+        document.getElementById('total-endpoints').textContent = counts.total;
+        document.getElementById('healthy-count').textContent = counts.healthy;
+        document.getElementById('unhealthy-count').textContent = counts.unhealthy;
+        document.getElementById('disabled-count').textContent = counts.disabled;
+        updateTabStatus(counts.healthy, counts.unhealthy);
+        document.getElementById('last-update').textContent = new Date().toLocaleTimeString();
+    } catch (error) {
+        console.error('Error fetching status:', error);
+    }
+}
+
+// Event delegation for action buttons
+document.addEventListener('click', async function(e) {
+    const btn = e.target.closest('[data-action]');
+    if (!btn) return;
+    
+    const action = btn.dataset.action;
+    const actionsDiv = btn.closest('.endpoint-actions');
+    const id = actionsDiv ? actionsDiv.dataset.endpointId : '';
+    const name = actionsDiv ? actionsDiv.dataset.endpointName : id;
+    
+    if (action === 'delete') {
+        if (!confirm('Delete endpoint "' + name + '"?')) return;
+        try {
+            const resp = await fetch('/api/endpoints/delete', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id})
+            });
+            if (resp.ok) {
+                showToast('Endpoint deleted');
+                updateDashboard();
+            } else {
+                const text = await resp.text();
+                showToast('Failed: ' + text, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to delete', 'error');
+        }
+    } else if (action === 'enable' || action === 'disable') {
+        try {
+            const resp = await fetch('/api/endpoints/' + action, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id})
+            });
+            if (resp.ok) {
+                showToast('Endpoint ' + action + 'd');
+                updateDashboard();
+            } else {
+                showToast('Failed to ' + action, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to ' + action, 'error');
+        }
+    } else if (action === 'suppress' || action === 'unsuppress') {
+        try {
+            const resp = await fetch('/api/endpoints/' + action, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id})
+            });
+            if (resp.ok) {
+                showToast(action === 'suppress' ? 'Alerts suppressed' : 'Alerts enabled');
+                updateDashboard();
+            } else {
+                showToast('Failed to update alerts', 'error');
+            }
+        } catch (err) {
+            showToast('Failed to update alerts', 'error');
+        }
+    } else if (action === 'snooze' || action === 'unsnooze') {
+        let duration = '0s';
+        if (action === 'snooze') {
+            duration = prompt('Snooze alerts for "' + name + '" for how long? (e.g. 30m, 2h)', '1h');
+            if (!duration) return;
+        }
+        try {
+            const resp = await fetch('/api/endpoints/snooze', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id, duration: duration.trim()})
+            });
+            if (resp.ok) {
+                showToast(action === 'snooze' ? 'Alerts snoozed for ' + duration.trim() : 'Snooze ended');
+                updateDashboard();
+            } else {
+                const text = await resp.text();
+                showToast('Failed: ' + text, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to update snooze', 'error');
+        }
+    } else if (action === 'check') {
+        try {
+            const resp = await fetch('/api/endpoints/check', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({ids: [id]})
+            });
+            if (resp.ok) {
+                const data = await resp.json();
+                const result = data.results[0];
+                showToast(name + ': ' + result.status, result.status === 'healthy' ? 'success' : 'error');
+                updateDashboard();
+            } else {
+                const text = await resp.text();
+                showToast('Failed: ' + text, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to run check', 'error');
+        }
+    } else if (action === 'reset') {
+        if (!confirm('Reset counters and status for "' + name + '"?')) return;
+        try {
+            const resp = await fetch('/api/endpoints/reset', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id})
+            });
+            if (resp.ok) {
+                showToast('Endpoint reset');
+                updateDashboard();
+            } else {
+                const text = await resp.text();
+                showToast('Failed: ' + text, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to reset', 'error');
+        }
+    } else if (action === 'acknowledge') {
+        try {
+            const resp = await fetch('/api/endpoints/acknowledge', {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({id: id})
+            });
+            if (resp.ok) {
+                showToast('Incident acknowledged');
+                updateDashboard();
+            } else {
+                const text = await resp.text();
+                showToast('Failed: ' + text, 'error');
+            }
+        } catch (err) {
+            showToast('Failed to acknowledge', 'error');
+        }
+    } else if (action === 'edit') {
+        openEditModal(id, name, actionsDiv.dataset.url, actionsDiv.dataset.timeout, 
+                      actionsDiv.dataset.failure, actionsDiv.dataset.success);
+    } else if (action === 'clone') {
+        openCloneModal(id);
+    } else if (action === 'history') {
+        openHistoryModal(id, name);
+    }
+});
+
+function openEditModal(id, name, url, timeout, failure, success) {
+    document.getElementById('edit-id').value = id;
+    document.getElementById('edit-name').textContent = name;
+    document.getElementById('edit-ep-name').value = name;
+    document.getElementById('edit-url').value = url;
+    document.getElementById('edit-method').value = ((endpointsData[id] || {}).method || 'GET').toUpperCase();
+    document.getElementById('edit-body').value = (endpointsData[id] || {}).body || '';
+    const checkInterval = (endpointsData[id] || {}).check_interval;
+    document.getElementById('edit-interval').value = checkInterval ? formatInterval(checkInterval) : '';
+    document.getElementById('edit-timeout').value = timeout || '';
+    document.getElementById('edit-failure').value = failure || 3;
+    const failureDuration = (endpointsData[id] || {}).failure_duration;
+    document.getElementById('edit-failure-duration').value = failureDuration ? formatInterval(failureDuration) : '';
+    document.getElementById('edit-success').value = success || 2;
+    const responseTimeSLO = (endpointsData[id] || {}).response_time_slo;
+    document.getElementById('edit-response-time-slo').value = responseTimeSLO ? (responseTimeSLO / 1000000) + 'ms' : '';
+    setHeaderRows('edit-headers', (endpointsData[id] || {}).headers);
+    document.getElementById('edit-tags').value = ((endpointsData[id] || {}).tags || []).join(', ');
+    document.getElementById('edit-urls').value = ((endpointsData[id] || {}).urls || []).join(', ');
+    document.getElementById('edit-expected-ip').value = (endpointsData[id] || {}).expected_ip || '';
+    document.getElementById('edit-user-agent').value = (endpointsData[id] || {}).user_agent || '';
+    document.getElementById('edit-proxy-url').value = (endpointsData[id] || {}).proxy_url || '';
+    document.getElementById('edit-address-family').value = (endpointsData[id] || {}).address_family || '';
+    document.getElementById('edit-resolve-override').value = (endpointsData[id] || {}).resolve_override || '';
+    const connectTimeout = (endpointsData[id] || {}).connect_timeout;
+    document.getElementById('edit-connect-timeout').value = connectTimeout ? formatInterval(connectTimeout) : '';
+    document.getElementById('edit-disable-keep-alives').checked = !!(endpointsData[id] || {}).disable_keep_alives;
+    document.getElementById('edit-max-redirects').value = (endpointsData[id] || {}).max_redirects || '';
+    document.getElementById('edit-websocket-ping').checked = !!(endpointsData[id] || {}).websocket_ping;
+    document.getElementById('edit-min-body-bytes').value = (endpointsData[id] || {}).assert_min_body_bytes || '';
+    document.getElementById('edit-max-body-bytes').value = (endpointsData[id] || {}).assert_max_body_bytes || '';
+    document.getElementById('edit-body-must-not-contain').value = (endpointsData[id] || {}).body_must_not_contain || '';
+    document.getElementById('edit-invert').checked = !!(endpointsData[id] || {}).invert;
+    document.getElementById('edit-backoff').checked = !!(endpointsData[id] || {}).backoff;
+    document.getElementById('edit-always-alert').checked = !!(endpointsData[id] || {}).always_alert;
+    document.getElementById('edit-alert-first-failure').checked = !!(endpointsData[id] || {}).alert_on_first_failure;
+    document.getElementById('edit-slack-mention').value = (endpointsData[id] || {}).slack_mention || '';
+    document.getElementById('edit-alert-emails').value = ((endpointsData[id] || {}).alert_emails || []).join(', ');
+    const status = (endpointsData[id] || {}).expected_status || 200;
+    document.getElementById('edit-status-any').checked = status === -1;
+    document.getElementById('edit-status').value = status === -1 ? '' : status;
+    toggleStatusAny('edit');
+    document.getElementById('editModal').classList.add('active');
+}
+
+function closeEditModal() {
+    document.getElementById('editModal').classList.remove('active');
+}
+
+async function updateEndpoint(e) {
+    e.preventDefault();
+    const data = {
+        id: document.getElementById('edit-id').value,
+        name: document.getElementById('edit-ep-name').value,
+        url: document.getElementById('edit-url').value,
+        method: document.getElementById('edit-method').value,
+        body: document.getElementById('edit-body').value,
+        check_interval: document.getElementById('edit-interval').value.trim() || '0s',
+        timeout: document.getElementById('edit-timeout').value.trim() || '0s',
+        connect_timeout: document.getElementById('edit-connect-timeout').value.trim(),
+        expected_status: expectedStatusValue('edit'),
+        failure_threshold: parseInt(document.getElementById('edit-failure').value) || 3,
+        failure_duration: document.getElementById('edit-failure-duration').value.trim() || '0s',
+        success_threshold: parseInt(document.getElementById('edit-success').value) || 2,
+        response_time_slo: document.getElementById('edit-response-time-slo').value.trim() || '0s',
+        tags: parseList(document.getElementById('edit-tags').value),
+        urls: parseList(document.getElementById('edit-urls').value),
+        expected_ip: document.getElementById('edit-expected-ip').value.trim(),
+        user_agent: document.getElementById('edit-user-agent').value.trim(),
+        proxy_url: document.getElementById('edit-proxy-url').value.trim(),
+        address_family: document.getElementById('edit-address-family').value,
+        resolve_override: document.getElementById('edit-resolve-override').value.trim(),
+        disable_keep_alives: document.getElementById('edit-disable-keep-alives').checked,
+        max_redirects: parseInt(document.getElementById('edit-max-redirects').value) || 0,
+        websocket_ping: document.getElementById('edit-websocket-ping').checked,
+        assert_min_body_bytes: parseInt(document.getElementById('edit-min-body-bytes').value) || 0,
+        assert_max_body_bytes: parseInt(document.getElementById('edit-max-body-bytes').value) || 0,
+        body_must_not_contain: document.getElementById('edit-body-must-not-contain').value,
+        invert: document.getElementById('edit-invert').checked,
+        backoff: document.getElementById('edit-backoff').checked,
+        always_alert: document.getElementById('edit-always-alert').checked,
+        alert_on_first_failure: document.getElementById('edit-alert-first-failure').checked,
+        slack_mention: document.getElementById('edit-slack-mention').value.trim(),
+        alert_emails: parseList(document.getElementById('edit-alert-emails').value),
+        headers: collectHeaders('edit-headers')
+    };
+    try {
+        const resp = await fetch('/api/endpoints/update', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify(data)
+        });
+        if (resp.ok) {
+            showToast('Endpoint updated');
+            closeEditModal();
+            updateDashboard();
+        } else {
+            const err = await resp.text();
+            showToast(err, 'error');
+        }
+    } catch (err) {
+        showToast('Failed to update', 'error');
+    }
+}
+
+let historyEndpointId = '';
+
+async function clearHistory() {
+    const name = document.getElementById('history-name').textContent;
+    if (!confirm('Clear all history for "' + name + '"? This cannot be undone.')) return;
+    try {
+        const resp = await fetch('/api/history/clear', {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({id: historyEndpointId})
+        });
+        if (resp.ok) {
+            showToast('History cleared');
+            closeHistoryModal();
+            updateDashboard();
+        } else {
+            const text = await resp.text();
+            showToast('Failed: ' + text, 'error');
+        }
+    } catch (err) {
+        showToast('Failed to clear history', 'error');
+    }
+}
+
+// HISTORY_BUCKETS is how many time buckets the history modal charts
+const HISTORY_BUCKETS = 200;
+
+async function openHistoryModal(id, name) {
+    historyEndpointId = id;
+    document.getElementById('history-name').textContent = name;
+    document.getElementById('history-link').href = '/endpoint/' + encodeURIComponent(id);
+    document.getElementById('historyModal').classList.add('active');
+    loadRecentFailures(id);
+    
+    try {
+        const resp = await fetch('/api/history?id=' + id + '&buckets=' + HISTORY_BUCKETS);
+        if (!resp.ok) return;
+        const data = await resp.json();
+        const buckets = data.buckets || [];
+        
+        // Calculate stats
+        let total = 0, healthy = 0;
+        buckets.forEach(b => {
+            total += b.checks;
+            healthy += b.healthy;
+        });
+        const unhealthy = total - healthy;
+        const uptime = total > 0 ? ((healthy / total) * 100).toFixed(1) : 0;
+        
+        document.getElementById('hist-total').textContent = total;
+        document.getElementById('hist-healthy').textContent = healthy;
+        document.getElementById('hist-unhealthy').textContent = unhealthy;
+        document.getElementById('hist-uptime').textContent = uptime + '%';
+        document.getElementById('hist-avg').textContent = data.avg_response_time_ms ? formatDuration(data.avg_response_time_ms) : '-';
+        
+        const tooltip = document.getElementById('chart-tooltip');
+        const bucketTooltip = b => b.checks === 0
+            ? '<strong>no checks</strong><br>' + new Date(b.start).toLocaleString()
+            : '<strong>' + b.uptime_percent.toFixed(1) + '% up</strong> (' + b.checks + ' checks)<br>' +
+              'avg ' + formatDuration(b.avg_response_time_ms) + ' • p95 ' + formatDuration(b.p95_response_time_ms) + '<br>' +
+              new Date(b.start).toLocaleString();
+        const showTooltip = (html, e) => {
+            tooltip.innerHTML = html;
+            tooltip.style.display = 'block';
+            tooltip.style.left = (e.clientX + 10) + 'px';
+            tooltip.style.top = (e.clientY - 60) + 'px';
+        };
+        
+        // Status timeline chart, one bar per bucket
+        const chartEl = document.getElementById('history-chart-large');
+        chartEl.innerHTML = '';
+        buckets.forEach(b => {
+            const bar = document.createElement('div');
+            bar.style.cssText = 'flex:1;min-width:1px;border-radius:1px 1px 0 0;cursor:pointer;';
+            bar.style.background = b.checks === 0 ? themeColor('--track') : b.healthy === b.checks ? '#10b981' : b.healthy === 0 ? '#ef4444' : '#f59e0b';
+            bar.style.height = '100%';
+            bar.onmouseenter = e => showTooltip(bucketTooltip(b), e);
+            bar.onmousemove = e => showTooltip(bucketTooltip(b), e);
+            bar.onmouseleave = function() {
+                tooltip.style.display = 'none';
+            };
+            chartEl.appendChild(bar);
+        });
+        
+        // Bucket start times, labelled with the date when the range spans days
+        const spansDays = buckets.length > 1 &&
+            new Date(buckets[buckets.length - 1].end) - new Date(buckets[0].start) > 24 * 3600 * 1000;
+        const bucketLabel = b => spansDays
+            ? new Date(b.start).toLocaleDateString([], {month: 'short', day: 'numeric'})
+            : new Date(b.start).toLocaleTimeString([], {hour: '2-digit', minute: '2-digit'});
+        
+        // Add X-axis labels for Status Timeline
+        const timelineXAxis = document.getElementById('timeline-x-axis');
+        timelineXAxis.innerHTML = '';
+        if (buckets.length > 0) {
+            const numLabels = 5;
+            for (let i = 0; i < numLabels; i++) {
+                const idx = Math.floor(i * (buckets.length - 1) / (numLabels - 1));
+                const label = document.createElement('span');
+                label.textContent = bucketLabel(buckets[idx]);
+                timelineXAxis.appendChild(label);
+            }
+        }
+        
+        // Response time line chart: average per bucket with p95 as a dashed line
+        const canvas = document.getElementById('response-chart');
+        const ctx = canvas.getContext('2d');
+        const rect = canvas.parentElement.getBoundingClientRect();
+        canvas.width = rect.width - 20;
+        canvas.height = rect.height - 20;
+        
+        const maxTime = Math.max(...buckets.map(b => b.p95_response_time_ms), 1);
+        const padding = 40;
+        const chartWidth = canvas.width - padding * 2;
+        const chartHeight = canvas.height - 30;
+        const xFor = i => padding + (i / Math.max(buckets.length - 1, 1)) * chartWidth;
+        const yFor = ms => 10 + chartHeight - (ms / maxTime) * chartHeight;
+        
+        // Draw grid lines
+        ctx.strokeStyle = themeColor('--track');
+        ctx.lineWidth = 1;
+        for (let i = 0; i <= 4; i++) {
+            const y = 10 + (chartHeight / 4) * i;
+            ctx.beginPath();
+            ctx.moveTo(padding, y);
+            ctx.lineTo(canvas.width - 10, y);
+            ctx.stroke();
+            
+            // Y-axis labels
+            ctx.fillStyle = themeColor('--text-subtle');
+            ctx.font = '10px sans-serif';
+            ctx.textAlign = 'right';
+            const val = Math.round(maxTime - (maxTime / 4) * i);
+            ctx.fillText(val + 'ms', padding - 5, y + 3);
+        }
+        
+        // Draw X-axis labels for Response Time chart
+        ctx.fillStyle = themeColor('--text-subtle');
+        ctx.font = '10px sans-serif';
+        ctx.textAlign = 'center';
+        if (buckets.length > 0) {
+            const numXLabels = 5;
+            for (let i = 0; i < numXLabels; i++) {
+                const idx = Math.floor(i * (buckets.length - 1) / (numXLabels - 1));
+                ctx.fillText(bucketLabel(buckets[idx]), xFor(idx), chartHeight + 25);
+            }
+        }
+        
+        // Draw line charts, skipping buckets without checks
+        const drawLine = (field, color, dash) => {
+            ctx.beginPath();
+            ctx.strokeStyle = color;
+            ctx.lineWidth = 2;
+            ctx.setLineDash(dash);
+            let started = false;
+            buckets.forEach((b, i) => {
+                if (b.checks === 0) {
+                    started = false;
+                    return;
+                }
+                if (!started) ctx.moveTo(xFor(i), yFor(b[field]));
+                else ctx.lineTo(xFor(i), yFor(b[field]));
+                started = true;
+            });
+            ctx.stroke();
+            ctx.setLineDash([]);
+        };
+        if (buckets.length > 1) {
+            drawLine('p95_response_time_ms', 'rgba(99, 102, 241, 0.4)', [4, 4]);
+            drawLine('avg_response_time_ms', themeColor('--accent'), []);
+            
+            // Draw dots for buckets with failures
+            buckets.forEach((b, i) => {
+                if (b.checks > 0 && b.healthy < b.checks) {
+                    ctx.beginPath();
+                    ctx.arc(xFor(i), yFor(b.avg_response_time_ms), 4, 0, Math.PI * 2);
+                    ctx.fillStyle = '#ef4444';
+                    ctx.fill();
+                }
+            });
+            
+            // Add hover tooltip for response time chart
+            canvas.onmousemove = function(e) {
+                const canvasRect = canvas.getBoundingClientRect();
+                const mouseX = e.clientX - canvasRect.left;
+                const idx = Math.round(((mouseX - padding) / chartWidth) * (buckets.length - 1));
+                if (idx >= 0 && idx < buckets.length) {
+                    showTooltip(bucketTooltip(buckets[idx]), e);
+                }
+            };
+            canvas.onmouseleave = function() {
+                tooltip.style.display = 'none';
+            };
+        }
+    } catch (err) {
+        console.error('Error loading history:', err);
+    }
+}
+
+// RECENT_FAILURES is how many failed checks the history modal lists
+const RECENT_FAILURES = 10;
+
+// loadRecentFailures lists the latest failed checks with their response body snapshots
+async function loadRecentFailures(id) {
+    const section = document.getElementById('recent-failures-section');
+    const list = document.getElementById('recent-failures');
+    section.style.display = 'none';
+    list.innerHTML = '';
+    try {
+        const resp = await fetch('/api/history?id=' + id + '&limit=500');
+        if (!resp.ok) return;
+        const data = await resp.json();
+        const failures = (data.records || []).filter(r => r.error).slice(0, RECENT_FAILURES);
+        failures.forEach(r => {
+            const item = document.createElement('details');
+            item.className = 'failure-entry';
+            const summary = document.createElement('summary');
+            summary.textContent = new Date(r.timestamp).toLocaleString() + ' — ' + r.error;
+            item.appendChild(summary);
+            const body = document.createElement('pre');
+            body.textContent = r.body_snapshot || '(no response body recorded)';
+            item.appendChild(body);
+            list.appendChild(item);
+        });
+        section.style.display = failures.length > 0 ? 'block' : 'none';
+    } catch (err) {
+        console.error('Error loading recent failures:', err);
+    }
+}
+
+function closeHistoryModal() {
+    document.getElementById('historyModal').classList.remove('active');
+}
+
+// Tick snooze countdowns between refreshes; refresh once a snooze runs out
+setInterval(() => {
+    let expired = false;
+    document.querySelectorAll('.badge-snooze').forEach(badge => {
+        if (new Date(badge.dataset.until) <= new Date()) {
+            expired = true;
+        }
+        badge.textContent = '💤 ' + formatCountdown(badge.dataset.until);
+    });
+    if (expired) updateDashboard();
+}, 1000);
+
+// updateTabStatus shows the number of unhealthy endpoints in the tab title and
+// colors the favicon, so a backgrounded tab still signals an outage
+function updateTabStatus(healthy, unhealthy) {
+    document.title = unhealthy > 0 ? '(' + unhealthy + ') ' + PAGE_TITLE : PAGE_TITLE;
+    const status = unhealthy > 0 ? 'unhealthy' : healthy > 0 ? 'healthy' : '';
+    document.getElementById('favicon').href = '/favicon.ico' + (status ? '?status=' + status : '');
+}
+
+// themeColor returns a color of the current theme for drawing on a canvas,
+// which can't use CSS variables
+function themeColor(name) {
+    return getComputedStyle(document.documentElement).getPropertyValue(name).trim();
+}
+
+// toggleTheme switches between the light and dark theme and remembers the
+// choice in this browser
+function toggleTheme() {
+    const dark = document.documentElement.dataset.theme !== 'dark';
+    if (dark) {
+        document.documentElement.dataset.theme = 'dark';
+    } else {
+        delete document.documentElement.dataset.theme;
+    }
+    localStorage.setItem('theme', dark ? 'dark' : 'light');
+    updateThemeToggle();
+}
+
+function updateThemeToggle() {
+    const dark = document.documentElement.dataset.theme === 'dark';
+    document.getElementById('theme-toggle').textContent = dark ? 'Light' : 'Dark';
+}
+
+updateThemeToggle();
+updateDashboard();
+setInterval(updateDashboard, REFRESH_INTERVAL_MS);
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// websocketGUID is the fixed key suffix RFC 6455 hashes into the server's
+// Sec-WebSocket-Accept header
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket frame opcodes the check sends or looks for
+const (
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// websocketPingPayload is sent with the ping so its pong can be recognized
+const websocketPingPayload = "cronzee"
+
+// probeWebSocket opens a WebSocket connection to target and, with
+// websocket_ping, sends a ping and waits for the pong. The handshake is an
+// HTTP upgrade request, so it uses the endpoint's proxy, resolve override and
+// address family like an HTTP check; its duration is the response time.
+func (m *Monitor) probeWebSocket(endpoint Endpoint, target string) (result checkResult) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(m.ctx, endpoint.Timeout)
+	defer cancel()
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return checkResult{err: fmt.Sprintf("failed to create request: %v", err)}
+	}
+	// ws becomes http and wss https
+	u.Scheme = "http" + strings.TrimPrefix(u.Scheme, "ws")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return checkResult{err: fmt.Sprintf("failed to create request: %v", err)}
+	}
+
+	var trace requestTrace
+	var statusCode int
+	req = trace.trace(req)
+	defer func() {
+		result.remoteAddr = trace.address()
+		result.statusCode = statusCode
+	}()
+
+	req.Header.Set("User-Agent", m.userAgent(endpoint))
+	for key, value := range m.requestHeaders(endpoint) {
+		req.Header.Set(key, value)
+	}
+	// Set last so custom headers can't break the handshake
+	key := make([]byte, 16)
+	rand.Read(key)
+	challenge := base64.StdEncoding.EncodeToString(key)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", challenge)
+
+	transport, err := m.transport(endpoint)
+	if err != nil {
+		return checkResult{err: err.Error()}
+	}
+	client := &http.Client{Transport: transport, CheckRedirect: redirectPolicy(endpoint.MaxRedirects)}
+
+	resp, err := client.Do(req)
+	responseTime := time.Since(start)
+
+	if err != nil {
+		return checkResult{responseTime: responseTime, err: describeRequestError(err, endpoint, &trace)}
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := readBody(resp, m.currentConfig().MaxBodyBytes)
+		return checkResult{
+			responseTime: responseTime,
+			err:          fmt.Sprintf("websocket handshake failed: got status %d, expected 101", resp.StatusCode),
+			body:         body,
+		}
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return checkResult{responseTime: responseTime, err: "websocket handshake failed: server did not upgrade to websocket"}
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(challenge) {
+		return checkResult{responseTime: responseTime, err: "websocket handshake failed: invalid Sec-WebSocket-Accept header"}
+	}
+
+	// After a 101 the body is the connection itself
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return checkResult{responseTime: responseTime, err: "websocket handshake failed: connection is not writable"}
+	}
+	if endpoint.WebSocketPing {
+		// The upgraded connection no longer watches ctx, so close it when ctx
+		// ends to wake up a read waiting for the pong
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+		if err := websocketPing(conn); err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("no pong within %v", endpoint.Timeout)
+			}
+			return checkResult{responseTime: responseTime, err: fmt.Sprintf("websocket ping failed: %v", err)}
+		}
+	}
+
+	// Close politely with status 1000, normal closure
+	writeWebSocketFrame(conn, wsOpClose, []byte{0x03, 0xe8})
+	return checkResult{responseTime: responseTime}
+}
+
+// websocketAccept returns the Sec-WebSocket-Accept value a server must answer
+// the Sec-WebSocket-Key challenge with
+func websocketAccept(challenge string) string {
+	sum := sha1.Sum([]byte(challenge + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// websocketPing sends a ping on conn and reads frames until its pong arrives.
+// Messages the server sends first are skipped.
+func websocketPing(conn io.ReadWriter) error {
+	if err := writeWebSocketFrame(conn, wsOpPing, []byte(websocketPingPayload)); err != nil {
+		return err
+	}
+	for {
+		opcode, payload, err := readWebSocketFrame(conn)
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case wsOpPong:
+			if string(payload) == websocketPingPayload {
+				return nil
+			}
+		case wsOpClose:
+			if len(payload) >= 2 {
+				return fmt.Errorf("server closed the connection with status %d", binary.BigEndian.Uint16(payload))
+			}
+			return errors.New("server closed the connection")
+		}
+	}
+}
+
+// writeWebSocketFrame writes a single masked frame, as clients must. Only
+// control frames are sent, whose payload always fits the 7-bit length.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	frame := make([]byte, 6, 6+len(payload))
+	frame[0] = 0x80 | opcode
+	frame[1] = 0x80 | byte(len(payload))
+	rand.Read(frame[2:6])
+	for i, b := range payload {
+		frame = append(frame, b^frame[2+i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readWebSocketFrame reads one frame and returns its opcode and, for control
+// frames, the payload. Data frames are discarded without being buffered, so a
+// large message can't exhaust memory.
+func readWebSocketFrame(r io.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0f
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if opcode < wsOpClose {
+		_, err := io.CopyN(io.Discard, r, int64(length))
+		return opcode, nil, err
+	}
+	if length > 125 {
+		return 0, nil, fmt.Errorf("invalid control frame of %d bytes", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}